@@ -0,0 +1,193 @@
+package zkp_iden3_exposer
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"sync"
+)
+
+// DefaultAsyncWorkers Number of the async calls running at once unless SetAsyncWorkers is called
+const DefaultAsyncWorkers = 4
+
+// ResultCallback receives the outcome of the async call on a Go goroutine, exactly one of the methods
+// is called once. errorMessage is the one the blocking variant returns, errorCode is one of ErrorCode*
+type ResultCallback interface {
+	OnSuccess(result []byte)
+	OnError(errorCode string, errorMessage string)
+}
+
+// AsyncTask Handle of the running async call
+type AsyncTask struct {
+	cancel context.CancelFunc
+}
+
+// Cancel aborts the call, the callback gets the CANCELLED error unless the call has already completed
+func (t *AsyncTask) Cancel() {
+	t.cancel()
+}
+
+var (
+	workersMutex sync.Mutex
+	workers      = make(chan struct{}, DefaultAsyncWorkers)
+)
+
+// SetAsyncWorkers limits the async calls of all the connectors running at once, the others wait
+// for a free worker. The calls already running keep their worker
+func SetAsyncWorkers(count int) {
+	if count <= 0 {
+		count = DefaultAsyncWorkers
+	}
+
+	workersMutex.Lock()
+	defer workersMutex.Unlock()
+
+	workers = make(chan struct{}, count)
+}
+
+func acquireWorker(ctx context.Context) (func(), error) {
+	workersMutex.Lock()
+	pool := workers
+	workersMutex.Unlock()
+
+	select {
+	case pool <- struct{}{}:
+		return func() { <-pool }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// runAsync calls the exported method on the worker with the connector bound to the cancellable context
+func (c *Connector) runAsync(callback ResultCallback, call func(c *Connector) ([]byte, error)) *AsyncTask {
+	ctx, cancel := context.WithCancel(c.context())
+	bound := c.WithContext(ctx)
+
+	go func() {
+		defer cancel()
+
+		result, err := runTask(ctx, bound, call)
+		if err != nil {
+			callback.OnError(string(errcode.Of(err)), err.Error())
+			return
+		}
+
+		callback.OnSuccess(result)
+	}()
+
+	return &AsyncTask{cancel: cancel}
+}
+
+func runTask(ctx context.Context, c *Connector, call func(c *Connector) ([]byte, error)) (_ []byte, err error) {
+	defer withCode(&err)
+
+	// the panic would take the whole app down with it
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = errors.Errorf("Async call panicked: %v", recovered)
+		}
+	}()
+
+	release, err := acquireWorker(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error waiting for async worker")
+	}
+	defer release()
+
+	return call(c)
+}
+
+// GetOfferJsonAsync is the non-blocking GetOfferJson
+func (c *Connector) GetOfferJsonAsync(
+	issuerApi string,
+	identityDidString string,
+	claimType string,
+	callback ResultCallback,
+) *AsyncTask {
+	return c.runAsync(callback, func(c *Connector) ([]byte, error) {
+		return c.GetOfferJson(issuerApi, identityDidString, claimType)
+	})
+}
+
+// GetAuthV2InputsAsync is the non-blocking GetAuthV2Inputs
+func (c *Connector) GetAuthV2InputsAsync(offerJson []byte, callback ResultCallback) *AsyncTask {
+	return c.runAsync(callback, func(c *Connector) ([]byte, error) {
+		return c.GetAuthV2Inputs(offerJson)
+	})
+}
+
+// GetVCAsync is the non-blocking GetVC
+func (c *Connector) GetVCAsync(offerJson []byte, proofRaw []byte, callback ResultCallback) *AsyncTask {
+	return c.runAsync(callback, func(c *Connector) ([]byte, error) {
+		return c.GetVC(offerJson, proofRaw)
+	})
+}
+
+// FetchVCAsync is the non-blocking FetchVC
+func (c *Connector) FetchVCAsync(offerJson []byte, callback ResultCallback) *AsyncTask {
+	return c.runAsync(callback, func(c *Connector) ([]byte, error) {
+		return c.FetchVC(offerJson)
+	})
+}
+
+// GetAtomicQueryMTVV2OnChainInputsAsync is the non-blocking GetAtomicQueryMTVV2OnChainInputs
+func (c *Connector) GetAtomicQueryMTVV2OnChainInputsAsync(
+	jsonVC []byte,
+
+	circuitId string,
+	challenge string,
+
+	subjectFieldName string,
+	subjectFieldValue string,
+	operator int,
+
+	callback ResultCallback,
+) *AsyncTask {
+	return c.runAsync(callback, func(c *Connector) ([]byte, error) {
+		return c.GetAtomicQueryMTVV2OnChainInputs(jsonVC, circuitId, challenge, subjectFieldName, subjectFieldValue, operator)
+	})
+}
+
+// GenerateAtomicQueryMTVV2OnChainProofAsync is the non-blocking GenerateAtomicQueryMTVV2OnChainProof
+func (c *Connector) GenerateAtomicQueryMTVV2OnChainProofAsync(
+	jsonVC []byte,
+
+	circuitId string,
+	challenge string,
+
+	subjectFieldName string,
+	subjectFieldValue string,
+	operator int,
+
+	callback ResultCallback,
+) *AsyncTask {
+	return c.runAsync(callback, func(c *Connector) ([]byte, error) {
+		return c.GenerateAtomicQueryMTVV2OnChainProof(jsonVC, circuitId, challenge, subjectFieldName, subjectFieldValue, operator)
+	})
+}
+
+// ProveAsync is the non-blocking Prove
+func (c *Connector) ProveAsync(circuitId string, inputs []byte, callback ResultCallback) *AsyncTask {
+	return c.runAsync(callback, func(c *Connector) ([]byte, error) {
+		return c.Prove(circuitId, inputs)
+	})
+}
+
+// SubmitZKPResponseAsync is the non-blocking SubmitZKPResponse
+func (c *Connector) SubmitZKPResponseAsync(
+	verifierAddress string,
+	requestId int64,
+	proofJson []byte,
+	callback ResultCallback,
+) *AsyncTask {
+	return c.runAsync(callback, func(c *Connector) ([]byte, error) {
+		return c.SubmitZKPResponse(verifierAddress, requestId, proofJson)
+	})
+}
+
+// WaitIssuerStateSignedAsync is the non-blocking WaitIssuerStateSigned
+func (c *Connector) WaitIssuerStateSignedAsync(issuerDid string, callback ResultCallback) *AsyncTask {
+	return c.runAsync(callback, func(c *Connector) ([]byte, error) {
+		return c.WaitIssuerStateSigned(issuerDid)
+	})
+}
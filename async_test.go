@@ -0,0 +1,136 @@
+package zkp_iden3_exposer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type asyncResult struct {
+	result    []byte
+	errorCode string
+	message   string
+}
+
+type channelCallback chan asyncResult
+
+func (c channelCallback) OnSuccess(result []byte) {
+	c <- asyncResult{result: result}
+}
+
+func (c channelCallback) OnError(errorCode string, errorMessage string) {
+	c <- asyncResult{errorCode: errorCode, message: errorMessage}
+}
+
+func waitResult(t *testing.T, callback channelCallback) asyncResult {
+	select {
+	case result := <-callback:
+		return result
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for callback")
+		return asyncResult{}
+	}
+}
+
+func TestAsync(t *testing.T) {
+	var running, maxRunning int32
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+
+		for {
+			seen := atomic.LoadInt32(&maxRunning)
+			if current <= seen || atomic.CompareAndSwapInt32(&maxRunning, seen, current) {
+				break
+			}
+		}
+
+		if strings.Contains(r.URL.Path, "slow") {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		w.Write([]byte(`{"id":"1","type":"offer","body":{"url":"http://issuer","credentials":[]}}`))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	connector, err := NewConnectorFromNetwork(
+		"local",
+		"1cbd5d2d1801e964736881fc0584473f23ba82669599ac65957fb4f2caf43e17",
+		[]byte(localOverrides),
+	)
+	if err != nil {
+		t.Fatalf("Error creating connector: %v", err)
+	}
+
+	t.Run("Should deliver the result to the callback", func(t *testing.T) {
+		callback := make(channelCallback, 1)
+		connector.GetOfferJsonAsync(server.URL, "did:iden3:test", "claim", callback)
+
+		result := waitResult(t, callback)
+		if result.errorCode != "" {
+			t.Fatalf("Unexpected error %s: %s", result.errorCode, result.message)
+		}
+
+		if !strings.Contains(string(result.result), `"type":"offer"`) {
+			t.Errorf("Unexpected result %s", result.result)
+		}
+	})
+
+	t.Run("Should deliver the error code to the callback", func(t *testing.T) {
+		callback := make(channelCallback, 1)
+		connector.GetAuthV2InputsAsync([]byte("not json"), callback)
+
+		result := waitResult(t, callback)
+		if result.errorCode != ErrorCodeInvalidInput {
+			t.Errorf("Expected %s, got %s", ErrorCodeInvalidInput, result.errorCode)
+		}
+	})
+
+	t.Run("Should cancel the task", func(t *testing.T) {
+		callback := make(channelCallback, 1)
+		task := connector.GetOfferJsonAsync(server.URL, "did:iden3:test", "slow", callback)
+
+		time.Sleep(50 * time.Millisecond)
+		task.Cancel()
+
+		result := waitResult(t, callback)
+		if result.errorCode != ErrorCodeCancelled {
+			t.Errorf("Expected %s, got %s: %s", ErrorCodeCancelled, result.errorCode, result.message)
+		}
+	})
+
+	t.Run("Should bound the tasks running at once", func(t *testing.T) {
+		SetAsyncWorkers(2)
+		defer SetAsyncWorkers(DefaultAsyncWorkers)
+
+		atomic.StoreInt32(&maxRunning, 0)
+
+		callbacks := make([]channelCallback, 5)
+		tasks := make([]*AsyncTask, 5)
+		for i := range callbacks {
+			callbacks[i] = make(channelCallback, 1)
+			tasks[i] = connector.GetOfferJsonAsync(server.URL, "did:iden3:test", "slow", callbacks[i])
+		}
+
+		time.Sleep(200 * time.Millisecond)
+
+		if seen := atomic.LoadInt32(&maxRunning); seen != 2 {
+			t.Errorf("Expected 2 tasks running at once, got %d", seen)
+		}
+
+		for i := range tasks {
+			tasks[i].Cancel()
+			waitResult(t, callbacks[i])
+		}
+	})
+}
@@ -0,0 +1,17 @@
+set -e
+
+rm -rf ./frameworks/cshared
+mkdir -p ./frameworks/cshared
+
+case "$(go env GOOS)" in
+  darwin) LIB=libzkpiden3.dylib ;;
+  windows) LIB=zkpiden3.dll ;;
+  *) LIB=libzkpiden3.so ;;
+esac
+
+# the header is generated next to the library
+CGO_ENABLED=1 go build -buildmode=c-shared -o ./frameworks/cshared/$LIB ./cmd/cshared
+
+if [ "$1" = "--test" ]; then
+  go test ./cmd/cshared/
+fi
@@ -0,0 +1,19 @@
+set -e
+
+rm -rf ./frameworks/wasm
+mkdir -p ./frameworks/wasm
+
+GOOS=js GOARCH=wasm go build -o ./frameworks/wasm/zkp-iden3.wasm ./cmd/wasm
+
+# the support script moved to lib/wasm in go 1.24
+WASM_EXEC="$(go env GOROOT)/lib/wasm/wasm_exec.js"
+if [ ! -f "$WASM_EXEC" ]; then
+  WASM_EXEC="$(go env GOROOT)/misc/wasm/wasm_exec.js"
+fi
+
+cp "$WASM_EXEC" ./frameworks/wasm/
+cp ./cmd/wasm/loader.mjs ./frameworks/wasm/
+
+if [ "$1" = "--test" ]; then
+  ZKP_WASM_DIR=./frameworks/wasm node --test ./cmd/wasm/harness/
+fi
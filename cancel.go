@@ -0,0 +1,60 @@
+package zkp_iden3_exposer
+
+import (
+	"context"
+	"time"
+)
+
+// CancellationToken Lets the app abandon the requests of a connector bound to it with WithCancellation,
+// e.g. when the user leaves the screen
+type CancellationToken struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func NewCancellationToken() *CancellationToken {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &CancellationToken{ctx: ctx, cancel: cancel}
+}
+
+// NewCancellationTokenWithTimeout creates the token cancelled on its own after timeoutMillis
+func NewCancellationTokenWithTimeout(timeoutMillis int64) *CancellationToken {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutMillis)*time.Millisecond)
+
+	return &CancellationToken{ctx: ctx, cancel: cancel}
+}
+
+// Cancel stops every request made through the token, it is safe to call it more than once
+func (t *CancellationToken) Cancel() {
+	t.cancel()
+}
+
+func (t *CancellationToken) IsCancelled() bool {
+	return t.ctx.Err() != nil
+}
+
+// WithContext returns the copy of the connector making its requests with ctx. The copy shares
+// the session of the connector, so the cached identity and connections are reused
+func (c *Connector) WithContext(ctx context.Context) *Connector {
+	c.getSession()
+
+	bound := *c
+	bound.ctx = ctx
+
+	return &bound
+}
+
+// WithCancellation returns the copy of the connector making its requests with the token,
+// cancelling the token aborts them
+func (c *Connector) WithCancellation(token *CancellationToken) *Connector {
+	return c.WithContext(token.ctx)
+}
+
+func (c *Connector) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+
+	return c.ctx
+}
@@ -0,0 +1,80 @@
+package zkp_iden3_exposer
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCancellation(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	connector, err := NewConnectorFromNetwork(
+		"local",
+		"1cbd5d2d1801e964736881fc0584473f23ba82669599ac65957fb4f2caf43e17",
+		[]byte(localOverrides),
+	)
+	if err != nil {
+		t.Fatalf("Error creating connector: %v", err)
+	}
+
+	t.Run("Should abort the request when the token is cancelled", func(t *testing.T) {
+		token := NewCancellationToken()
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			token.Cancel()
+		}()
+
+		started := time.Now()
+
+		_, err := connector.WithCancellation(token).GetOfferJson(server.URL, "did:iden3:test", "claim")
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected context.Canceled, got %v", err)
+		}
+
+		if time.Since(started) > 5*time.Second {
+			t.Errorf("Request was not aborted in time")
+		}
+
+		if !token.IsCancelled() {
+			t.Errorf("Expected the token to be cancelled")
+		}
+	})
+	t.Run("Should abort the request on token timeout", func(t *testing.T) {
+		token := NewCancellationTokenWithTimeout(50)
+
+		_, err := connector.WithCancellation(token).GetOfferJson(server.URL, "did:iden3:test", "claim")
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+		}
+	})
+	t.Run("Should share the session with the bound connector", func(t *testing.T) {
+		bound := connector.WithContext(context.Background())
+
+		first, err := connector.getIdentity()
+		if err != nil {
+			t.Fatalf("Error getting identity: %v", err)
+		}
+
+		second, err := bound.getIdentity()
+		if err != nil {
+			t.Fatalf("Error getting identity: %v", err)
+		}
+
+		if first != second {
+			t.Errorf("Expected the bound connector to reuse the identity")
+		}
+	})
+}
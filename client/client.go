@@ -18,12 +18,8 @@ import (
 	"google.golang.org/grpc"
 )
 
-type ChainConfig struct {
-	ChainId     string `json:"chainId"`
-	Denom       string `json:"denom"`
-	MinGasPrice uint64 `json:"minGasPrice"`
-	GasLimit    uint64 `json:"gasLimit"`
-}
+// ChainConfig moved to the wallet package, which builds without the Cosmos SDK
+type ChainConfig = wallet.ChainConfig
 
 type Client struct {
 	Cli         *grpc.ClientConn
@@ -39,7 +35,7 @@ func NewClient(cli *grpc.ClientConn, chainConfig ChainConfig, signer wallet.Wall
 	}, nil
 }
 
-func (c *Client) submitTx(msgs ...sdk.Msg) ([]byte, error) {
+func (c *Client) submitTx(ctx context.Context, msgs ...sdk.Msg) ([]byte, error) {
 	txConfig := authTx.NewTxConfig(
 		codec.NewProtoCodec(codectypes.NewInterfaceRegistry()),
 		[]signing.SignMode{signing.SignMode_SIGN_MODE_DIRECT},
@@ -62,7 +58,7 @@ func (c *Client) submitTx(msgs ...sdk.Msg) ([]byte, error) {
 	)
 
 	accountResp, err := authtypes.NewQueryClient(c.Cli).Account(
-		context.TODO(),
+		ctx,
 		&authtypes.QueryAccountRequest{Address: c.Signer.Address},
 	)
 	if err != nil {
@@ -130,7 +126,7 @@ func (c *Client) submitTx(msgs ...sdk.Msg) ([]byte, error) {
 	}
 
 	grpcRes, err := client.NewServiceClient(c.Cli).BroadcastTx(
-		context.TODO(),
+		ctx,
 		&client.BroadcastTxRequest{
 			Mode:    client.BroadcastMode_BROADCAST_MODE_BLOCK,
 			TxBytes: tx,
@@ -148,7 +144,7 @@ func (c *Client) submitTx(msgs ...sdk.Msg) ([]byte, error) {
 	return data, nil
 }
 
-func (c *Client) Send(addrFrom, addrTo string, amount int64, denom string) ([]byte, error) {
+func (c *Client) Send(ctx context.Context, addrFrom, addrTo string, amount int64, denom string) ([]byte, error) {
 	msgSend := &bank.MsgSend{
 		FromAddress: addrFrom,
 		ToAddress:   addrTo,
@@ -160,7 +156,7 @@ func (c *Client) Send(addrFrom, addrTo string, amount int64, denom string) ([]by
 		},
 	}
 
-	txResp, err := c.submitTx(msgSend)
+	txResp, err := c.submitTx(ctx, msgSend)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to submit tx")
 	}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"github.com/rarimo/zkp-iden3-exposer/wallet"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/keepalive"
@@ -44,6 +45,7 @@ func TestClient(t *testing.T) {
 		)
 
 		txResp, err := client.Send(
+			context.Background(),
 			w.Address,
 			"rarimo1apm2p4k97euu8k8lxg9974kxvfnah8zj7lnydf",
 			1000, // 1000000 = 1 Stake, 1000 = 0.001 Stake
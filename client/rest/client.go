@@ -0,0 +1,213 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"github.com/rarimo/zkp-iden3-exposer/wallet"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Client Submits the transactions through the REST gateway of the Rarimo core node. Unlike
+// client.Client it does not depend on the Cosmos SDK, so it builds for js/wasm as well
+type Client struct {
+	Url         string
+	HttpClient  *http.Client
+	Signer      wallet.Wallet
+	ChainConfig wallet.ChainConfig
+}
+
+func NewClient(url string, httpClient *http.Client, chainConfig wallet.ChainConfig, signer wallet.Wallet) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &Client{
+		Url:         strings.TrimSuffix(url, "/"),
+		HttpClient:  httpClient,
+		Signer:      signer,
+		ChainConfig: chainConfig,
+	}
+}
+
+// Account Number and sequence of the signer account
+type Account struct {
+	AccountNumber uint64
+	Sequence      uint64
+}
+
+// GetAccount reads the account, both the plain and the ethermint accounts are supported
+func (c *Client) GetAccount(ctx context.Context, address string) (*Account, error) {
+	type baseAccount struct {
+		AccountNumber string `json:"account_number"`
+		Sequence      string `json:"sequence"`
+	}
+
+	var response struct {
+		Account struct {
+			baseAccount
+			BaseAccount *baseAccount `json:"base_account"`
+		} `json:"account"`
+	}
+
+	if err := c.do(ctx, http.MethodGet, "/cosmos/auth/v1beta1/accounts/"+address, nil, &response); err != nil {
+		return nil, errors.Wrap(err, "failed to get account")
+	}
+
+	base := response.Account.baseAccount
+	if response.Account.BaseAccount != nil {
+		base = *response.Account.BaseAccount
+	}
+
+	accountNumber, err := strconv.ParseUint(base.AccountNumber, 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse account number")
+	}
+
+	sequence, err := strconv.ParseUint(base.Sequence, 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse sequence")
+	}
+
+	return &Account{AccountNumber: accountNumber, Sequence: sequence}, nil
+}
+
+// GetBalance returns the amount of denom the address holds, the amount is decimal as it may not fit int64
+func (c *Client) GetBalance(ctx context.Context, address string, denom string) (string, error) {
+	var response struct {
+		Balance struct {
+			Denom  string `json:"denom"`
+			Amount string `json:"amount"`
+		} `json:"balance"`
+	}
+
+	path := "/cosmos/bank/v1beta1/balances/" + address + "/by_denom?denom=" + url.QueryEscape(denom)
+	if err := c.do(ctx, http.MethodGet, path, nil, &response); err != nil {
+		return "", errors.Wrap(err, "failed to get balance")
+	}
+
+	if response.Balance.Amount == "" {
+		return "0", nil
+	}
+
+	return response.Balance.Amount, nil
+}
+
+// Send transfers the amount of denom and returns the tx response json encoded
+func (c *Client) Send(ctx context.Context, addrFrom, addrTo string, amount int64, denom string) ([]byte, error) {
+	txBytes, err := c.BuildSendTx(ctx, addrFrom, addrTo, amount, denom)
+	if err != nil {
+		return nil, err
+	}
+
+	txResp, err := c.Broadcast(ctx, txBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to submit tx")
+	}
+
+	return txResp, nil
+}
+
+// BuildSendTx creates the signed bank transfer
+func (c *Client) BuildSendTx(ctx context.Context, addrFrom, addrTo string, amount int64, denom string) ([]byte, error) {
+	account, err := c.GetAccount(ctx, c.Signer.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	body := encodeTxBody(encodeAny(msgSendTypeUrl, encodeMsgSend(addrFrom, addrTo, coin{Denom: denom, Amount: amount})))
+
+	fee := coin{
+		Denom:  c.ChainConfig.Denom,
+		Amount: int64(c.ChainConfig.GasLimit * c.ChainConfig.MinGasPrice),
+	}
+	authInfo := encodeAuthInfo(c.Signer.PubKey.Key, account.Sequence, fee, c.ChainConfig.GasLimit)
+
+	signature, err := c.Signer.PrivateKey.Sign(encodeSignDoc(body, authInfo, c.ChainConfig.ChainId, account.AccountNumber))
+	if err != nil {
+		return nil, errcode.Wrap(err, errcode.KeyError, "failed to sign tx")
+	}
+
+	return encodeTxRaw(body, authInfo, signature), nil
+}
+
+// Broadcast submits the signed tx and waits for it to be included in the block
+func (c *Client) Broadcast(ctx context.Context, txBytes []byte) ([]byte, error) {
+	request := map[string]string{
+		"tx_bytes": base64.StdEncoding.EncodeToString(txBytes),
+		"mode":     "BROADCAST_MODE_BLOCK",
+	}
+
+	var response struct {
+		TxResponse json.RawMessage `json:"tx_response"`
+	}
+
+	if err := c.do(ctx, http.MethodPost, "/cosmos/tx/v1beta1/txs", request, &response); err != nil {
+		return nil, errcode.Classify(err, errcode.TransactionFailed, "failed to broadcast tx")
+	}
+
+	var result struct {
+		Code   uint32 `json:"code"`
+		RawLog string `json:"raw_log"`
+	}
+
+	if err := json.Unmarshal(response.TxResponse, &result); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal tx response")
+	}
+
+	if result.Code != 0 {
+		return nil, errcode.New(errcode.TransactionFailed, fmt.Sprintf("tx failed with code %d: %s", result.Code, result.RawLog))
+	}
+
+	return response.TxResponse, nil
+}
+
+func (c *Client) do(ctx context.Context, method string, path string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		bodyJson, err := json.Marshal(body)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal request")
+		}
+
+		reader = bytes.NewReader(bodyJson)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, c.Url+path, reader)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := c.HttpClient.Do(request)
+	if err != nil {
+		return errors.Wrap(err, "failed to send request")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+
+		return errcode.New(
+			errcode.FromHttpStatus(response.StatusCode, errcode.TransactionFailed),
+			fmt.Sprintf("node responded with status %d: %s", response.StatusCode, strings.TrimSpace(string(message))),
+		)
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(result); err != nil {
+		return errors.Wrap(err, "failed to decode response")
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package rest
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	bank "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/rarimo/zkp-iden3-exposer/wallet"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient(t *testing.T) {
+	signer, err := wallet.NewWallet("1cbd5d2d1801e964736881fc0584473f23ba82669599ac65957fb4f2caf43e17", "rarimo")
+	if err != nil {
+		t.Fatalf("Error creating wallet: %v", err)
+	}
+
+	var broadcast []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cosmos/auth/v1beta1/accounts/" + signer.Address:
+			w.Write([]byte(`{"account":{"@type":"/ethermint.types.v1.EthAccount","base_account":{"account_number":"7","sequence":"3"}}}`))
+		case "/cosmos/tx/v1beta1/txs":
+			var request struct {
+				TxBytes string `json:"tx_bytes"`
+			}
+			json.NewDecoder(r.Body).Decode(&request)
+			broadcast, _ = base64.StdEncoding.DecodeString(request.TxBytes)
+
+			w.Write([]byte(`{"tx_response":{"code":0,"txhash":"ABCD"}}`))
+		case "/cosmos/bank/v1beta1/balances/" + signer.Address + "/by_denom":
+			w.Write([]byte(`{"balance":{"denom":"` + r.URL.Query().Get("denom") + `","amount":"12345678901234567890"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	chainConfig := wallet.ChainConfig{
+		ChainId:     "rarimo_201411-1",
+		Denom:       "urmo",
+		MinGasPrice: 2,
+		GasLimit:    1000,
+	}
+
+	client := NewClient(server.URL, nil, chainConfig, *signer)
+
+	t.Run("Should get balance", func(t *testing.T) {
+		amount, err := client.GetBalance(context.Background(), signer.Address, "urmo")
+		if err != nil {
+			t.Fatalf("Error getting balance: %v", err)
+		}
+
+		if amount != "12345678901234567890" {
+			t.Errorf("Unexpected amount %s", amount)
+		}
+	})
+
+	t.Run("Should send tokens", func(t *testing.T) {
+		txResp, err := client.Send(context.Background(), signer.Address, "rarimo1recipient", 100, "urmo")
+		if err != nil {
+			t.Fatalf("Error sending tx: %v", err)
+		}
+
+		if string(txResp) != `{"code":0,"txhash":"ABCD"}` {
+			t.Errorf("Unexpected tx response %s", txResp)
+		}
+	})
+
+	t.Run("Should encode the tx the way the Cosmos SDK decodes it", func(t *testing.T) {
+		raw := tx.TxRaw{}
+		if err := raw.Unmarshal(broadcast); err != nil {
+			t.Fatalf("Error decoding tx: %v", err)
+		}
+
+		body := tx.TxBody{}
+		if err := body.Unmarshal(raw.BodyBytes); err != nil {
+			t.Fatalf("Error decoding body: %v", err)
+		}
+
+		msg := bank.MsgSend{}
+		if err := msg.Unmarshal(body.Messages[0].Value); err != nil {
+			t.Fatalf("Error decoding message: %v", err)
+		}
+
+		if body.Messages[0].TypeUrl != msgSendTypeUrl || msg.FromAddress != signer.Address ||
+			msg.ToAddress != "rarimo1recipient" || msg.Amount.String() != "100urmo" {
+			t.Errorf("Unexpected message %s %v", body.Messages[0].TypeUrl, msg)
+		}
+
+		authInfo := tx.AuthInfo{}
+		if err := authInfo.Unmarshal(raw.AuthInfoBytes); err != nil {
+			t.Fatalf("Error decoding auth info: %v", err)
+		}
+
+		if authInfo.SignerInfos[0].Sequence != 3 || authInfo.Fee.GasLimit != 1000 || authInfo.Fee.Amount.String() != "2000urmo" {
+			t.Errorf("Unexpected auth info %v", authInfo)
+		}
+
+		signDoc := tx.SignDoc{
+			BodyBytes:     raw.BodyBytes,
+			AuthInfoBytes: raw.AuthInfoBytes,
+			ChainId:       chainConfig.ChainId,
+			AccountNumber: 7,
+		}
+
+		signBytes, err := signDoc.Marshal()
+		if err != nil {
+			t.Fatalf("Error encoding sign doc: %v", err)
+		}
+
+		pubKey := secp256k1.PubKey{Key: signer.PubKey.Key}
+		if !pubKey.VerifySignature(signBytes, raw.Signatures[0]) {
+			t.Errorf("Signature does not match the sign doc")
+		}
+	})
+
+	t.Run("Should fail on the rejected tx", func(t *testing.T) {
+		rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"tx_response":{"code":5,"raw_log":"insufficient funds"}}`))
+		}))
+		defer rejecting.Close()
+
+		if _, err := NewClient(rejecting.URL, nil, chainConfig, *signer).Broadcast(context.Background(), []byte{1}); err == nil {
+			t.Errorf("Expected error")
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package rest
+
+import (
+	"google.golang.org/protobuf/encoding/protowire"
+	"strconv"
+)
+
+// The Cosmos SDK messages are encoded by hand, the SDK packages do not build for js/wasm.
+// Only the fields the bank transfer needs are covered, the zero values are omitted like proto3 does
+
+const (
+	msgSendTypeUrl = "/cosmos.bank.v1beta1.MsgSend"
+	pubKeyTypeUrl  = "/cosmos.crypto.secp256k1.PubKey"
+
+	signModeDirect = 1
+)
+
+type coin struct {
+	Denom  string
+	Amount int64
+}
+
+type message []byte
+
+func (m message) bytes(field protowire.Number, value []byte) message {
+	if len(value) == 0 {
+		return m
+	}
+
+	m = protowire.AppendTag(m, field, protowire.BytesType)
+	return protowire.AppendBytes(m, value)
+}
+
+func (m message) string(field protowire.Number, value string) message {
+	return m.bytes(field, []byte(value))
+}
+
+func (m message) uint64(field protowire.Number, value uint64) message {
+	if value == 0 {
+		return m
+	}
+
+	m = protowire.AppendTag(m, field, protowire.VarintType)
+	return protowire.AppendVarint(m, value)
+}
+
+// embedded appends the nested message, unlike bytes it keeps the empty one
+func (m message) embedded(field protowire.Number, value message) message {
+	m = protowire.AppendTag(m, field, protowire.BytesType)
+	return protowire.AppendBytes(m, value)
+}
+
+func encodeAny(typeUrl string, value message) message {
+	return message{}.string(1, typeUrl).bytes(2, value)
+}
+
+func encodeCoin(c coin) message {
+	return message{}.string(1, c.Denom).string(2, strconv.FormatInt(c.Amount, 10))
+}
+
+func encodeMsgSend(from string, to string, amount coin) message {
+	return message{}.string(1, from).string(2, to).embedded(3, encodeCoin(amount))
+}
+
+func encodeTxBody(messages ...message) message {
+	body := message{}
+	for _, msg := range messages {
+		body = body.embedded(1, msg)
+	}
+
+	return body
+}
+
+func encodeAuthInfo(pubKey []byte, sequence uint64, fee coin, gasLimit uint64) message {
+	modeInfo := message{}.embedded(1, message{}.uint64(1, signModeDirect))
+
+	signerInfo := message{}.
+		embedded(1, encodeAny(pubKeyTypeUrl, message{}.bytes(1, pubKey))).
+		embedded(2, modeInfo).
+		uint64(3, sequence)
+
+	feeInfo := message{}
+	if fee.Amount != 0 {
+		feeInfo = feeInfo.embedded(1, encodeCoin(fee))
+	}
+	feeInfo = feeInfo.uint64(2, gasLimit)
+
+	return message{}.embedded(1, signerInfo).embedded(2, feeInfo)
+}
+
+func encodeSignDoc(body message, authInfo message, chainId string, accountNumber uint64) message {
+	return message{}.bytes(1, body).bytes(2, authInfo).string(3, chainId).uint64(4, accountNumber)
+}
+
+func encodeTxRaw(body message, authInfo message, signature []byte) message {
+	return message{}.bytes(1, body).bytes(2, authInfo).bytes(3, signature)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestSharedLibrary(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Building the shared library is slow")
+	}
+
+	gcc, err := exec.LookPath("gcc")
+	if err != nil {
+		t.Skip("gcc is required to build the C test program")
+	}
+
+	dir := t.TempDir()
+
+	build := exec.Command("go", "build", "-buildmode=c-shared", "-o", filepath.Join(dir, "libzkpiden3.so"), ".")
+	build.Env = append(os.Environ(), "CGO_ENABLED=1")
+	if output, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("Error building shared library: %v\n%s", err, output)
+	}
+
+	program := filepath.Join(dir, "connector_test")
+
+	compile := exec.Command(
+		gcc, "-Wall", "-Werror",
+		"-I", dir,
+		"-o", program,
+		filepath.Join("test", "connector_test.c"),
+		"-L", dir, "-lzkpiden3",
+		"-Wl,-rpath,"+dir,
+	)
+	if output, err := compile.CombinedOutput(); err != nil {
+		t.Fatalf("Error compiling C test program: %v\n%s", err, output)
+	}
+
+	issuer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"1","type":"offer","body":{"url":"http://issuer","credentials":[]}}`))
+	}))
+	defer issuer.Close()
+
+	t.Run("Should pass the C test program", func(t *testing.T) {
+		output, err := exec.Command(program, issuer.URL).CombinedOutput()
+		if err != nil {
+			t.Fatalf("C test program failed: %v\n%s", err, output)
+		}
+	})
+}
@@ -0,0 +1,423 @@
+// Command cshared is the C ABI of the connector, build_cshared.sh builds it as the shared library
+// with the generated libzkpiden3.h header.
+//
+// The connectors are referred to by the opaque handles, the strings are UTF-8 and the JSON values
+// are passed as strings. Every function returns ZKP_OK or the status of the failure, the result and
+// the "CODE: message" error are written to the out parameters and must be released with zkp_free
+package main
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+
+// zkp_connector Opaque handle of the connector, zero is never a valid one
+typedef uint64_t zkp_connector;
+
+// zkp_status Status of the call, the values never change once released
+typedef enum {
+	ZKP_OK = 0,
+	ZKP_UNKNOWN = 1,
+	ZKP_NETWORK = 2,
+	ZKP_TIMEOUT = 3,
+	ZKP_CANCELLED = 4,
+	ZKP_CERTIFICATE_REJECTED = 5,
+	ZKP_ISSUER_REJECTED = 6,
+	ZKP_CREDENTIAL_REVOKED = 7,
+	ZKP_STATE_NOT_SYNCED = 8,
+	ZKP_NOT_FOUND = 9,
+	ZKP_VERIFICATION_FAILED = 10,
+	ZKP_INVALID_QUERY = 11,
+	ZKP_INVALID_INPUT = 12,
+	ZKP_INVALID_CONFIG = 13,
+	ZKP_KEY_ERROR = 14,
+	ZKP_CIRCUIT_ARTIFACT = 15,
+	ZKP_PROVER_FAILED = 16,
+	ZKP_TRANSACTION_FAILED = 17
+} zkp_status;
+*/
+import "C"
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	exposer "github.com/rarimo/zkp-iden3-exposer"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+func main() {}
+
+var statuses = map[errcode.Code]C.zkp_status{
+	errcode.Unknown:             C.ZKP_UNKNOWN,
+	errcode.Network:             C.ZKP_NETWORK,
+	errcode.Timeout:             C.ZKP_TIMEOUT,
+	errcode.Cancelled:           C.ZKP_CANCELLED,
+	errcode.CertificateRejected: C.ZKP_CERTIFICATE_REJECTED,
+	errcode.IssuerRejected:      C.ZKP_ISSUER_REJECTED,
+	errcode.CredentialRevoked:   C.ZKP_CREDENTIAL_REVOKED,
+	errcode.StateNotSynced:      C.ZKP_STATE_NOT_SYNCED,
+	errcode.NotFound:            C.ZKP_NOT_FOUND,
+	errcode.VerificationFailed:  C.ZKP_VERIFICATION_FAILED,
+	errcode.InvalidQuery:        C.ZKP_INVALID_QUERY,
+	errcode.InvalidInput:        C.ZKP_INVALID_INPUT,
+	errcode.InvalidConfig:       C.ZKP_INVALID_CONFIG,
+	errcode.KeyError:            C.ZKP_KEY_ERROR,
+	errcode.CircuitArtifact:     C.ZKP_CIRCUIT_ARTIFACT,
+	errcode.ProverFailed:        C.ZKP_PROVER_FAILED,
+	errcode.TransactionFailed:   C.ZKP_TRANSACTION_FAILED,
+}
+
+// handles Connectors owned by the C side, the handles are never reused so the stale one fails cleanly
+var handles = struct {
+	sync.Mutex
+	next       C.zkp_connector
+	connectors map[C.zkp_connector]*exposer.Connector
+}{connectors: map[C.zkp_connector]*exposer.Connector{}}
+
+func register(connector *exposer.Connector) C.zkp_connector {
+	handles.Lock()
+	defer handles.Unlock()
+
+	handles.next++
+	handles.connectors[handles.next] = connector
+
+	return handles.next
+}
+
+func lookup(handle C.zkp_connector) (*exposer.Connector, error) {
+	handles.Lock()
+	defer handles.Unlock()
+
+	connector, ok := handles.connectors[handle]
+	if !ok {
+		return nil, errcode.New(errcode.InvalidInput, "Unknown connector handle")
+	}
+
+	return connector, nil
+}
+
+// call runs the operation and reports its outcome through the out parameters, the panic is
+// reported as the error since it must not unwind into the C frames
+func call(result **C.char, errorMessage **C.char, run func() ([]byte, error)) (status C.zkp_status) {
+	if result != nil {
+		*result = nil
+	}
+
+	if errorMessage != nil {
+		*errorMessage = nil
+	}
+
+	value, err := func() (value []byte, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = errors.Errorf("Call panicked: %v", recovered)
+			}
+		}()
+
+		return run()
+	}()
+	if err != nil {
+		code := errcode.Of(err)
+
+		message := err.Error()
+		if !strings.HasPrefix(message, string(code)+": ") {
+			message = string(code) + ": " + message
+		}
+
+		if errorMessage != nil {
+			*errorMessage = C.CString(message)
+		}
+
+		if status, ok := statuses[code]; ok {
+			return status
+		}
+
+		return C.ZKP_UNKNOWN
+	}
+
+	if result != nil && value != nil {
+		*result = C.CString(string(value))
+	}
+
+	return C.ZKP_OK
+}
+
+func callConnector(
+	handle C.zkp_connector,
+	result **C.char,
+	errorMessage **C.char,
+	run func(connector *exposer.Connector) ([]byte, error),
+) C.zkp_status {
+	return call(result, errorMessage, func() ([]byte, error) {
+		connector, err := lookup(handle)
+		if err != nil {
+			return nil, err
+		}
+
+		return run(connector)
+	})
+}
+
+func createConnector(handle *C.zkp_connector, errorMessage **C.char, create func() (*exposer.Connector, error)) C.zkp_status {
+	if handle != nil {
+		*handle = 0
+	}
+
+	return call(nil, errorMessage, func() ([]byte, error) {
+		if handle == nil {
+			return nil, errcode.New(errcode.InvalidInput, "Handle out parameter is required")
+		}
+
+		connector, err := create()
+		if err != nil {
+			return nil, err
+		}
+
+		*handle = register(connector)
+
+		return nil, nil
+	})
+}
+
+func goString(value *C.char) string {
+	if value == nil {
+		return ""
+	}
+
+	return C.GoString(value)
+}
+
+//export zkp_free
+func zkp_free(value *C.char) {
+	C.free(unsafe.Pointer(value))
+}
+
+//export zkp_connector_new
+func zkp_connector_new(configJson *C.char, handle *C.zkp_connector, errorMessage **C.char) C.zkp_status {
+	return createConnector(handle, errorMessage, func() (*exposer.Connector, error) {
+		return exposer.NewConnectorFromJSON([]byte(goString(configJson)))
+	})
+}
+
+//export zkp_connector_new_from_network
+func zkp_connector_new_from_network(
+	network *C.char,
+	pkHex *C.char,
+	overridesJson *C.char,
+	handle *C.zkp_connector,
+	errorMessage **C.char,
+) C.zkp_status {
+	return createConnector(handle, errorMessage, func() (*exposer.Connector, error) {
+		return exposer.NewConnectorFromNetwork(goString(network), goString(pkHex), []byte(goString(overridesJson)))
+	})
+}
+
+//export zkp_connector_new_from_state
+func zkp_connector_new_from_state(stateJson *C.char, handle *C.zkp_connector, errorMessage **C.char) C.zkp_status {
+	return createConnector(handle, errorMessage, func() (*exposer.Connector, error) {
+		return exposer.NewConnectorFromState([]byte(goString(stateJson)))
+	})
+}
+
+// zkp_connector_free closes the connector and invalidates the handle, the unknown handle is ignored
+//
+//export zkp_connector_free
+func zkp_connector_free(handle C.zkp_connector) {
+	handles.Lock()
+	connector, ok := handles.connectors[handle]
+	delete(handles.connectors, handle)
+	handles.Unlock()
+
+	if ok {
+		connector.Close()
+	}
+}
+
+//export zkp_connector_export_state
+func zkp_connector_export_state(handle C.zkp_connector, result **C.char, errorMessage **C.char) C.zkp_status {
+	return callConnector(handle, result, errorMessage, func(c *exposer.Connector) ([]byte, error) {
+		return c.ExportState()
+	})
+}
+
+//export zkp_connector_set_circuits_dir
+func zkp_connector_set_circuits_dir(handle C.zkp_connector, dir *C.char, errorMessage **C.char) C.zkp_status {
+	return callConnector(handle, nil, errorMessage, func(c *exposer.Connector) ([]byte, error) {
+		c.SetCircuitsDir(goString(dir))
+		return nil, nil
+	})
+}
+
+// zkp_connector_pin_circuit sets the hex encoded SHA-256 digests the circuit artifacts must match,
+// the artifacts without the digest are rejected
+//
+//export zkp_connector_pin_circuit
+func zkp_connector_pin_circuit(
+	handle C.zkp_connector,
+	circuitId *C.char,
+	wasmDigest *C.char,
+	provingKeyDigest *C.char,
+	verificationKeyDigest *C.char,
+	errorMessage **C.char,
+) C.zkp_status {
+	return callConnector(handle, nil, errorMessage, func(c *exposer.Connector) ([]byte, error) {
+		c.PinCircuit(goString(circuitId), goString(wasmDigest), goString(provingKeyDigest), goString(verificationKeyDigest))
+		return nil, nil
+	})
+}
+
+//export zkp_get_did_string
+func zkp_get_did_string(handle C.zkp_connector, result **C.char, errorMessage **C.char) C.zkp_status {
+	return callConnector(handle, result, errorMessage, func(c *exposer.Connector) ([]byte, error) {
+		did, err := c.GetDidString()
+		return []byte(did), err
+	})
+}
+
+//export zkp_get_id_big_int_string
+func zkp_get_id_big_int_string(handle C.zkp_connector, result **C.char, errorMessage **C.char) C.zkp_status {
+	return callConnector(handle, result, errorMessage, func(c *exposer.Connector) ([]byte, error) {
+		id, err := c.GetIdBigIntString()
+		return []byte(id), err
+	})
+}
+
+//export zkp_get_offer_json
+func zkp_get_offer_json(
+	handle C.zkp_connector,
+	issuerApi *C.char,
+	identityDid *C.char,
+	claimType *C.char,
+	result **C.char,
+	errorMessage **C.char,
+) C.zkp_status {
+	return callConnector(handle, result, errorMessage, func(c *exposer.Connector) ([]byte, error) {
+		return c.GetOfferJson(goString(issuerApi), goString(identityDid), goString(claimType))
+	})
+}
+
+//export zkp_get_auth_v2_inputs
+func zkp_get_auth_v2_inputs(handle C.zkp_connector, offerJson *C.char, result **C.char, errorMessage **C.char) C.zkp_status {
+	return callConnector(handle, result, errorMessage, func(c *exposer.Connector) ([]byte, error) {
+		return c.GetAuthV2Inputs([]byte(goString(offerJson)))
+	})
+}
+
+//export zkp_get_vc
+func zkp_get_vc(
+	handle C.zkp_connector,
+	offerJson *C.char,
+	proofJson *C.char,
+	result **C.char,
+	errorMessage **C.char,
+) C.zkp_status {
+	return callConnector(handle, result, errorMessage, func(c *exposer.Connector) ([]byte, error) {
+		return c.GetVC([]byte(goString(offerJson)), []byte(goString(proofJson)))
+	})
+}
+
+//export zkp_fetch_vc
+func zkp_fetch_vc(handle C.zkp_connector, offerJson *C.char, result **C.char, errorMessage **C.char) C.zkp_status {
+	return callConnector(handle, result, errorMessage, func(c *exposer.Connector) ([]byte, error) {
+		return c.FetchVC([]byte(goString(offerJson)))
+	})
+}
+
+// query Arguments of the query inputs and proof builders
+type query struct {
+	VC                json.RawMessage `json:"vc"`
+	CircuitId         string          `json:"circuitId"`
+	Challenge         string          `json:"challenge"`
+	SubjectFieldName  string          `json:"subjectFieldName"`
+	SubjectFieldValue string          `json:"subjectFieldValue"`
+	Operator          int             `json:"operator"`
+}
+
+func decodeQuery(queryJson *C.char) (*query, error) {
+	q := query{}
+	if err := json.Unmarshal([]byte(goString(queryJson)), &q); err != nil {
+		return nil, errcode.Wrap(err, errcode.InvalidInput, "Error unmarshalling query")
+	}
+
+	return &q, nil
+}
+
+// zkp_get_query_inputs builds the AtomicQueryMTPV2OnChain inputs, queryJson is
+// {"vc":{...},"circuitId":"...","challenge":"...","subjectFieldName":"...","subjectFieldValue":"...","operator":1}
+//
+//export zkp_get_query_inputs
+func zkp_get_query_inputs(handle C.zkp_connector, queryJson *C.char, result **C.char, errorMessage **C.char) C.zkp_status {
+	return callConnector(handle, result, errorMessage, func(c *exposer.Connector) ([]byte, error) {
+		q, err := decodeQuery(queryJson)
+		if err != nil {
+			return nil, err
+		}
+
+		return c.GetAtomicQueryMTVV2OnChainInputs(q.VC, q.CircuitId, q.Challenge, q.SubjectFieldName, q.SubjectFieldValue, q.Operator)
+	})
+}
+
+// zkp_generate_query_proof takes the zkp_get_query_inputs arguments and proves the inputs
+//
+//export zkp_generate_query_proof
+func zkp_generate_query_proof(handle C.zkp_connector, queryJson *C.char, result **C.char, errorMessage **C.char) C.zkp_status {
+	return callConnector(handle, result, errorMessage, func(c *exposer.Connector) ([]byte, error) {
+		q, err := decodeQuery(queryJson)
+		if err != nil {
+			return nil, err
+		}
+
+		return c.GenerateAtomicQueryMTVV2OnChainProof(q.VC, q.CircuitId, q.Challenge, q.SubjectFieldName, q.SubjectFieldValue, q.Operator)
+	})
+}
+
+//export zkp_prove
+func zkp_prove(
+	handle C.zkp_connector,
+	circuitId *C.char,
+	inputsJson *C.char,
+	result **C.char,
+	errorMessage **C.char,
+) C.zkp_status {
+	return callConnector(handle, result, errorMessage, func(c *exposer.Connector) ([]byte, error) {
+		return c.Prove(goString(circuitId), []byte(goString(inputsJson)))
+	})
+}
+
+//export zkp_submit_zkp_response
+func zkp_submit_zkp_response(
+	handle C.zkp_connector,
+	verifierAddress *C.char,
+	requestId C.int64_t,
+	proofJson *C.char,
+	result **C.char,
+	errorMessage **C.char,
+) C.zkp_status {
+	return callConnector(handle, result, errorMessage, func(c *exposer.Connector) ([]byte, error) {
+		return c.SubmitZKPResponse(goString(verifierAddress), int64(requestId), []byte(goString(proofJson)))
+	})
+}
+
+//export zkp_wallet_get_address
+func zkp_wallet_get_address(handle C.zkp_connector, result **C.char, errorMessage **C.char) C.zkp_status {
+	return callConnector(handle, result, errorMessage, func(c *exposer.Connector) ([]byte, error) {
+		address, err := c.WalletGetAddress()
+		return []byte(address), err
+	})
+}
+
+//export zkp_wallet_send
+func zkp_wallet_send(
+	handle C.zkp_connector,
+	fromAddress *C.char,
+	toAddress *C.char,
+	amount C.int64_t,
+	result **C.char,
+	errorMessage **C.char,
+) C.zkp_status {
+	return callConnector(handle, result, errorMessage, func(c *exposer.Connector) ([]byte, error) {
+		return c.WalletSend(goString(fromAddress), goString(toAddress), int64(amount))
+	})
+}
@@ -0,0 +1,147 @@
+// C test program of the shared library, cshared_test.go builds and runs it with the issuer URL as
+// the only argument. Every failed check is printed and reflected in the exit status
+#include <stdio.h>
+#include <string.h>
+
+#include "libzkpiden3.h"
+
+static const char *pk_hex = "1cbd5d2d1801e964736881fc0584473f23ba82669599ac65957fb4f2caf43e17";
+
+static int failures = 0;
+
+#define CHECK(condition, ...)                                     \
+	do {                                                          \
+		if (!(condition)) {                                       \
+			failures++;                                           \
+			fprintf(stderr, "%s:%d: ", __FILE__, __LINE__);       \
+			fprintf(stderr, __VA_ARGS__);                         \
+			fprintf(stderr, "\n");                                \
+		}                                                         \
+	} while (0)
+
+static int starts_with(const char *value, const char *prefix) {
+	return value != NULL && strncmp(value, prefix, strlen(prefix)) == 0;
+}
+
+static void test_network_connector(void) {
+	zkp_connector connector = 0;
+	char *error = NULL;
+
+	zkp_status status = zkp_connector_new_from_network("mainnet-beta", (char *)pk_hex, NULL, &connector, &error);
+	CHECK(status == ZKP_OK, "new_from_network: %d %s", status, error);
+	CHECK(connector != 0, "new_from_network returned the zero handle");
+	zkp_free(error);
+
+	char *did = NULL;
+	status = zkp_get_did_string(connector, &did, &error);
+	CHECK(status == ZKP_OK, "get_did_string: %d %s", status, error);
+	CHECK(starts_with(did, "did:iden3:"), "unexpected did %s", did);
+
+	char *address = NULL;
+	status = zkp_wallet_get_address(connector, &address, &error);
+	CHECK(status == ZKP_OK, "wallet_get_address: %d %s", status, error);
+	CHECK(starts_with(address, "rarimo1"), "unexpected address %s", address);
+	zkp_free(address);
+
+	char *state = NULL;
+	status = zkp_connector_export_state(connector, &state, &error);
+	CHECK(status == ZKP_OK, "export_state: %d %s", status, error);
+
+	zkp_connector restored = 0;
+	status = zkp_connector_new_from_state(state, &restored, &error);
+	CHECK(status == ZKP_OK, "new_from_state: %d %s", status, error);
+	CHECK(restored != connector, "restored connector reused the handle");
+	zkp_free(state);
+
+	char *restored_did = NULL;
+	status = zkp_get_did_string(restored, &restored_did, &error);
+	CHECK(status == ZKP_OK, "restored get_did_string: %d %s", status, error);
+	CHECK(did != NULL && restored_did != NULL && strcmp(did, restored_did) == 0,
+	      "restored did %s differs from %s", restored_did, did);
+
+	zkp_free(did);
+	zkp_free(restored_did);
+	zkp_connector_free(restored);
+	zkp_connector_free(connector);
+}
+
+static void test_invalid_config(void) {
+	zkp_connector connector = 0;
+	char *error = NULL;
+
+	zkp_status status = zkp_connector_new("{\"pkHex\":\"\"}", &connector, &error);
+	CHECK(status == ZKP_INVALID_CONFIG, "new: unexpected status %d", status);
+	CHECK(connector == 0, "failed new returned the handle");
+	CHECK(starts_with(error, "INVALID_CONFIG: "), "unexpected error %s", error);
+	zkp_free(error);
+
+	status = zkp_connector_new("not json", &connector, &error);
+	CHECK(status == ZKP_INVALID_CONFIG, "new from malformed json: unexpected status %d", status);
+	zkp_free(error);
+}
+
+static void test_invalid_arguments(void) {
+	zkp_connector connector = 0;
+	char *result = NULL;
+	char *error = NULL;
+
+	zkp_status status = zkp_connector_new_from_network("mainnet-beta", (char *)pk_hex, NULL, &connector, &error);
+	CHECK(status == ZKP_OK, "new_from_network: %d %s", status, error);
+
+	status = zkp_get_auth_v2_inputs(connector, "not json", &result, &error);
+	CHECK(status == ZKP_INVALID_INPUT, "get_auth_v2_inputs: unexpected status %d", status);
+	CHECK(result == NULL, "failed call returned the result");
+	CHECK(starts_with(error, "INVALID_INPUT: "), "unexpected error %s", error);
+	zkp_free(error);
+
+	status = zkp_get_query_inputs(connector, "[]", &result, &error);
+	CHECK(status == ZKP_INVALID_INPUT, "get_query_inputs: unexpected status %d", status);
+	zkp_free(error);
+
+	zkp_connector_free(connector);
+
+	status = zkp_get_did_string(connector, &result, &error);
+	CHECK(status == ZKP_INVALID_INPUT, "freed handle: unexpected status %d", status);
+	CHECK(starts_with(error, "INVALID_INPUT: "), "unexpected error %s", error);
+	zkp_free(error);
+
+	zkp_connector_free(connector);
+	zkp_connector_free(0);
+}
+
+static void test_offer(const char *issuer_url) {
+	zkp_connector connector = 0;
+	char *result = NULL;
+	char *error = NULL;
+
+	zkp_status status = zkp_connector_new_from_network("mainnet-beta", (char *)pk_hex, NULL, &connector, &error);
+	CHECK(status == ZKP_OK, "new_from_network: %d %s", status, error);
+
+	status = zkp_get_offer_json(connector, (char *)issuer_url, "did:iden3:test", "claim", &result, &error);
+	CHECK(status == ZKP_OK, "get_offer_json: %d %s", status, error);
+	CHECK(result != NULL && strstr(result, "\"type\":\"offer\"") != NULL, "unexpected offer %s", result);
+	zkp_free(result);
+
+	zkp_connector_free(connector);
+}
+
+int main(int argc, char **argv) {
+	if (argc != 2) {
+		fprintf(stderr, "usage: %s <issuer url>\n", argv[0]);
+		return 2;
+	}
+
+	test_network_connector();
+	test_invalid_config();
+	test_invalid_arguments();
+	test_offer(argv[1]);
+
+	if (failures > 0) {
+		fprintf(stderr, "%d checks failed\n", failures);
+		return 1;
+	}
+
+	printf("ok\n");
+
+	return 0;
+}
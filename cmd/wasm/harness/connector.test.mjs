@@ -0,0 +1,127 @@
+// Node harness of the wasm build, run it with `sh build_wasm.sh --test`. Every endpoint is served
+// locally, so the harness works offline
+
+import assert from 'node:assert/strict'
+import { createServer } from 'node:http'
+import { createRequire } from 'node:module'
+import path from 'node:path'
+import { after, before, describe, it } from 'node:test'
+
+const wasmDir = path.resolve(process.env.ZKP_WASM_DIR ?? 'frameworks/wasm')
+
+createRequire(import.meta.url)(path.join(wasmDir, 'wasm_exec.js'))
+const { load } = await import(path.join(wasmDir, 'loader.mjs'))
+
+const pkHex = '1cbd5d2d1801e964736881fc0584473f23ba82669599ac65957fb4f2caf43e17'
+
+function serve(handler) {
+  return new Promise((resolve) => {
+    const server = createServer(handler)
+    server.listen(0, '127.0.0.1', () => resolve(server))
+  })
+}
+
+function urlOf(server) {
+  return `http://127.0.0.1:${server.address().port}`
+}
+
+describe('zkpIden3Exposer', () => {
+  let exposer
+  let issuer
+  let core
+  let broadcast
+
+  before(async () => {
+    // net/http talks to its fake network instead of fetch once it sees node in argv0 during the init
+    const nodeProcess = globalThis.process
+    globalThis.process = Object.create(nodeProcess, { argv0: { value: 'zkp-iden3-harness' } })
+
+    try {
+      exposer = await load(path.join(wasmDir, 'zkp-iden3.wasm'))
+    } finally {
+      globalThis.process = nodeProcess
+    }
+
+    issuer = await serve((request, response) => {
+      response.end(JSON.stringify({ id: '1', type: 'offer', body: { url: 'http://issuer', credentials: [] } }))
+    })
+
+    core = await serve((request, response) => {
+      if (request.url.startsWith('/cosmos/auth/v1beta1/accounts/')) {
+        response.end(JSON.stringify({ account: { base_account: { account_number: '7', sequence: '3' } } }))
+        return
+      }
+
+      let body = ''
+      request.on('data', (chunk) => { body += chunk })
+      request.on('end', () => {
+        broadcast = JSON.parse(body)
+        response.end(JSON.stringify({ tx_response: { code: 0, txhash: 'ABCD' } }))
+      })
+    })
+  })
+
+  after(() => {
+    issuer.close()
+    core.close()
+  })
+
+  it('creates the connector from the network preset', async () => {
+    const connector = await exposer.newConnectorFromNetwork('mainnet-beta', pkHex)
+
+    const did = await connector.getDidString()
+    assert.match(did, /^did:iden3:/)
+
+    const address = await connector.walletGetAddress()
+    assert.match(address, /^rarimo1/)
+  })
+
+  it('creates the connector from the exported config', async () => {
+    const connector = await exposer.newConnectorFromNetwork('mainnet-beta', pkHex)
+    const state = await connector.exportState()
+
+    const restored = await exposer.newConnectorFromState(state)
+    assert.equal(await restored.getDidString(), await connector.getDidString())
+
+    const config = JSON.parse(state).config
+    const fromJson = await exposer.newConnector(JSON.stringify(config))
+    assert.equal(await fromJson.getDidString(), await connector.getDidString())
+  })
+
+  it('rejects the invalid config with the code', async () => {
+    await assert.rejects(exposer.newConnector('{"pkHex":""}'), (error) => {
+      assert.equal(error.code, 'INVALID_CONFIG')
+      assert.match(error.message, /^INVALID_CONFIG: /)
+      return true
+    })
+  })
+
+  it('rejects the malformed arguments with the code', async () => {
+    const connector = await exposer.newConnectorFromNetwork('mainnet-beta', pkHex)
+
+    await assert.rejects(connector.getAuthV2Inputs('not json'), { code: 'INVALID_INPUT' })
+    await assert.rejects(connector.getAuthV2Inputs(42), { code: 'INVALID_INPUT' })
+  })
+
+  it('gets the offer from the issuer', async () => {
+    const connector = await exposer.newConnectorFromNetwork('mainnet-beta', pkHex)
+
+    const offer = JSON.parse(await connector.getOfferJson(urlOf(issuer), 'did:iden3:test', 'claim'))
+    assert.equal(offer.type, 'offer')
+  })
+
+  it('sends the tokens through the REST API', async () => {
+    const connector = await exposer.newConnectorFromNetwork(
+      'mainnet-beta',
+      pkHex,
+      JSON.stringify({ chainInfo: { coreApiUrl: urlOf(core) } }),
+    )
+
+    const from = await connector.walletGetAddress()
+    const response = JSON.parse(await connector.walletSend(from, from, 100))
+
+    assert.equal(response.txhash, 'ABCD')
+    assert.equal(broadcast.mode, 'BROADCAST_MODE_BLOCK')
+    assert.ok(broadcast.tx_bytes.length > 0)
+  })
+})
@@ -0,0 +1,24 @@
+// Loads zkp-iden3.wasm and resolves to the zkpIden3Exposer object, works in the browsers and in Node.
+// wasm_exec.js has to be loaded first, build_wasm.sh puts both next to this file
+
+export async function load(wasm) {
+  const go = new globalThis.Go()
+
+  const source = typeof wasm === 'string' || wasm instanceof URL ? await fetchWasm(wasm) : wasm
+  const { instance } = await WebAssembly.instantiate(source, go.importObject)
+
+  // main never returns, it sets the global object and parks
+  go.run(instance)
+
+  return globalThis.zkpIden3Exposer
+}
+
+async function fetchWasm(location) {
+  if (globalThis.process?.versions?.node) {
+    const { readFile } = await import('node:fs/promises')
+    return readFile(location)
+  }
+
+  const response = await fetch(location)
+  return response.arrayBuffer()
+}
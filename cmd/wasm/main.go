@@ -0,0 +1,383 @@
+//go:build js && wasm
+
+// Command wasm exposes the connector to JavaScript as the global zkpIden3Exposer object, every
+// function returns the Promise. The JSON values are passed as strings both ways and the rejection
+// Error carries the ErrorCode* of the failure in its code field. Built with build_wasm.sh
+package main
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	exposer "github.com/rarimo/zkp-iden3-exposer"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"strings"
+	"syscall/js"
+)
+
+func main() {
+	js.Global().Set("zkpIden3Exposer", js.ValueOf(map[string]interface{}{
+		"newConnector": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			return promise(func() (interface{}, error) {
+				configJson, err := stringArg(args, 0)
+				if err != nil {
+					return nil, err
+				}
+
+				connector, err := exposer.NewConnectorFromJSON([]byte(configJson))
+				if err != nil {
+					return nil, err
+				}
+
+				return wrapConnector(connector), nil
+			})
+		}),
+		"newConnectorFromNetwork": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			return promise(func() (interface{}, error) {
+				networkName, err := stringArg(args, 0)
+				if err != nil {
+					return nil, err
+				}
+
+				pkHex, err := stringArg(args, 1)
+				if err != nil {
+					return nil, err
+				}
+
+				overridesJson, err := optionalStringArg(args, 2)
+				if err != nil {
+					return nil, err
+				}
+
+				connector, err := exposer.NewConnectorFromNetwork(networkName, pkHex, []byte(overridesJson))
+				if err != nil {
+					return nil, err
+				}
+
+				return wrapConnector(connector), nil
+			})
+		}),
+		"newConnectorFromState": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			return promise(func() (interface{}, error) {
+				stateJson, err := stringArg(args, 0)
+				if err != nil {
+					return nil, err
+				}
+
+				connector, err := exposer.NewConnectorFromState([]byte(stateJson))
+				if err != nil {
+					return nil, err
+				}
+
+				return wrapConnector(connector), nil
+			})
+		}),
+	}))
+
+	// the callbacks run on the JS event loop, main only has to stay alive
+	select {}
+}
+
+// method Connector operation callable from JS, the []byte result is returned as the string
+type method func(connector *exposer.Connector, args []js.Value) (interface{}, error)
+
+var methods = map[string]method{
+	"getDidString": func(c *exposer.Connector, args []js.Value) (interface{}, error) {
+		return c.GetDidString()
+	},
+	"getIdBigIntString": func(c *exposer.Connector, args []js.Value) (interface{}, error) {
+		return c.GetIdBigIntString()
+	},
+	"getOfferJson": func(c *exposer.Connector, args []js.Value) (interface{}, error) {
+		values, err := stringArgs(args, 3)
+		if err != nil {
+			return nil, err
+		}
+
+		return c.GetOfferJson(values[0], values[1], values[2])
+	},
+	"getAuthV2Inputs": func(c *exposer.Connector, args []js.Value) (interface{}, error) {
+		offerJson, err := stringArg(args, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		return c.GetAuthV2Inputs([]byte(offerJson))
+	},
+	"getVC": func(c *exposer.Connector, args []js.Value) (interface{}, error) {
+		values, err := stringArgs(args, 2)
+		if err != nil {
+			return nil, err
+		}
+
+		return c.GetVC([]byte(values[0]), []byte(values[1]))
+	},
+	"fetchVC": func(c *exposer.Connector, args []js.Value) (interface{}, error) {
+		offerJson, err := stringArg(args, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		return c.FetchVC([]byte(offerJson))
+	},
+	"getAtomicQueryMTVV2OnChainInputs": func(c *exposer.Connector, args []js.Value) (interface{}, error) {
+		values, operator, err := queryArgs(args)
+		if err != nil {
+			return nil, err
+		}
+
+		return c.GetAtomicQueryMTVV2OnChainInputs([]byte(values[0]), values[1], values[2], values[3], values[4], operator)
+	},
+	"generateAtomicQueryMTVV2OnChainProof": func(c *exposer.Connector, args []js.Value) (interface{}, error) {
+		values, operator, err := queryArgs(args)
+		if err != nil {
+			return nil, err
+		}
+
+		return c.GenerateAtomicQueryMTVV2OnChainProof([]byte(values[0]), values[1], values[2], values[3], values[4], operator)
+	},
+	"setCircuit": func(c *exposer.Connector, args []js.Value) (interface{}, error) {
+		circuitId, err := stringArg(args, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		wasm, err := bytesArg(args, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		provingKey, err := bytesArg(args, 2)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, c.SetCircuit(circuitId, wasm, provingKey)
+	},
+	"pinCircuit": func(c *exposer.Connector, args []js.Value) (interface{}, error) {
+		values, err := stringArgs(args, 4)
+		if err != nil {
+			return nil, err
+		}
+
+		c.PinCircuit(values[0], values[1], values[2], values[3])
+
+		return nil, nil
+	},
+	"prove": func(c *exposer.Connector, args []js.Value) (interface{}, error) {
+		values, err := stringArgs(args, 2)
+		if err != nil {
+			return nil, err
+		}
+
+		return c.Prove(values[0], []byte(values[1]))
+	},
+	"resolveDid": func(c *exposer.Connector, args []js.Value) (interface{}, error) {
+		did, err := stringArg(args, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		return c.ResolveDid(did)
+	},
+	"getDidDocument": func(c *exposer.Connector, args []js.Value) (interface{}, error) {
+		profileNonce, err := intArg(args, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		return c.GetDidDocument(int64(profileNonce))
+	},
+	"setGISTReference": func(c *exposer.Connector, args []js.Value) (interface{}, error) {
+		kind, err := stringArg(args, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := optionalStringArg(args, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, c.SetGISTReference(kind, value)
+	},
+	"evmGetAddress": func(c *exposer.Connector, args []js.Value) (interface{}, error) {
+		return c.EvmGetAddress()
+	},
+	"submitZKPResponse": func(c *exposer.Connector, args []js.Value) (interface{}, error) {
+		verifierAddress, err := stringArg(args, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		requestId, err := intArg(args, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		proofJson, err := stringArg(args, 2)
+		if err != nil {
+			return nil, err
+		}
+
+		return c.SubmitZKPResponse(verifierAddress, int64(requestId), []byte(proofJson))
+	},
+	"walletGetAddress": func(c *exposer.Connector, args []js.Value) (interface{}, error) {
+		return c.WalletGetAddress()
+	},
+	"walletSend": func(c *exposer.Connector, args []js.Value) (interface{}, error) {
+		values, err := stringArgs(args, 2)
+		if err != nil {
+			return nil, err
+		}
+
+		amount, err := intArg(args, 2)
+		if err != nil {
+			return nil, err
+		}
+
+		return c.WalletSend(values[0], values[1], int64(amount))
+	},
+	"exportState": func(c *exposer.Connector, args []js.Value) (interface{}, error) {
+		return c.ExportState()
+	},
+	"close": func(c *exposer.Connector, args []js.Value) (interface{}, error) {
+		return nil, c.Close()
+	},
+}
+
+// wrapConnector returns the JS object with the promise returning methods of the connector
+func wrapConnector(connector *exposer.Connector) js.Value {
+	object := js.Global().Get("Object").New()
+
+	for name, call := range methods {
+		call := call
+
+		object.Set(name, js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			return promise(func() (interface{}, error) {
+				return call(connector, args)
+			})
+		}))
+	}
+
+	return object
+}
+
+// promise runs the call on the goroutine, blocking the event loop would deadlock the network requests
+func promise(call func() (interface{}, error)) js.Value {
+	executor := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		resolve, reject := args[0], args[1]
+
+		go func() {
+			result, err := safeCall(call)
+			if err != nil {
+				reject.Invoke(toJsError(err))
+				return
+			}
+
+			if bytes, ok := result.([]byte); ok {
+				result = string(bytes)
+			}
+
+			resolve.Invoke(result)
+		}()
+
+		return nil
+	})
+	defer executor.Release()
+
+	return js.Global().Get("Promise").New(executor)
+}
+
+func safeCall(call func() (interface{}, error)) (result interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = errors.Errorf("Call panicked: %v", recovered)
+		}
+	}()
+
+	return call()
+}
+
+// toJsError keeps the "CODE: message" format of the connector errors for the ones raised here
+func toJsError(err error) js.Value {
+	code := string(errcode.Of(err))
+	message := err.Error()
+
+	if !strings.HasPrefix(message, code+": ") {
+		message = code + ": " + message
+	}
+
+	jsError := js.Global().Get("Error").New(message)
+	jsError.Set("code", code)
+
+	return jsError
+}
+
+func invalidArg(index int, expected string) error {
+	return errcode.New(errcode.InvalidInput, fmt.Sprintf("Argument %d must be the %s", index, expected))
+}
+
+func stringArg(args []js.Value, index int) (string, error) {
+	if index >= len(args) || args[index].Type() != js.TypeString {
+		return "", invalidArg(index, "string")
+	}
+
+	return args[index].String(), nil
+}
+
+func optionalStringArg(args []js.Value, index int) (string, error) {
+	if index >= len(args) || args[index].IsUndefined() || args[index].IsNull() {
+		return "", nil
+	}
+
+	return stringArg(args, index)
+}
+
+func stringArgs(args []js.Value, count int) ([]string, error) {
+	values := make([]string, count)
+
+	for i := range values {
+		value, err := stringArg(args, i)
+		if err != nil {
+			return nil, err
+		}
+
+		values[i] = value
+	}
+
+	return values, nil
+}
+
+func intArg(args []js.Value, index int) (int, error) {
+	if index >= len(args) || args[index].Type() != js.TypeNumber {
+		return 0, invalidArg(index, "number")
+	}
+
+	return args[index].Int(), nil
+}
+
+func bytesArg(args []js.Value, index int) ([]byte, error) {
+	if index >= len(args) || !args[index].InstanceOf(js.Global().Get("Uint8Array")) {
+		return nil, invalidArg(index, "Uint8Array")
+	}
+
+	value := make([]byte, args[index].Get("length").Int())
+	js.CopyBytesToGo(value, args[index])
+
+	return value, nil
+}
+
+// queryArgs reads the (vcJson, circuitId, challenge, subjectFieldName, subjectFieldValue, operator) arguments
+func queryArgs(args []js.Value) ([]string, int, error) {
+	values, err := stringArgs(args, 5)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	operator, err := intArg(args, 5)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return values, operator, nil
+}
@@ -0,0 +1,379 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/iden3/go-circuits/v2"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"strings"
+)
+
+// command Runs the subcommand, the []byte result is printed as the json it already is
+type command func(ctx context.Context, c *cli, args []string) (interface{}, error)
+
+var commands = map[string]command{
+	"did":                didCommand,
+	"offer":              offerCommand,
+	"auth-inputs":        authInputsCommand,
+	"fetch-vc":           fetchVCCommand,
+	"query-inputs":       queryInputsCommand,
+	"check-sync":         checkSyncCommand,
+	"wallet address":     walletAddressCommand,
+	"wallet balance":     walletBalanceCommand,
+	"wallet send":        walletSendCommand,
+	"credentials list":   credentialsListCommand,
+	"credentials show":   credentialsShowCommand,
+	"credentials save":   credentialsSaveCommand,
+	"credentials remove": credentialsRemoveCommand,
+}
+
+func newFlags(c *cli, name string, arguments string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(c.stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(c.stderr, strings.TrimSpace("Usage: zkp-iden3 "+name+" [flags] "+arguments))
+		flags.PrintDefaults()
+	}
+
+	return flags
+}
+
+// parse parses the flags and checks the required ones and the number of the positional arguments
+func parse(flags *flag.FlagSet, args []string, positional int, required ...string) error {
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+
+	set := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	missing := make([]string, 0, len(required))
+	for _, name := range required {
+		if !set[name] {
+			missing = append(missing, "--"+name)
+		}
+	}
+
+	if len(missing) > 0 {
+		fmt.Fprintf(flags.Output(), "missing required flags %s\n", strings.Join(missing, ", "))
+		flags.Usage()
+		return errUsage
+	}
+
+	if flags.NArg() != positional {
+		fmt.Fprintf(flags.Output(), "expected %d arguments, got %d\n", positional, flags.NArg())
+		flags.Usage()
+		return errUsage
+	}
+
+	return nil
+}
+
+func didCommand(ctx context.Context, c *cli, args []string) (interface{}, error) {
+	if err := parse(newFlags(c, "did", ""), args, 0); err != nil {
+		return nil, err
+	}
+
+	connector, err := c.newConnector(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer connector.Close()
+
+	did, err := connector.GetDidString()
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := connector.GetIdBigIntString()
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"did": did, "id": id}, nil
+}
+
+func offerCommand(ctx context.Context, c *cli, args []string) (interface{}, error) {
+	flags := newFlags(c, "offer", "")
+	issuer := flags.String("issuer", "", "issuer API url")
+	did := flags.String("did", "", "DID the offer is issued to, defaults to the connector one")
+	claimType := flags.String("type", "", "claim type")
+
+	if err := parse(flags, args, 0, "issuer", "type"); err != nil {
+		return nil, err
+	}
+
+	connector, err := c.newConnector(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer connector.Close()
+
+	if *did == "" {
+		if *did, err = connector.GetDidString(); err != nil {
+			return nil, err
+		}
+	}
+
+	return connector.GetOfferJson(*issuer, *did, *claimType)
+}
+
+func authInputsCommand(ctx context.Context, c *cli, args []string) (interface{}, error) {
+	flags := newFlags(c, "auth-inputs", "")
+	offerPath := flags.String("offer", "", "offer json file, - reads stdin")
+
+	if err := parse(flags, args, 0, "offer"); err != nil {
+		return nil, err
+	}
+
+	offerJson, err := c.readInput(*offerPath)
+	if err != nil {
+		return nil, err
+	}
+
+	connector, err := c.newConnector(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer connector.Close()
+
+	return connector.GetAuthV2Inputs(offerJson)
+}
+
+func fetchVCCommand(ctx context.Context, c *cli, args []string) (interface{}, error) {
+	flags := newFlags(c, "fetch-vc", "")
+	offerPath := flags.String("offer", "", "offer json file, - reads stdin")
+	save := flags.Bool("save", false, "store the credential")
+
+	if err := parse(flags, args, 0, "offer"); err != nil {
+		return nil, err
+	}
+
+	offerJson, err := c.readInput(*offerPath)
+	if err != nil {
+		return nil, err
+	}
+
+	connector, err := c.newConnector(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer connector.Close()
+
+	vcJson, err := connector.FetchVC(offerJson)
+	if err != nil {
+		return nil, err
+	}
+
+	if *save {
+		store, err := c.store()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := store.Save(vcJson); err != nil {
+			return nil, err
+		}
+	}
+
+	return vcJson, nil
+}
+
+func queryInputsCommand(ctx context.Context, c *cli, args []string) (interface{}, error) {
+	flags := newFlags(c, "query-inputs", "")
+	vcPath := flags.String("vc", "", "credential json file, - reads stdin")
+	credentialId := flags.String("credential", "", "id of the stored credential, used without --vc")
+	circuitId := flags.String("circuit", string(circuits.AtomicQueryMTPV2OnChainCircuitID), "circuit id")
+	challenge := flags.String("challenge", "", "challenge hex")
+	field := flags.String("field", "", "credential subject field name")
+	value := flags.String("value", "", "credential subject field value")
+	operator := flags.Int("operator", 1, "query operator, 1 is $eq")
+
+	if err := parse(flags, args, 0, "challenge", "field", "value"); err != nil {
+		return nil, err
+	}
+
+	var (
+		vcJson []byte
+		err    error
+	)
+
+	switch {
+	case *vcPath != "":
+		vcJson, err = c.readInput(*vcPath)
+	case *credentialId != "":
+		var store *credentialStore
+		if store, err = c.store(); err == nil {
+			vcJson, err = store.Get(*credentialId)
+		}
+	default:
+		err = errcode.New(errcode.InvalidInput, "one of --vc or --credential is required")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	connector, err := c.newConnector(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer connector.Close()
+
+	return connector.GetAtomicQueryMTVV2OnChainInputs(vcJson, *circuitId, *challenge, *field, *value, *operator)
+}
+
+func checkSyncCommand(ctx context.Context, c *cli, args []string) (interface{}, error) {
+	flags := newFlags(c, "check-sync", "")
+	issuer := flags.String("issuer", "", "issuer DID")
+
+	if err := parse(flags, args, 0, "issuer"); err != nil {
+		return nil, err
+	}
+
+	connector, err := c.newConnector(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer connector.Close()
+
+	return connector.CheckStateContractSync(*issuer)
+}
+
+func walletAddressCommand(ctx context.Context, c *cli, args []string) (interface{}, error) {
+	if err := parse(newFlags(c, "wallet address", ""), args, 0); err != nil {
+		return nil, err
+	}
+
+	connector, err := c.newConnector(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer connector.Close()
+
+	address, err := connector.WalletGetAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"address": address}, nil
+}
+
+func walletBalanceCommand(ctx context.Context, c *cli, args []string) (interface{}, error) {
+	flags := newFlags(c, "wallet balance", "")
+	address := flags.String("address", "", "address to check, defaults to the wallet one")
+
+	if err := parse(flags, args, 0); err != nil {
+		return nil, err
+	}
+
+	connector, err := c.newConnector(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer connector.Close()
+
+	return connector.WalletGetBalance(*address)
+}
+
+func walletSendCommand(ctx context.Context, c *cli, args []string) (interface{}, error) {
+	flags := newFlags(c, "wallet send", "")
+	from := flags.String("from", "", "sender address, defaults to the wallet one")
+	to := flags.String("to", "", "recipient address")
+	amount := flags.Int64("amount", 0, "amount of the connector denom")
+
+	if err := parse(flags, args, 0, "to", "amount"); err != nil {
+		return nil, err
+	}
+
+	if *amount <= 0 {
+		return nil, errcode.New(errcode.InvalidInput, "amount must be positive")
+	}
+
+	connector, err := c.newConnector(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer connector.Close()
+
+	if *from == "" {
+		if *from, err = connector.WalletGetAddress(); err != nil {
+			return nil, err
+		}
+	}
+
+	return connector.WalletSend(*from, *to, *amount)
+}
+
+func credentialsListCommand(ctx context.Context, c *cli, args []string) (interface{}, error) {
+	if err := parse(newFlags(c, "credentials list", ""), args, 0); err != nil {
+		return nil, err
+	}
+
+	store, err := c.store()
+	if err != nil {
+		return nil, err
+	}
+
+	return store.List()
+}
+
+func credentialsShowCommand(ctx context.Context, c *cli, args []string) (interface{}, error) {
+	flags := newFlags(c, "credentials show", "<id>")
+	if err := parse(flags, args, 1); err != nil {
+		return nil, err
+	}
+
+	store, err := c.store()
+	if err != nil {
+		return nil, err
+	}
+
+	return store.Get(flags.Arg(0))
+}
+
+func credentialsSaveCommand(ctx context.Context, c *cli, args []string) (interface{}, error) {
+	flags := newFlags(c, "credentials save", "<file>")
+	if err := parse(flags, args, 1); err != nil {
+		return nil, err
+	}
+
+	vcJson, err := c.readInput(flags.Arg(0))
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := c.store()
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := store.Save(vcJson)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to save credential")
+	}
+
+	return map[string]string{"id": id}, nil
+}
+
+func credentialsRemoveCommand(ctx context.Context, c *cli, args []string) (interface{}, error) {
+	flags := newFlags(c, "credentials remove", "<id>")
+	if err := parse(flags, args, 1); err != nil {
+		return nil, err
+	}
+
+	store, err := c.store()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := store.Remove(flags.Arg(0)); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"id": flags.Arg(0)}, nil
+}
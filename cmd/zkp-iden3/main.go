@@ -0,0 +1,332 @@
+// Command zkp-iden3 runs the connector flows from the terminal, so the mobile issuance and proving
+// issues can be reproduced without writing Go code against the live endpoints.
+//
+// The connector is read from the config (--config or ZKP_CONFIG), the exported state (--state or
+// ZKP_STATE) or the network preset (--network or ZKP_NETWORK). The private key may be left out of
+// the files and passed with ZKP_PK_HEX. The results are printed to stdout as JSON, the failures
+// to stderr as {"code":...,"message":...}
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/pkg/errors"
+	exposer "github.com/rarimo/zkp-iden3-exposer"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const usage = `Usage: zkp-iden3 [flags] <command> [command flags]
+
+Commands:
+  did                     print the DID and the ID of the identity
+  offer                   get the credential offer from the issuer
+  auth-inputs             build the AuthV2 inputs for the offer
+  fetch-vc                prove the AuthV2 inputs and load the credential
+  query-inputs            build the AtomicQueryMTPV2OnChain inputs for the credential
+  check-sync              check whether the issuer state is transited to the target chain
+  wallet address          print the wallet address
+  wallet balance          print the wallet balance
+  wallet send             transfer the tokens
+  credentials list        list the stored credentials
+  credentials show <id>   print the stored credential
+  credentials save <file> store the credential, - reads stdin
+  credentials remove <id> remove the stored credential
+
+Flags:
+`
+
+// env Lookup of the environment variables, replaced in the tests
+type env func(key string) string
+
+// cli Parsed global flags and the streams of the run
+type cli struct {
+	configPath    string
+	statePath     string
+	network       string
+	overridesPath string
+	circuitsDir   string
+	unpinned      bool
+	storeDir      string
+	timeout       time.Duration
+	verbose       bool
+
+	env    env
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Getenv, os.Stdin, os.Stdout, os.Stderr)
+	stop()
+
+	os.Exit(code)
+}
+
+// run executes the command and returns the exit code, 1 for the failed command and 2 for the misuse
+func run(ctx context.Context, args []string, getenv env, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	c := &cli{env: getenv, stdin: stdin, stdout: stdout, stderr: stderr}
+
+	flags := flag.NewFlagSet("zkp-iden3", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+
+	flags.StringVar(&c.configPath, "config", getenv("ZKP_CONFIG"), "connector config json file")
+	flags.StringVar(&c.statePath, "state", getenv("ZKP_STATE"), "connector state json file")
+	flags.StringVar(&c.network, "network", getenv("ZKP_NETWORK"), "network preset name")
+	flags.StringVar(&c.overridesPath, "overrides", getenv("ZKP_OVERRIDES"), "network preset overrides json file")
+	flags.StringVar(&c.circuitsDir, "circuits-dir", getenv("ZKP_CIRCUITS_DIR"), "directory of the circuit artifacts")
+	flags.BoolVar(&c.unpinned, "allow-unpinned-circuits", false, "accept the circuit artifacts without the pinned digest")
+	flags.StringVar(&c.storeDir, "store", getenv("ZKP_CREDENTIALS_DIR"), "credential store directory")
+	flags.DurationVar(&c.timeout, "timeout", 0, "timeout of the command, zero waits forever")
+	flags.BoolVar(&c.verbose, "verbose", false, "print the flow steps to stderr")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	name, commandArgs := flags.Arg(0), flags.Args()[1:]
+	if (name == "wallet" || name == "credentials") && len(commandArgs) > 0 {
+		name, commandArgs = name+" "+commandArgs[0], commandArgs[1:]
+	}
+
+	command, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", name)
+		flags.Usage()
+		return 2
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	result, err := command(ctx, c, commandArgs)
+	if errors.Is(err, flag.ErrHelp) || errors.Is(err, errUsage) {
+		return 2
+	}
+
+	if err != nil {
+		c.printError(err)
+		return 1
+	}
+
+	if err := c.printJSON(result); err != nil {
+		c.printError(err)
+		return 1
+	}
+
+	return 0
+}
+
+// errUsage The command flags are invalid, the command has already printed the reason
+var errUsage = errors.New("invalid usage")
+
+// newConnector creates the connector from the config, the state or the network preset in that order
+func (c *cli) newConnector(ctx context.Context) (*exposer.Connector, error) {
+	connector, err := c.loadConnector()
+	if err != nil {
+		return nil, err
+	}
+
+	connector.AllowUnpinnedCircuits(c.unpinned)
+
+	if c.circuitsDir != "" {
+		connector.SetCircuitsDir(c.circuitsDir)
+	}
+
+	if c.verbose {
+		connector.SetEventListener(&stepPrinter{out: c.stderr})
+	}
+
+	return connector.WithContext(ctx), nil
+}
+
+func (c *cli) loadConnector() (*exposer.Connector, error) {
+	pkHex := c.env("ZKP_PK_HEX")
+
+	switch {
+	case c.configPath != "":
+		configJson, err := os.ReadFile(c.configPath)
+		if err != nil {
+			return nil, errcode.Wrap(err, errcode.InvalidConfig, "failed to read config")
+		}
+
+		if pkHex != "" {
+			if configJson, err = withPkHex(configJson, pkHex); err != nil {
+				return nil, err
+			}
+		}
+
+		return exposer.NewConnectorFromJSON(configJson)
+	case c.statePath != "":
+		stateJson, err := os.ReadFile(c.statePath)
+		if err != nil {
+			return nil, errcode.Wrap(err, errcode.InvalidConfig, "failed to read state")
+		}
+
+		if pkHex != "" {
+			if stateJson, err = withStatePkHex(stateJson, pkHex); err != nil {
+				return nil, err
+			}
+		}
+
+		return exposer.NewConnectorFromState(stateJson)
+	case c.network != "":
+		var overridesJson []byte
+		if c.overridesPath != "" {
+			var err error
+			if overridesJson, err = os.ReadFile(c.overridesPath); err != nil {
+				return nil, errcode.Wrap(err, errcode.InvalidConfig, "failed to read overrides")
+			}
+		}
+
+		return exposer.NewConnectorFromNetwork(c.network, pkHex, overridesJson)
+	default:
+		return nil, errcode.New(errcode.InvalidConfig, "one of --config, --state or --network is required")
+	}
+}
+
+// withPkHex sets the key of the config unless the file has its own one
+func withPkHex(configJson []byte, pkHex string) ([]byte, error) {
+	config := map[string]json.RawMessage{}
+	if err := json.Unmarshal(configJson, &config); err != nil {
+		return nil, errcode.Wrap(err, errcode.InvalidConfig, "failed to unmarshal config")
+	}
+
+	if key, ok := config["pkHex"]; ok && string(key) != `""` {
+		return configJson, nil
+	}
+
+	config["pkHex"], _ = json.Marshal(pkHex)
+
+	return json.Marshal(config)
+}
+
+// withStatePkHex sets the key of the config embedded in the exported state, see withPkHex
+func withStatePkHex(stateJson []byte, pkHex string) ([]byte, error) {
+	state := map[string]json.RawMessage{}
+	if err := json.Unmarshal(stateJson, &state); err != nil {
+		return nil, errcode.Wrap(err, errcode.InvalidConfig, "failed to unmarshal state")
+	}
+
+	configJson, ok := state["config"]
+	if !ok {
+		return stateJson, nil
+	}
+
+	configJson, err := withPkHex(configJson, pkHex)
+	if err != nil {
+		return nil, err
+	}
+
+	state["config"] = configJson
+
+	return json.Marshal(state)
+}
+
+func (c *cli) store() (*credentialStore, error) {
+	dir := c.storeDir
+	if dir == "" {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, errcode.Wrap(err, errcode.InvalidConfig, "failed to get default credential store directory, set --store")
+		}
+
+		dir = filepath.Join(configDir, "zkp-iden3", "credentials")
+	}
+
+	return newCredentialStore(dir), nil
+}
+
+// readInput reads the file, - stands for stdin
+func (c *cli) readInput(path string) ([]byte, error) {
+	if path == "-" {
+		data, err := io.ReadAll(c.stdin)
+		return data, errors.Wrap(err, "failed to read stdin")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errcode.Wrap(err, errcode.InvalidInput, "failed to read "+path)
+	}
+
+	return data, nil
+}
+
+// printJSON prints the json encoded bytes as they are and marshals the rest
+func (c *cli) printJSON(result interface{}) error {
+	raw, ok := result.([]byte)
+	if !ok {
+		var err error
+		if raw, err = json.Marshal(result); err != nil {
+			return errors.Wrap(err, "failed to marshal result")
+		}
+	}
+
+	out := bytes.Buffer{}
+	if err := json.Indent(&out, raw, "", "  "); err != nil {
+		return errors.Wrap(err, "failed to format result")
+	}
+
+	out.WriteByte('\n')
+	_, err := out.WriteTo(c.stdout)
+
+	return err
+}
+
+func (c *cli) printError(err error) {
+	code := errcode.Of(err)
+
+	failure, _ := json.Marshal(map[string]string{
+		"code":    string(code),
+		"message": strings.TrimPrefix(err.Error(), string(code)+": "),
+	})
+
+	fmt.Fprintln(c.stderr, string(failure))
+}
+
+// stepPrinter Prints the connector flow steps, the flow itself is reported as the empty step
+type stepPrinter struct {
+	out io.Writer
+}
+
+func (p *stepPrinter) OnStepStarted(flow string, step string) {
+	fmt.Fprintf(p.out, "%s %s started\n", flow, stepName(step))
+}
+
+func (p *stepPrinter) OnStepFinished(flow string, step string, durationMillis int64) {
+	fmt.Fprintf(p.out, "%s %s finished in %dms\n", flow, stepName(step), durationMillis)
+}
+
+func (p *stepPrinter) OnStepFailed(flow string, step string, durationMillis int64, errorCode string, errorMessage string) {
+	fmt.Fprintf(p.out, "%s %s failed in %dms: %s\n", flow, stepName(step), durationMillis, errorMessage)
+}
+
+func stepName(step string) string {
+	if step == "" {
+		return "flow"
+	}
+
+	return step
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	exposer "github.com/rarimo/zkp-iden3-exposer"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const pkHex = "1cbd5d2d1801e964736881fc0584473f23ba82669599ac65957fb4f2caf43e17"
+
+type result struct {
+	code   int
+	stdout string
+	stderr string
+}
+
+func runCli(t *testing.T, environment map[string]string, stdin string, args ...string) result {
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+
+	code := run(
+		context.Background(),
+		args,
+		func(key string) string { return environment[key] },
+		strings.NewReader(stdin),
+		&stdout,
+		&stderr,
+	)
+
+	return result{code: code, stdout: stdout.String(), stderr: stderr.String()}
+}
+
+func TestCli(t *testing.T) {
+	issuer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"1","type":"offer","body":{"url":"http://issuer","credentials":[]}}`))
+	}))
+	defer issuer.Close()
+
+	core := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/cosmos/bank/v1beta1/balances/") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Write([]byte(`{"balance":{"denom":"` + r.URL.Query().Get("denom") + `","amount":"100"}}`))
+	}))
+	defer core.Close()
+
+	overridesPath := filepath.Join(t.TempDir(), "overrides.json")
+	if err := os.WriteFile(overridesPath, []byte(`{"chainInfo":{"coreApiUrl":"`+core.URL+`"}}`), 0o600); err != nil {
+		t.Fatalf("Error writing overrides: %v", err)
+	}
+
+	environment := map[string]string{
+		"ZKP_NETWORK":         "mainnet-beta",
+		"ZKP_PK_HEX":          pkHex,
+		"ZKP_OVERRIDES":       overridesPath,
+		"ZKP_CREDENTIALS_DIR": filepath.Join(t.TempDir(), "credentials"),
+	}
+
+	t.Run("Should print the DID", func(t *testing.T) {
+		r := runCli(t, environment, "", "did")
+		if r.code != 0 {
+			t.Fatalf("Unexpected exit code %d: %s", r.code, r.stderr)
+		}
+
+		did := map[string]string{}
+		if err := json.Unmarshal([]byte(r.stdout), &did); err != nil {
+			t.Fatalf("Error unmarshalling output: %v", err)
+		}
+
+		if !strings.HasPrefix(did["did"], "did:iden3:") || did["id"] == "" {
+			t.Errorf("Unexpected output %s", r.stdout)
+		}
+	})
+
+	t.Run("Should read the config file with the key from env", func(t *testing.T) {
+		preset := runCli(t, environment, "", "did")
+
+		configPath := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(configPath, []byte(exportConfig(t)), 0o600); err != nil {
+			t.Fatalf("Error writing config: %v", err)
+		}
+
+		r := runCli(t, map[string]string{"ZKP_PK_HEX": pkHex}, "", "--config", configPath, "did")
+		if r.code != 0 {
+			t.Fatalf("Unexpected exit code %d: %s", r.code, r.stderr)
+		}
+
+		if r.stdout != preset.stdout {
+			t.Errorf("Expected %s, got %s", preset.stdout, r.stdout)
+		}
+	})
+
+	t.Run("Should read the state file with the key from env", func(t *testing.T) {
+		preset := runCli(t, environment, "", "did")
+
+		statePath := filepath.Join(t.TempDir(), "state.json")
+		if err := os.WriteFile(statePath, []byte(exportState(t)), 0o600); err != nil {
+			t.Fatalf("Error writing state: %v", err)
+		}
+
+		if r := runCli(t, nil, "", "--state", statePath, "did"); r.code == 0 {
+			t.Errorf("Expected the state without the key to be rejected, got %s", r.stdout)
+		}
+
+		r := runCli(t, map[string]string{"ZKP_PK_HEX": pkHex}, "", "--state", statePath, "did")
+		if r.code != 0 {
+			t.Fatalf("Unexpected exit code %d: %s", r.code, r.stderr)
+		}
+
+		if r.stdout != preset.stdout {
+			t.Errorf("Expected %s, got %s", preset.stdout, r.stdout)
+		}
+	})
+
+	t.Run("Should get the offer", func(t *testing.T) {
+		r := runCli(t, environment, "", "offer", "--issuer", issuer.URL, "--type", "claim")
+		if r.code != 0 {
+			t.Fatalf("Unexpected exit code %d: %s", r.code, r.stderr)
+		}
+
+		if !strings.Contains(r.stdout, `"type": "offer"`) {
+			t.Errorf("Unexpected output %s", r.stdout)
+		}
+	})
+
+	t.Run("Should get the wallet balance", func(t *testing.T) {
+		r := runCli(t, environment, "", "wallet", "balance")
+		if r.code != 0 {
+			t.Fatalf("Unexpected exit code %d: %s", r.code, r.stderr)
+		}
+
+		if !strings.Contains(r.stdout, `"amount": "100"`) {
+			t.Errorf("Unexpected output %s", r.stdout)
+		}
+	})
+
+	t.Run("Should manage the stored credentials", func(t *testing.T) {
+		vc := `{"id":"urn:uuid:1","type":["VerifiableCredential","KYC"],"issuer":"did:iden3:issuer"}`
+
+		if r := runCli(t, environment, vc, "credentials", "save", "-"); r.code != 0 {
+			t.Fatalf("Unexpected exit code %d: %s", r.code, r.stderr)
+		}
+
+		r := runCli(t, environment, "", "credentials", "list")
+		if r.code != 0 || !strings.Contains(r.stdout, `"id": "urn:uuid:1"`) {
+			t.Fatalf("Unexpected list %d: %s %s", r.code, r.stdout, r.stderr)
+		}
+
+		r = runCli(t, environment, "", "credentials", "show", "urn:uuid:1")
+		if r.code != 0 || !strings.Contains(r.stdout, `"issuer": "did:iden3:issuer"`) {
+			t.Fatalf("Unexpected credential %d: %s %s", r.code, r.stdout, r.stderr)
+		}
+
+		if r := runCli(t, environment, "", "credentials", "remove", "urn:uuid:1"); r.code != 0 {
+			t.Fatalf("Unexpected exit code %d: %s", r.code, r.stderr)
+		}
+
+		r = runCli(t, environment, "", "credentials", "show", "urn:uuid:1")
+		if r.code != 1 || !strings.Contains(r.stderr, `"code":"NOT_FOUND"`) {
+			t.Errorf("Expected not found, got %d: %s", r.code, r.stderr)
+		}
+	})
+
+	t.Run("Should print the error with the code", func(t *testing.T) {
+		r := runCli(t, map[string]string{}, "", "did")
+		if r.code != 1 {
+			t.Fatalf("Unexpected exit code %d", r.code)
+		}
+
+		failure := map[string]string{}
+		if err := json.Unmarshal([]byte(r.stderr), &failure); err != nil {
+			t.Fatalf("Error unmarshalling error: %v", err)
+		}
+
+		if failure["code"] != "INVALID_CONFIG" {
+			t.Errorf("Unexpected error %s", r.stderr)
+		}
+	})
+
+	t.Run("Should reject the misuse", func(t *testing.T) {
+		if r := runCli(t, environment, "", "unknown"); r.code != 2 {
+			t.Errorf("Expected exit code 2 for unknown command, got %d", r.code)
+		}
+
+		if r := runCli(t, environment, "", "offer", "--type", "claim"); r.code != 2 {
+			t.Errorf("Expected exit code 2 for missing flag, got %d", r.code)
+		}
+	})
+}
+
+// exportConfig returns the config of the network preset without the key
+func exportConfig(t *testing.T) string {
+	connector, err := exposer.NewConnectorFromNetwork("mainnet-beta", pkHex, nil)
+	if err != nil {
+		t.Fatalf("Error creating connector: %v", err)
+	}
+
+	configJson, err := connector.ToJSON()
+	if err != nil {
+		t.Fatalf("Error marshalling config: %v", err)
+	}
+
+	config := map[string]interface{}{}
+	if err := json.Unmarshal(configJson, &config); err != nil {
+		t.Fatalf("Error unmarshalling config: %v", err)
+	}
+
+	delete(config, "pkHex")
+
+	configJson, err = json.Marshal(config)
+	if err != nil {
+		t.Fatalf("Error marshalling config: %v", err)
+	}
+
+	return string(configJson)
+}
+
+// exportState returns the exported connector state with the key left out of its config
+func exportState(t *testing.T) string {
+	connector, err := exposer.NewConnectorFromNetwork("mainnet-beta", pkHex, nil)
+	if err != nil {
+		t.Fatalf("Error creating connector: %v", err)
+	}
+
+	stateJson, err := connector.ExportState()
+	if err != nil {
+		t.Fatalf("Error exporting state: %v", err)
+	}
+
+	state := map[string]json.RawMessage{}
+	if err := json.Unmarshal(stateJson, &state); err != nil {
+		t.Fatalf("Error unmarshalling state: %v", err)
+	}
+
+	state["config"] = json.RawMessage(exportConfig(t))
+
+	stateJson, err = json.Marshal(state)
+	if err != nil {
+		t.Fatalf("Error marshalling state: %v", err)
+	}
+
+	return string(stateJson)
+}
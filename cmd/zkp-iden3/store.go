@@ -0,0 +1,133 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// credentialStore Keeps the credentials as json files in the directory, the file name is derived
+// from the credential id as the ids are urls
+type credentialStore struct {
+	dir string
+}
+
+// credentialSummary Listed fields of the stored credential
+type credentialSummary struct {
+	Id             string   `json:"id"`
+	Type           []string `json:"type"`
+	Issuer         string   `json:"issuer"`
+	IssuanceDate   string   `json:"issuanceDate,omitempty"`
+	ExpirationDate string   `json:"expirationDate,omitempty"`
+}
+
+func newCredentialStore(dir string) *credentialStore {
+	return &credentialStore{dir: dir}
+}
+
+func (s *credentialStore) path(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:16])+".json")
+}
+
+// Save stores the credential under its id, the stored one with the same id is replaced
+func (s *credentialStore) Save(vcJson []byte) (string, error) {
+	summary, err := summarize(vcJson)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return "", errors.Wrap(err, "failed to create credential store")
+	}
+
+	// the credential is written aside and renamed, so the interrupted save never leaves the broken file
+	path := s.path(summary.Id)
+	if err := os.WriteFile(path+".tmp", vcJson, 0o600); err != nil {
+		return "", errors.Wrap(err, "failed to write credential")
+	}
+
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return "", errors.Wrap(err, "failed to write credential")
+	}
+
+	return summary.Id, nil
+}
+
+func (s *credentialStore) Get(id string) ([]byte, error) {
+	vcJson, err := os.ReadFile(s.path(id))
+	if os.IsNotExist(err) {
+		return nil, errcode.New(errcode.NotFound, "credential "+id+" is not stored")
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read credential")
+	}
+
+	return vcJson, nil
+}
+
+func (s *credentialStore) Remove(id string) error {
+	err := os.Remove(s.path(id))
+	if os.IsNotExist(err) {
+		return errcode.New(errcode.NotFound, "credential "+id+" is not stored")
+	}
+
+	return errors.Wrap(err, "failed to remove credential")
+}
+
+// List returns the stored credentials ordered by id, the missing store is empty
+func (s *credentialStore) List() ([]credentialSummary, error) {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return []credentialSummary{}, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read credential store")
+	}
+
+	summaries := make([]credentialSummary, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		vcJson, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read credential")
+		}
+
+		summary, err := summarize(vcJson)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read credential %s", entry.Name())
+		}
+
+		summaries = append(summaries, *summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Id < summaries[j].Id
+	})
+
+	return summaries, nil
+}
+
+func summarize(vcJson []byte) (*credentialSummary, error) {
+	summary := credentialSummary{}
+	if err := json.Unmarshal(vcJson, &summary); err != nil {
+		return nil, errcode.Wrap(err, errcode.InvalidInput, "failed to unmarshal credential")
+	}
+
+	if summary.Id == "" {
+		return nil, errcode.New(errcode.InvalidInput, "credential id is required")
+	}
+
+	return &summary, nil
+}
@@ -0,0 +1,213 @@
+package zkp_iden3_exposer
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"github.com/rarimo/zkp-iden3-exposer/evm/backend"
+	"github.com/rarimo/zkp-iden3-exposer/issuerstate"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/didtype"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// NewConnectorFromJSON creates the connector from the json encoded fields of Connector,
+// the unknown fields and the malformed values are rejected
+func NewConnectorFromJSON(configJson []byte) (_ *Connector, err error) {
+	defer withCode(&err)
+
+	connector, err := decodeConnector(configJson)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := connector.Validate(); err != nil {
+		return nil, err
+	}
+
+	return connector, nil
+}
+
+func decodeConnector(configJson []byte) (*Connector, error) {
+	connector := Connector{}
+
+	if err := decodeStrict(configJson, &connector); err != nil {
+		return nil, errcode.Wrap(err, errcode.InvalidConfig, "Error decoding connector config")
+	}
+
+	return &connector, nil
+}
+
+func decodeStrict(data []byte, value interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(value); err != nil {
+		return err
+	}
+
+	if _, err := decoder.Token(); err != io.EOF {
+		return errors.New("unexpected data after the json object")
+	}
+
+	return nil
+}
+
+// ToJSON returns the config of the connector in the NewConnectorFromJSON format, it contains the private key
+func (c *Connector) ToJSON() (_ []byte, err error) {
+	defer withCode(&err)
+
+	configJson, err := json.Marshal(c)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error marshalling connector config")
+	}
+
+	return configJson, nil
+}
+
+// Validate checks the connector fields and reports all the malformed ones at once. The target chain
+// and the wallet fields are optional, they are only checked when set
+func (c *Connector) Validate() (err error) {
+	defer withCode(&err)
+
+	var problems []string
+	check := func(err error) {
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	check(validatePrivateKey(c.PkHex))
+	check(validateIdType(c.IdType))
+	check(validateSchemaHash(c.SchemaHashHex))
+
+	if c.TargetChainId < 0 {
+		check(errors.New("targetChainId must not be negative"))
+	}
+
+	if c.TargetRpcUrl != "" {
+		check(validateUrls("targetRpcUrl", c.TargetRpcUrl, "http", "https", "ws", "wss"))
+	}
+
+	if c.TargetStateContractAddress != "" {
+		check(validateAddress("targetStateContractAddress", c.TargetStateContractAddress))
+	}
+
+	switch c.IssuerStateSource {
+	case "", issuerstate.SourceRest:
+		check(validateUrls("coreApiUrl", c.CoreApiUrl, "http", "https"))
+	case issuerstate.SourceEvm:
+		if c.CoreApiUrl != "" {
+			check(validateUrls("coreApiUrl", c.CoreApiUrl, "http", "https"))
+		}
+	default:
+		check(errors.Errorf("issuerStateSource must be %q or %q", issuerstate.SourceRest, issuerstate.SourceEvm))
+	}
+
+	check(validateUrls("coreEvmRpcApiUrl", c.CoreEvmRpcApiUrl, "http", "https", "ws", "wss"))
+	check(validateAddress("coreStateContractAddress", c.CoreStateContractAddress))
+
+	if c.RpcApi != "" {
+		if _, _, err := net.SplitHostPort(c.RpcApi); err != nil {
+			check(errors.Wrap(err, "rpcApi must be host:port"))
+		}
+	}
+
+	if c.MinGasPrice < 0 || c.GasLimit < 0 {
+		check(errors.New("minGasPrice and gasLimit must not be negative"))
+	}
+
+	if len(problems) != 0 {
+		return errcode.New(errcode.InvalidConfig, "Invalid connector config: "+strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+func validatePrivateKey(pkHex string) error {
+	if pkHex == "" {
+		return errors.New("pkHex is required")
+	}
+
+	key, err := hex.DecodeString(pkHex)
+	if err != nil || len(key) != 32 {
+		return errors.New("pkHex must be 32 bytes hex encoded without 0x prefix")
+	}
+
+	return nil
+}
+
+func validateIdType(idType []byte) error {
+	if len(idType) != 2 {
+		return errors.Errorf("idType must be 2 bytes, got %d", len(idType))
+	}
+
+	if _, err := didtype.Parse(idType); err != nil {
+		return errors.Wrap(err, "idType")
+	}
+
+	return nil
+}
+
+func validateSchemaHash(schemaHashHex string) error {
+	schemaHash, err := hex.DecodeString(schemaHashHex)
+	if err != nil || len(schemaHash) != 16 {
+		return errors.New("schemaHashHex must be 16 bytes hex encoded")
+	}
+
+	return nil
+}
+
+// validateUrls checks the comma separated list of the absolute URLs
+func validateUrls(field string, value string, schemes ...string) error {
+	urls := backend.SplitUrls(value)
+	if len(urls) == 0 {
+		return errors.Errorf("%s is required", field)
+	}
+
+	for _, rawUrl := range urls {
+		parsed, err := url.Parse(rawUrl)
+		if err != nil {
+			return errors.Wrapf(err, "%s", field)
+		}
+
+		if parsed.Host == "" || !containsString(schemes, parsed.Scheme) {
+			return errors.Errorf("%s must be the %s URL, got %q", field, strings.Join(schemes, "/"), rawUrl)
+		}
+	}
+
+	return nil
+}
+
+// validateAddress checks the 0x prefixed address, the mixed case one must match its EIP-55 checksum
+func validateAddress(field string, value string) error {
+	if value == "" {
+		return errors.Errorf("%s is required", field)
+	}
+
+	if !strings.HasPrefix(value, "0x") || !common.IsHexAddress(value) {
+		return errors.Errorf("%s must be the 0x prefixed address, got %q", field, value)
+	}
+
+	digits := value[2:]
+	if digits != strings.ToLower(digits) && digits != strings.ToUpper(digits) && common.HexToAddress(value).Hex() != value {
+		return errors.Errorf("%s has the invalid checksum, expected %s", field, common.HexToAddress(value).Hex())
+	}
+
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,106 @@
+package zkp_iden3_exposer
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func getConfigJson(t *testing.T, overrides map[string]interface{}) []byte {
+	connector, err := NewConnectorFromNetwork(
+		"mainnet-beta",
+		"1cbd5d2d1801e964736881fc0584473f23ba82669599ac65957fb4f2caf43e17",
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("Error creating connector: %v", err)
+	}
+
+	configJson, err := connector.ToJSON()
+	if err != nil {
+		t.Fatalf("Error marshalling config: %v", err)
+	}
+
+	config := map[string]interface{}{}
+	if err := json.Unmarshal(configJson, &config); err != nil {
+		t.Fatalf("Error unmarshalling config: %v", err)
+	}
+
+	for key, value := range overrides {
+		config[key] = value
+	}
+
+	configJson, err = json.Marshal(config)
+	if err != nil {
+		t.Fatalf("Error marshalling config: %v", err)
+	}
+
+	return configJson
+}
+
+func TestNewConnectorFromJSON(t *testing.T) {
+	t.Run("Should create connector from config", func(t *testing.T) {
+		connector, err := NewConnectorFromJSON(getConfigJson(t, nil))
+		if err != nil {
+			t.Fatalf("Error creating connector: %v", err)
+		}
+
+		if connector.CoreStateContractAddress != "0x753a8678c85d5fb70A97CFaE37c84CE2fD67EDE8" {
+			t.Errorf("Unexpected core state contract address %s", connector.CoreStateContractAddress)
+		}
+	})
+
+	invalid := []struct {
+		name      string
+		overrides map[string]interface{}
+		problem   string
+	}{
+		{"Should reject unknown field", map[string]interface{}{"pkhex2": "1"}, "unknown field"},
+		{"Should reject empty key", map[string]interface{}{"pkHex": ""}, "pkHex is required"},
+		{"Should reject short key", map[string]interface{}{"pkHex": "1cbd"}, "pkHex must be 32 bytes"},
+		{"Should reject id type length", map[string]interface{}{"idType": "AQ=="}, "idType must be 2 bytes"},
+		{"Should reject url scheme", map[string]interface{}{"coreApiUrl": "ftp://rarimo.com"}, "coreApiUrl"},
+		{"Should reject relative url", map[string]interface{}{"targetRpcUrl": "rpc.rarimo.com"}, "targetRpcUrl"},
+		{"Should reject address checksum", map[string]interface{}{"coreStateContractAddress": "0x753A8678c85d5fb70A97CFaE37c84CE2fD67EDE8"}, "checksum"},
+		{"Should reject malformed address", map[string]interface{}{"targetStateContractAddress": "0x753a"}, "targetStateContractAddress"},
+		{"Should reject issuer state source", map[string]interface{}{"issuerStateSource": "grpc"}, "issuerStateSource"},
+	}
+
+	for _, c := range invalid {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := NewConnectorFromJSON(getConfigJson(t, c.overrides))
+			if err == nil {
+				t.Fatalf("Expected error")
+			}
+
+			if GetErrorCode(err.Error()) != ErrorCodeInvalidConfig {
+				t.Errorf("Expected %s, got %q", ErrorCodeInvalidConfig, err.Error())
+			}
+
+			if !strings.Contains(err.Error(), c.problem) {
+				t.Errorf("Expected %q in %q", c.problem, err.Error())
+			}
+		})
+	}
+
+	t.Run("Should accept lowercase address", func(t *testing.T) {
+		overrides := map[string]interface{}{"coreStateContractAddress": "0x753a8678c85d5fb70a97cfae37c84ce2fd67ede8"}
+
+		if _, err := NewConnectorFromJSON(getConfigJson(t, overrides)); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Should report all problems at once", func(t *testing.T) {
+		overrides := map[string]interface{}{"pkHex": "", "coreEvmRpcApiUrl": ""}
+
+		_, err := NewConnectorFromJSON(getConfigJson(t, overrides))
+		if err == nil {
+			t.Fatalf("Expected error")
+		}
+
+		if !strings.Contains(err.Error(), "pkHex") || !strings.Contains(err.Error(), "coreEvmRpcApiUrl") {
+			t.Errorf("Expected both problems in %q", err.Error())
+		}
+	})
+}
@@ -1,23 +1,28 @@
 package zkp_iden3_exposer
 
 import (
-	"encoding/hex"
+	"context"
 	"encoding/json"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/iden3/go-circuits/v2"
-	core "github.com/iden3/go-iden3-core/v2"
-	"github.com/iden3/go-iden3-core/v2/w3c"
+	rapidsnarkTypes "github.com/iden3/go-rapidsnark/types"
 	"github.com/iden3/go-schema-processor/v2/verifiable"
 	"github.com/pkg/errors"
 	"github.com/rarimo/go-jwz"
-	"github.com/rarimo/zkp-iden3-exposer/client"
+	"github.com/rarimo/zkp-iden3-exposer/client/rest"
+	"github.com/rarimo/zkp-iden3-exposer/coreapi"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"github.com/rarimo/zkp-iden3-exposer/evm"
+	"github.com/rarimo/zkp-iden3-exposer/progress"
 	"github.com/rarimo/zkp-iden3-exposer/wallet"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/didtype"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/helpers"
 	"github.com/rarimo/zkp-iden3-exposer/zkp/instances"
 	"github.com/rarimo/zkp-iden3-exposer/zkp/overrides"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/prover"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/registry"
 	zkpTypes "github.com/rarimo/zkp-iden3-exposer/zkp/types"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/keepalive"
 	"net/http"
-	"time"
 )
 
 type Connector struct {
@@ -41,6 +46,22 @@ type Connector struct {
 	MinGasPrice int    `json:"minGasPrice"`
 	GasLimit    int    `json:"gasLimit"`
 	IsTLS       bool   `json:"tls"`
+
+	// IssuerStateSource is either "rest" (default) to read issuer states from the core API
+	// or "evm" to read them from StateV2 at CoreEvmRpcApiUrl
+	IssuerStateSource string `json:"issuerStateSource"`
+
+	prover   prover.Prover
+	circuits *registry.Registry
+
+	operationWait     coreapi.WaitOptions
+	operationListener OperationWaitListener
+	eventListener     EventListener
+	gistReference     gistReference
+
+	session    *session
+	ctx        context.Context
+	httpClient *http.Client
 }
 
 func NewConnector(
@@ -84,10 +105,20 @@ func NewConnector(
 
 func getIdentityInstance(identityConfig zkpTypes.IdentityConfig) (*instances.Identity, error) {
 	if identityConfig.PkHex == "" || &identityConfig.PkHex == nil {
-		return nil, errors.New("Private key is required")
+		return nil, errcode.New(errcode.KeyError, "Private key is required")
+	}
+
+	identity, err := instances.NewIdentity(toInstanceIdentityConfig(identityConfig), &identityConfig.PkHex)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating identity")
 	}
 
-	identity, err := instances.NewIdentity(instances.IdentityConfig{
+	return identity, nil
+}
+
+func toInstanceIdentityConfig(identityConfig zkpTypes.IdentityConfig) instances.IdentityConfig {
+	return instances.IdentityConfig{
 		IdType:        identityConfig.IdType,
 		SchemaHashHex: identityConfig.SchemaHashHex,
 		ChainInfo: zkpTypes.ChainZkpInfo{
@@ -99,19 +130,22 @@ func getIdentityInstance(identityConfig zkpTypes.IdentityConfig) (*instances.Ide
 			CoreEvmRpcApiUrl:         identityConfig.CoreEvmRpcApiUrl,
 			CoreStateContractAddress: identityConfig.CoreStateContractAddress,
 		},
-	}, &identityConfig.PkHex)
+	}
+}
 
+func (c *Connector) getIdentityConfig() (*zkpTypes.IdentityConfig, error) {
+	idType, err := didtype.Parse(c.IdType)
 	if err != nil {
-		return nil, errors.Wrap(err, "Error creating identity")
+		return nil, errors.Wrap(err, "Invalid id type")
 	}
 
-	return identity, nil
+	return c.getIdentityConfigFor(idType), nil
 }
 
-func (c *Connector) getIdentityConfig() *zkpTypes.IdentityConfig {
+func (c *Connector) getIdentityConfigFor(idType [2]byte) *zkpTypes.IdentityConfig {
 	return &zkpTypes.IdentityConfig{
 		PkHex:                      c.PkHex,
-		IdType:                     [2]byte(c.IdType),
+		IdType:                     idType,
 		SchemaHashHex:              c.SchemaHashHex,
 		TargetChainId:              c.TargetChainId,
 		TargetRpcUrl:               c.TargetRpcUrl,
@@ -122,15 +156,42 @@ func (c *Connector) getIdentityConfig() *zkpTypes.IdentityConfig {
 	}
 }
 
-func (c *Connector) GetOfferJson(issuerApi string, identityDidString string, claimType string) ([]byte, error) {
+func (c *Connector) GetOfferJson(issuerApi string, identityDidString string, claimType string) (_ []byte, err error) {
+	defer withCode(&err)
+
+	c, done := c.startFlow(FlowGetOffer)
+	defer func() { done(err) }()
+
 	offer := zkpTypes.ClaimOffer{}
 
-	response, err := http.Get(issuerApi + "/v1/credentials/" + identityDidString + "/" + claimType)
+	request, err := http.NewRequestWithContext(
+		c.context(),
+		http.MethodGet,
+		issuerApi+"/v1/credentials/"+identityDidString+"/"+claimType,
+		nil,
+	)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating offer request")
+	}
+
+	trackOffer := progress.Track(c.context(), progress.StepIssuerOffer)
+	response, err := c.getHttpClient().Do(request)
+	trackOffer(err)
 
 	if err != nil {
 		return nil, errors.Wrap(err, "Error getting offer")
 	}
 
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, errcode.New(
+			errcode.FromHttpStatus(response.StatusCode, errcode.IssuerRejected),
+			"Issuer responded with status "+response.Status,
+		)
+	}
+
 	if err := json.NewDecoder(response.Body).Decode(&offer); err != nil {
 		return nil, errors.Wrap(err, "Error decoding offer")
 	}
@@ -144,8 +205,10 @@ func (c *Connector) GetOfferJson(issuerApi string, identityDidString string, cla
 	return offerJson, nil
 }
 
-func (c *Connector) GetDidString() (string, error) {
-	identity, err := getIdentityInstance(*c.getIdentityConfig())
+func (c *Connector) GetDidString() (_ string, err error) {
+	defer withCode(&err)
+
+	identity, err := c.getIdentity()
 
 	if err != nil {
 		return "", errors.Wrap(err, "Error getting identity")
@@ -154,8 +217,10 @@ func (c *Connector) GetDidString() (string, error) {
 	return identity.DID.String(), nil
 }
 
-func (c *Connector) GetIdBigIntString() (string, error) {
-	identity, err := getIdentityInstance(*c.getIdentityConfig())
+func (c *Connector) GetIdBigIntString() (_ string, err error) {
+	defer withCode(&err)
+
+	identity, err := c.getIdentity()
 	if err != nil {
 		return "", errors.Wrap(err, "Error getting identity")
 	}
@@ -168,15 +233,20 @@ func (c *Connector) GetIdBigIntString() (string, error) {
 	return id.BigInt().String(), nil
 }
 
-func (c *Connector) GetAuthV2Inputs(offerJson []byte) ([]byte, error) {
-	identity, err := getIdentityInstance(*c.getIdentityConfig())
+func (c *Connector) GetAuthV2Inputs(offerJson []byte) (_ []byte, err error) {
+	defer withCode(&err)
+
+	c, done := c.startFlow(FlowGetAuthV2Inputs)
+	defer func() { done(err) }()
+
+	identity, err := c.getIdentity()
 	if err != nil {
 		return nil, errors.Wrap(err, "Error getting identity")
 	}
 
 	offer := zkpTypes.ClaimOffer{}
 	if err := json.Unmarshal(offerJson, &offer); err != nil {
-		return nil, errors.Wrap(err, "Error unmarshalling offer")
+		return nil, errcode.Wrap(err, errcode.InvalidInput, "Error unmarshalling offer")
 	}
 
 	claimDetailsJson, err := instances.GetClaimDetailsJson(offer)
@@ -185,7 +255,7 @@ func (c *Connector) GetAuthV2Inputs(offerJson []byte) ([]byte, error) {
 	}
 
 	preparer := jwz.ProofInputsPreparerHandlerFunc(func(hash []byte, circuitID circuits.CircuitID) ([]byte, error) {
-		return identity.PrepareAuthV2Inputs(hash, circuitID)
+		return identity.PrepareAuthV2Inputs(c.context(), hash, circuitID)
 	})
 
 	token, err := jwz.NewWithPayload(
@@ -203,7 +273,7 @@ func (c *Connector) GetAuthV2Inputs(offerJson []byte) ([]byte, error) {
 		return nil, errors.Wrap(err, "Error getting message hash")
 	}
 
-	authV2Inputs, err := identity.PrepareAuthV2Inputs(messageHash, circuits.AuthV2CircuitID)
+	authV2Inputs, err := identity.PrepareAuthV2Inputs(c.context(), messageHash, circuits.AuthV2CircuitID)
 
 	if err != nil {
 		return nil, errors.Wrap(err, "Error getting AuthV2Inputs")
@@ -215,25 +285,32 @@ func (c *Connector) GetAuthV2Inputs(offerJson []byte) ([]byte, error) {
 func (c *Connector) GetVC(
 	offerJson []byte,
 	proofRaw []byte,
-) ([]byte, error) {
-	identity, err := getIdentityInstance(*c.getIdentityConfig())
+) (_ []byte, err error) {
+	defer withCode(&err)
+
+	c, done := c.startFlow(FlowGetVC)
+	defer func() { done(err) }()
+
+	identity, err := c.getIdentity()
 	if err != nil {
 		return nil, errors.Wrap(err, "Error getting identity")
 	}
 
 	offer := zkpTypes.ClaimOffer{}
 	if err := json.Unmarshal(offerJson, &offer); err != nil {
-		return nil, errors.Wrap(err, "Error unmarshalling offer")
+		return nil, errcode.Wrap(err, errcode.InvalidInput, "Error unmarshalling offer")
 	}
 
 	claimDetailsJson, err := instances.GetClaimDetailsJson(offer)
 
-	jwzToken, err := instances.GetJWZToken(*identity, claimDetailsJson, proofRaw)
+	trackToken := progress.Track(c.context(), progress.StepJWZToken)
+	jwzToken, err := instances.GetJWZToken(c.context(), *identity, claimDetailsJson, proofRaw)
+	trackToken(err)
 	if err != nil {
 		return nil, errors.Wrap(err, "Error getting JWZ token")
 	}
 
-	vc, err := instances.LoadVC(offer.Body.Url, *jwzToken)
+	vc, err := instances.LoadVC(c.context(), c.getHttpClient(), offer.Body.Url, *jwzToken)
 	if err != nil {
 		return nil, errors.Wrap(err, "Error loading VC")
 	}
@@ -246,6 +323,31 @@ func (c *Connector) GetVC(
 	return vcJson, nil
 }
 
+// FetchVC generates the AuthV2 proof with the configured prover and loads the VC in one go
+func (c *Connector) FetchVC(offerJson []byte) (_ []byte, err error) {
+	defer withCode(&err)
+
+	c, done := c.startFlow(FlowFetchVC)
+	defer func() { done(err) }()
+
+	authV2Inputs, err := c.GetAuthV2Inputs(offerJson)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting AuthV2 inputs")
+	}
+
+	proofRaw, err := c.Prove(string(circuits.AuthV2CircuitID), authV2Inputs)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error proving AuthV2 inputs")
+	}
+
+	vcJson, err := c.GetVC(offerJson, proofRaw)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting VC")
+	}
+
+	return vcJson, nil
+}
+
 func (c *Connector) GetAtomicQueryMTVV2OnChainInputs(
 	jsonVC []byte,
 
@@ -255,8 +357,13 @@ func (c *Connector) GetAtomicQueryMTVV2OnChainInputs(
 	subjectFieldName string,
 	subjectFieldValue string,
 	operator int,
-) ([]byte, error) {
-	identity, err := getIdentityInstance(*c.getIdentityConfig())
+) (_ []byte, err error) {
+	defer withCode(&err)
+
+	c, done := c.startFlow(FlowGetQueryInputs)
+	defer func() { done(err) }()
+
+	identity, err := c.getIdentity()
 	if err != nil {
 		return nil, errors.Wrap(err, "Error getting identity")
 	}
@@ -273,149 +380,209 @@ func (c *Connector) GetAtomicQueryMTVV2OnChainInputs(
 
 	vc := overrides.W3CCredential{}
 	if err := json.Unmarshal(jsonVC, &vc); err != nil {
-		return nil, errors.Wrap(err, "Error unmarshalling vc")
+		return nil, errcode.Wrap(err, errcode.InvalidInput, "Error unmarshalling vc")
 	}
 
 	vc.W3CCredential.Proof = verifiable.CredentialProofs(vc.Proof)
 
-	type StateInfo struct {
-		Index                    string `json:"index"`
-		Hash                     string `json:"hash"`
-		CreatedAtTimestamp       string `json:"createdAtTimestamp"`
-		CreatedAtBlock           string `json:"createdAtBlock"`
-		LastUpdateOperationIndex string `json:"lastUpdateOperationIndex"`
+	issuerId, err := getIssuerId(vc.Issuer)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting issuer ID")
 	}
 
-	type StateInfoResponse struct {
-		State StateInfo `json:"state"`
+	issuerStateProvider, err := c.getIssuerStateProvider()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting issuer state provider")
 	}
 
-	issuerDID, _ := w3c.ParseDID(vc.Issuer)
+	issuerState, err := issuerStateProvider.GetIssuerState(c.context(), issuerId)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting issuer state")
+	}
 
-	issuerID, _ := core.IDFromDID(*issuerDID)
+	atomicQueryMTPV2OnChainProof := instances.NewAtomicQueryMTPV2OnChainProof(
+		*identity,
 
-	issuerHexId := "0x" + hex.EncodeToString(issuerID.BigInt().Bytes())
+		issuerState.StateHash,
+		issuerState.GISTHash,
+		vc,
+		proofRequest,
+	)
 
-	response, _ := http.Get(identity.Config.ChainInfo.CoreApiUrl + "/rarimo/rarimo-core/identity/state/" + issuerHexId)
+	atomicQueryMTPV2OnChainProof.GISTReference, err = c.resolveGISTReference(c.context())
+	if err != nil {
+		return nil, errors.Wrap(err, "Error resolving GIST reference")
+	}
 
-	stateInfoResponse := StateInfoResponse{}
-	if err := json.NewDecoder(response.Body).Decode(&stateInfoResponse); err != nil {
-		return nil, errors.Wrap(err, "Error decoding response")
+	inputs, err := atomicQueryMTPV2OnChainProof.GetInputs(c.context())
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting inputs")
 	}
 
-	type OperationStatus string
+	return inputs, nil
+}
+
+// GenerateAtomicQueryMTVV2OnChainProof builds the query inputs and proves them with the configured prover
+func (c *Connector) GenerateAtomicQueryMTVV2OnChainProof(
+	jsonVC []byte,
+
+	circuitId string,
+	challenge string,
 
-	const (
-		Signed      OperationStatus = "SIGNED"
-		Initialized OperationStatus = "INITIALIZED"
-		Approved    OperationStatus = "APPROVED"
-		NotApproved OperationStatus = "NOT_APPROVED"
+	subjectFieldName string,
+	subjectFieldValue string,
+	operator int,
+) (_ []byte, err error) {
+	defer withCode(&err)
+
+	c, done := c.startFlow(FlowGenerateQueryProof)
+	defer func() { done(err) }()
+
+	inputs, err := c.GetAtomicQueryMTVV2OnChainInputs(
+		jsonVC,
+		circuitId,
+		challenge,
+		subjectFieldName,
+		subjectFieldValue,
+		operator,
 	)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting inputs")
+	}
 
-	type Operation struct {
-		Index         string `json:"index"`
-		OperationType string `json:"operationType"`
-		Details       struct {
-			AtType        string `json:"@type"`
-			Contract      string `json:"contract"`
-			Chain         string `json:"chain"`
-			GISTHash      string `json:"GISTHash"`
-			StateRootHash string `json:"stateRootHash"`
-			Timestamp     string `json:"timestamp"`
-		} `json:"details"`
-		Status    OperationStatus `json:"status"`
-		Creator   string          `json:"creator"`
-		Timestamp string          `json:"timestamp"`
+	proofRaw, err := c.Prove(circuitId, inputs)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error proving inputs")
 	}
 
-	type OperationResponse struct {
-		Operation Operation `json:"operation"`
+	return proofRaw, nil
+}
+
+// EvmGetAddress returns the Ethereum address of the connector key
+func (c *Connector) EvmGetAddress() (_ string, err error) {
+	defer withCode(&err)
+
+	address, err := evm.GetAddress(c.PkHex)
+	if err != nil {
+		return "", errors.Wrap(err, "Error deriving address")
 	}
 
-	response, _ = http.Get(identity.Config.ChainInfo.CoreApiUrl + "/rarimo/rarimo-core/rarimocore/operation/" + stateInfoResponse.State.LastUpdateOperationIndex)
+	return address.Hex(), nil
+}
+
+// SubmitZKPResponse sends the query proof to the verifier contract on the target chain and returns the receipt
+func (c *Connector) SubmitZKPResponse(verifierAddress string, requestId int64, proofJson []byte) (_ []byte, err error) {
+	defer withCode(&err)
 
-	operationResponse := OperationResponse{}
-	if err := json.NewDecoder(response.Body).Decode(&operationResponse); err != nil {
-		return nil, errors.Wrap(err, "Error decoding operation response")
+	// the tx is signed and paid for, so the mistyped address must not be padded into another one
+	if err := validateAddress("verifierAddress", verifierAddress); err != nil {
+		return nil, errcode.Wrap(err, errcode.InvalidInput, "Invalid verifier address")
 	}
 
-	atomicQueryMTPV2OnChainProof := instances.NewAtomicQueryMTPV2OnChainProof(
-		*identity,
+	c, done := c.startFlow(FlowSubmitZKPResponse)
+	defer func() { done(err) }()
 
-		stateInfoResponse.State.Hash,
-		operationResponse.Operation.Details.GISTHash,
-		vc,
-		proofRequest,
-	)
+	proof := rapidsnarkTypes.ZKProof{}
+	if err := json.Unmarshal(proofJson, &proof); err != nil {
+		return nil, errcode.Wrap(err, errcode.InvalidInput, "Error unmarshalling proof")
+	}
 
-	inputs, err := atomicQueryMTPV2OnChainProof.GetInputs()
+	response, err := helpers.NewZKPResponse(requestId, proof)
 	if err != nil {
-		return nil, errors.Wrap(err, "Error getting inputs")
+		return nil, errors.Wrap(err, "Error converting proof")
 	}
 
-	return inputs, nil
-}
+	evmClient, err := c.getEvmClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting evm client")
+	}
 
-func (c *Connector) WalletGetAddress() (string, error) {
-	w, err := wallet.NewWallet(c.PkHex, c.AddrPrefix)
+	trackSubmit := progress.Track(c.context(), progress.StepSubmitTransaction)
+	receipt, err := evmClient.SubmitZKPResponse(c.context(), common.HexToAddress(verifierAddress), *response)
+	trackSubmit(err)
 	if err != nil {
-		return "", errors.Wrap(err, "Error creating wallet")
+		return nil, errors.Wrap(err, "Error submitting ZKP response")
 	}
 
-	return w.Address, nil
+	receiptJson, err := json.Marshal(receipt)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error marshalling receipt")
+	}
+
+	return receiptJson, nil
 }
 
-func (c *Connector) WalletSend(fromAddr, toAddr string, amount int64) ([]byte, error) {
-	w, err := wallet.NewWallet(c.PkHex, c.AddrPrefix)
+// GetZKPResponseCalldata encodes the proof as ZKPVerifier.submitZKPResponse calldata for the given request
+func (c *Connector) GetZKPResponseCalldata(requestId int64, proofJson []byte) (_ []byte, err error) {
+	defer withCode(&err)
+
+	proof := rapidsnarkTypes.ZKProof{}
+	if err := json.Unmarshal(proofJson, &proof); err != nil {
+		return nil, errcode.Wrap(err, errcode.InvalidInput, "Error unmarshalling proof")
+	}
+
+	response, err := helpers.NewZKPResponse(requestId, proof)
 	if err != nil {
-		return nil, errors.Wrap(err, "Error creating wallet")
+		return nil, errors.Wrap(err, "Error converting proof")
 	}
 
-	chainConfig := client.ChainConfig{
+	calldata, err := response.Pack()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error packing calldata")
+	}
+
+	return calldata, nil
+}
+
+func (c *Connector) getChainConfig() wallet.ChainConfig {
+	return wallet.ChainConfig{
 		ChainId:     c.ChainId,
 		Denom:       c.Denom,
 		MinGasPrice: uint64(c.MinGasPrice),
 		GasLimit:    uint64(c.GasLimit),
 	}
+}
 
-	grpcClient, err := grpc.Dial(
-		c.RpcApi,
-		grpc.WithInsecure(),
-		grpc.WithKeepaliveParams(keepalive.ClientParameters{
-			Time:    10 * time.Second, // wait time before ping if no activity
-			Timeout: 20 * time.Second, // ping timeout
-		}),
-	)
+func (c *Connector) WalletGetAddress() (_ string, err error) {
+	defer withCode(&err)
+
+	w, err := wallet.NewWallet(c.PkHex, c.AddrPrefix)
 	if err != nil {
-		return nil, errors.Wrap(err, "Error dialing grpc")
+		return "", errors.Wrap(err, "Error creating wallet")
 	}
 
-	rarimoClient, err := client.NewClient(
-		grpcClient,
-		chainConfig,
-		*w,
-	)
+	return w.Address, nil
+}
+
+// WalletGetBalance returns {"denom":...,"amount":...} with the Denom balance read through the REST API
+// of the core at CoreApiUrl, the empty address stands for the connector wallet
+func (c *Connector) WalletGetBalance(address string) (_ []byte, err error) {
+	defer withCode(&err)
+
+	w, err := wallet.NewWallet(c.PkHex, c.AddrPrefix)
 	if err != nil {
-		return nil, errors.Wrap(err, "Error creating client")
+		return nil, errors.Wrap(err, "Error creating wallet")
 	}
 
-	txResp, err := rarimoClient.Send(
-		fromAddr,
-		toAddr,
-		amount,
-		c.Denom,
-	)
+	if address == "" {
+		address = w.Address
+	}
+
+	amount, err := rest.NewClient(c.CoreApiUrl, c.getHttpClient(), c.getChainConfig(), *w).GetBalance(c.context(), address, c.Denom)
 	if err != nil {
-		return nil, errors.Wrap(err, "Error sending tx")
+		return nil, errors.Wrap(err, "Error getting balance")
 	}
 
-	return txResp, nil
+	balanceJson, err := json.Marshal(map[string]string{"denom": c.Denom, "amount": amount})
+	if err != nil {
+		return nil, errors.Wrap(err, "Error marshalling balance")
+	}
+
+	return balanceJson, nil
 }
 
 //func (c *Connector) RemoveCredentials() {}
 
 //func (c *Connector) GetCredentials() {}
 
-//func (c *Connector) CheckStateContractSync() {}
-
 //func (c *Connector) CheckCredentialExistence() {}
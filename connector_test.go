@@ -1,6 +1,7 @@
 package zkp_iden3_exposer
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/iden3/go-circuits/v2"
 	"github.com/iden3/go-jwz/v2"
@@ -61,7 +62,7 @@ func getGroth16AuthV2ZKProof(identity instances.Identity, offer types.ClaimOffer
 	}
 
 	preparer := jwz.ProofInputsPreparerHandlerFunc(func(hash []byte, circuitID circuits.CircuitID) ([]byte, error) {
-		return identity.PrepareAuthV2Inputs(hash, circuitID)
+		return identity.PrepareAuthV2Inputs(context.Background(), hash, circuitID)
 	})
 
 	token, err := jwz.NewWithPayload(
@@ -0,0 +1,192 @@
+package coreapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	identityPrefix   = "/rarimo/rarimo-core/identity"
+	rarimocorePrefix = "/rarimo/rarimo-core/rarimocore"
+)
+
+// Error Decoded grpc-gateway error returned by the core REST API
+type Error struct {
+	StatusCode int    `json:"-"`
+	Code       int    `json:"code"`
+	Message    string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("core api responded with status %d: %s", e.StatusCode, e.Message)
+}
+
+// ErrorCode classifies the response status, the rejected requests are the malformed ones
+func (e *Error) ErrorCode() errcode.Code {
+	return errcode.FromHttpStatus(e.StatusCode, errcode.InvalidInput)
+}
+
+// IsNotFound reports whether the requested entity does not exist on the core
+func IsNotFound(err error) bool {
+	apiErr := &Error{}
+
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+type Client struct {
+	BaseUrl    string
+	HttpClient *http.Client
+}
+
+func NewClient(baseUrl string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &Client{
+		BaseUrl:    strings.TrimSuffix(baseUrl, "/"),
+		HttpClient: httpClient,
+	}
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, result interface{}) error {
+	endpoint := c.BaseUrl + path
+	if len(query) != 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+
+	response, err := c.HttpClient.Do(request)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get %s", path)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		apiErr := Error{StatusCode: response.StatusCode}
+
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+		if err := json.Unmarshal(body, &apiErr); err != nil || apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(body))
+		}
+
+		return &apiErr
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(result); err != nil {
+		return errors.Wrapf(err, "failed to decode %s response", path)
+	}
+
+	return nil
+}
+
+func pageQuery(page PageRequest) url.Values {
+	query := url.Values{}
+
+	if page.Key != "" {
+		query.Set("pagination.key", page.Key)
+	}
+
+	if page.Limit != 0 {
+		query.Set("pagination.limit", strconv.FormatUint(page.Limit, 10))
+	}
+
+	return query
+}
+
+// GetState Issuer state by the hex encoded issuer id
+func (c *Client) GetState(ctx context.Context, idHex string) (*StateInfo, error) {
+	response := stateResponse{}
+
+	if err := c.get(ctx, identityPrefix+"/state/"+url.PathEscape(idHex), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response.State, nil
+}
+
+func (c *Client) GetStates(ctx context.Context, page PageRequest) ([]StateInfo, *PageResponse, error) {
+	response := statesResponse{}
+
+	if err := c.get(ctx, identityPrefix+"/state", pageQuery(page), &response); err != nil {
+		return nil, nil, err
+	}
+
+	return response.State, &response.Pagination, nil
+}
+
+// GetStateMerkleProof Path of the issuer state in the identity treap
+func (c *Client) GetStateMerkleProof(ctx context.Context, idHex string) ([]string, error) {
+	response := merkleProofResponse{}
+
+	if err := c.get(ctx, identityPrefix+"/state/"+url.PathEscape(idHex)+"/proof", nil, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Proof, nil
+}
+
+// GetIdentityParams Identity module params with the latest GIST root
+func (c *Client) GetIdentityParams(ctx context.Context) (*IdentityParams, error) {
+	response := identityParamsResponse{}
+
+	if err := c.get(ctx, identityPrefix+"/params", nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response.Params, nil
+}
+
+func (c *Client) GetOperation(ctx context.Context, index string) (*Operation, error) {
+	response := operationResponse{}
+
+	if err := c.get(ctx, rarimocorePrefix+"/operation/"+url.PathEscape(index), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response.Operation, nil
+}
+
+func (c *Client) GetOperations(ctx context.Context, page PageRequest) ([]Operation, *PageResponse, error) {
+	response := operationsResponse{}
+
+	if err := c.get(ctx, rarimocorePrefix+"/operation", pageQuery(page), &response); err != nil {
+		return nil, nil, err
+	}
+
+	return response.Operation, &response.Pagination, nil
+}
+
+// GetOperationProof Merkle path and signature of the signed operation
+func (c *Client) GetOperationProof(ctx context.Context, index string) (*OperationProof, error) {
+	response := OperationProof{}
+
+	if err := c.get(ctx, rarimocorePrefix+"/operation/"+url.PathEscape(index)+"/proof", nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// GetConfirmation Confirmation by the hex encoded Merkle root
+func (c *Client) GetConfirmation(ctx context.Context, root string) (*Confirmation, error) {
+	response := confirmationResponse{}
+
+	if err := c.get(ctx, rarimocorePrefix+"/confirmation/"+url.PathEscape(root), nil, &response); err != nil {
+		return nil, err
+	}
+
+	return &response.Confirmation, nil
+}
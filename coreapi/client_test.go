@@ -0,0 +1,120 @@
+package coreapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestServer() *httptest.Server {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/rarimo/rarimo-core/identity/state/0x01", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"state":{"index":"0x01","hash":"0x0a","createdAtTimestamp":"1700000000","createdAtBlock":"42","lastUpdateOperationIndex":"0xop"}}`))
+	})
+	mux.HandleFunc("/rarimo/rarimo-core/identity/state", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("pagination.key") == "" {
+			_, _ = w.Write([]byte(`{"state":[{"index":"0x01"}],"pagination":{"next_key":"Ag==","total":"2"}}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"state":[{"index":"0x02"}],"pagination":{"next_key":null,"total":"2"}}`))
+	})
+	mux.HandleFunc("/rarimo/rarimo-core/rarimocore/operation/0xop", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"operation":{"index":"0xop","operationType":"IDENTITY_AGGREGATED_TRANSFER","details":{"@type":"/rarimo.rarimocore.rarimocore.IdentityAggregatedTransfer","contract":"0xc","chain":"Sepolia","GISTHash":"0x0b","stateRootHash":"0x0c","timestamp":"1700000001"},"status":"SIGNED","creator":"rarimo1","timestamp":"1700000002"}}`))
+	})
+	mux.HandleFunc("/rarimo/rarimo-core/rarimocore/confirmation/0xroot", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"confirmation":{"root":"0xroot","indexes":["0xop"],"signatureECDSA":"0xsig","creator":"rarimo1"}}`))
+	})
+	mux.HandleFunc("/rarimo/rarimo-core/identity/params", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"params":{"GISTHash":"0x0b","GISTUpdatedTimestamp":"1700000003","statesWaitingForSign":[]}}`))
+	})
+	mux.HandleFunc("/rarimo/rarimo-core/rarimocore/operation/0xmissing", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"code":5,"message":"not found","details":[]}`))
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestClient(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", server.Client())
+	ctx := context.Background()
+
+	t.Run("Should get state", func(t *testing.T) {
+		state, err := client.GetState(ctx, "0x01")
+		if err != nil {
+			t.Fatalf("Error getting state: %v", err)
+		}
+
+		if state.Hash != "0x0a" || state.CreatedAtBlock != 42 || state.LastUpdateOperationIndex != "0xop" {
+			t.Errorf("Unexpected state: %+v", state)
+		}
+	})
+	t.Run("Should page through states", func(t *testing.T) {
+		states, page, err := client.GetStates(ctx, PageRequest{Limit: 1})
+		if err != nil {
+			t.Fatalf("Error getting states: %v", err)
+		}
+
+		if len(states) != 1 || page.NextKey != "Ag==" || page.Total != 2 {
+			t.Fatalf("Unexpected first page: %+v %+v", states, page)
+		}
+
+		states, page, err = client.GetStates(ctx, PageRequest{Key: page.NextKey, Limit: 1})
+		if err != nil {
+			t.Fatalf("Error getting states: %v", err)
+		}
+
+		if len(states) != 1 || states[0].Index != "0x02" || page.NextKey != "" {
+			t.Errorf("Unexpected second page: %+v %+v", states, page)
+		}
+	})
+	t.Run("Should get operation", func(t *testing.T) {
+		operation, err := client.GetOperation(ctx, "0xop")
+		if err != nil {
+			t.Fatalf("Error getting operation: %v", err)
+		}
+
+		if operation.Status != OperationStatusSigned || operation.Details.GISTHash != "0x0b" {
+			t.Errorf("Unexpected operation: %+v", operation)
+		}
+	})
+	t.Run("Should get confirmation and params", func(t *testing.T) {
+		confirmation, err := client.GetConfirmation(ctx, "0xroot")
+		if err != nil {
+			t.Fatalf("Error getting confirmation: %v", err)
+		}
+
+		if len(confirmation.Indexes) != 1 || confirmation.SignatureECDSA != "0xsig" {
+			t.Errorf("Unexpected confirmation: %+v", confirmation)
+		}
+
+		params, err := client.GetIdentityParams(ctx)
+		if err != nil {
+			t.Fatalf("Error getting params: %v", err)
+		}
+
+		if params.GISTHash != "0x0b" || params.GISTUpdatedTimestamp != 1700000003 {
+			t.Errorf("Unexpected params: %+v", params)
+		}
+	})
+	t.Run("Should decode errors", func(t *testing.T) {
+		_, err := client.GetOperation(ctx, "0xmissing")
+		if !IsNotFound(err) {
+			t.Fatalf("Expected not found error, got %v", err)
+		}
+
+		if err.(*Error).Message != "not found" {
+			t.Errorf("Unexpected message: %s", err.(*Error).Message)
+		}
+
+		if _, err := client.GetState(ctx, "0x03"); !IsNotFound(err) {
+			t.Errorf("Expected not found error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package coreapi
+
+type OperationStatus string
+
+const (
+	OperationStatusSigned      OperationStatus = "SIGNED"
+	OperationStatusInitialized OperationStatus = "INITIALIZED"
+	OperationStatusApproved    OperationStatus = "APPROVED"
+	OperationStatusNotApproved OperationStatus = "NOT_APPROVED"
+)
+
+const OperationTypeIdentityAggregatedTransfer = "IDENTITY_AGGREGATED_TRANSFER"
+
+// StateInfo Issuer state as stored by the identity module
+type StateInfo struct {
+	Index                    string `json:"index"`
+	Hash                     string `json:"hash"`
+	CreatedAtTimestamp       uint64 `json:"createdAtTimestamp,string"`
+	CreatedAtBlock           uint64 `json:"createdAtBlock,string"`
+	LastUpdateOperationIndex string `json:"lastUpdateOperationIndex"`
+}
+
+// OperationDetails Body of the identity transfer operations, only the fields of the matching type are filled
+type OperationDetails struct {
+	AtType        string `json:"@type"`
+	Contract      string `json:"contract"`
+	Chain         string `json:"chain"`
+	GISTHash      string `json:"GISTHash"`
+	StateRootHash string `json:"stateRootHash"`
+	Timestamp     string `json:"timestamp"`
+}
+
+type Operation struct {
+	Index         string           `json:"index"`
+	OperationType string           `json:"operationType"`
+	Details       OperationDetails `json:"details"`
+	Status        OperationStatus  `json:"status"`
+	Creator       string           `json:"creator"`
+	Timestamp     uint64           `json:"timestamp,string"`
+}
+
+// Confirmation Threshold signature of the Merkle root built over the operation hashes
+type Confirmation struct {
+	Root           string   `json:"root"`
+	Indexes        []string `json:"indexes"`
+	SignatureECDSA string   `json:"signatureECDSA"`
+	Creator        string   `json:"creator"`
+}
+
+// IdentityParams Identity module params, carries the latest GIST root
+type IdentityParams struct {
+	IdentityContractAddress string   `json:"identityContractAddress"`
+	ChainName               string   `json:"chainName"`
+	GISTHash                string   `json:"GISTHash"`
+	GISTUpdatedTimestamp    uint64   `json:"GISTUpdatedTimestamp,string"`
+	TreapRootKey            string   `json:"treapRootKey"`
+	StatesWaitingForSign    []string `json:"statesWaitingForSign"`
+}
+
+// OperationProof Merkle path of the operation in the confirmation tree and the tree signature
+type OperationProof struct {
+	Path      []string `json:"path"`
+	Signature string   `json:"signature"`
+}
+
+// PageRequest Cosmos pagination, Key is the NextKey of the previous page
+type PageRequest struct {
+	Key   string
+	Limit uint64
+}
+
+type PageResponse struct {
+	NextKey string `json:"next_key"`
+	Total   uint64 `json:"total,string"`
+}
+
+type stateResponse struct {
+	State StateInfo `json:"state"`
+}
+
+type statesResponse struct {
+	State      []StateInfo  `json:"state"`
+	Pagination PageResponse `json:"pagination"`
+}
+
+type operationResponse struct {
+	Operation Operation `json:"operation"`
+}
+
+type operationsResponse struct {
+	Operation  []Operation  `json:"operation"`
+	Pagination PageResponse `json:"pagination"`
+}
+
+type confirmationResponse struct {
+	Confirmation Confirmation `json:"confirmation"`
+}
+
+type identityParamsResponse struct {
+	Params IdentityParams `json:"params"`
+}
+
+type merkleProofResponse struct {
+	Proof []string `json:"proof"`
+}
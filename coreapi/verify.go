@@ -0,0 +1,220 @@
+package coreapi
+
+import (
+	"bytes"
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"math/big"
+	"strings"
+)
+
+var (
+	ErrUnsupportedOperation = errcode.New(errcode.VerificationFailed, "unsupported operation type")
+	ErrOperationNotSigned   = errcode.New(errcode.StateNotSynced, "operation is not signed")
+	ErrContractMismatch     = errcode.New(errcode.VerificationFailed, "operation is bound to another contract")
+	ErrRootMismatch         = errcode.New(errcode.VerificationFailed, "operation is not included in the confirmation")
+	ErrSignerMismatch       = errcode.New(errcode.VerificationFailed, "operation is signed by unexpected signer")
+	ErrStateMismatch        = errcode.New(errcode.VerificationFailed, "issuer state is not included in the operation states root")
+)
+
+// TrustAnchor Parties the signed operation must be bound to, mirrors the LightweightStateV2 on the target chain
+type TrustAnchor struct {
+	Signer common.Address
+	// Contract is the source StateV2 the operation transfers the states of, not the LightweightStateV2 itself
+	Contract common.Address
+	// Chain is not checked when empty
+	Chain string
+}
+
+// OperationContentHash Merkle leaf of the operation, the same one LightweightStateV2 recomputes on transit
+func OperationContentHash(operation *Operation) ([]byte, error) {
+	if operation.OperationType != OperationTypeIdentityAggregatedTransfer {
+		return nil, errors.Wrap(ErrUnsupportedOperation, operation.OperationType)
+	}
+
+	details := operation.Details
+
+	contract, err := hexutil.Decode(details.Contract)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode contract")
+	}
+
+	gistHash, err := hexutil.Decode(details.GISTHash)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode GIST hash")
+	}
+
+	stateRootHash, err := hexutil.Decode(details.StateRootHash)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode state root hash")
+	}
+
+	timestamp, ok := new(big.Int).SetString(details.Timestamp, 10)
+	if !ok {
+		return nil, errors.Errorf("invalid timestamp %q", details.Timestamp)
+	}
+
+	return crypto.Keccak256(
+		common.LeftPadBytes(gistHash, 32),
+		common.LeftPadBytes(timestamp.Bytes(), 32),
+		common.LeftPadBytes(stateRootHash, 32),
+		contract,
+		[]byte(details.Chain),
+	), nil
+}
+
+// StateLeafHash Merkle leaf of the issuer state in the identities states tree, the same one
+// LightweightStateV2 recomputes in verifyStatesMerkleData
+func StateLeafHash(issuerId *big.Int, state *StateInfo) ([]byte, error) {
+	stateHash, err := hexutil.Decode(state.Hash)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode state hash")
+	}
+
+	return crypto.Keccak256(
+		common.LeftPadBytes(issuerId.Bytes(), 32),
+		common.LeftPadBytes(stateHash, 32),
+		common.LeftPadBytes(new(big.Int).SetUint64(state.CreatedAtTimestamp).Bytes(), 32),
+	), nil
+}
+
+// ProcessMerklePath Folds the path over the leaf with sorted pair hashing used by the core tree
+func ProcessMerklePath(leaf []byte, path []string) ([]byte, error) {
+	hash := leaf
+
+	for i, sibling := range path {
+		siblingBytes, err := hexutil.Decode(sibling)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode path element %d", i)
+		}
+
+		if bytes.Compare(hash, siblingBytes) < 0 {
+			hash = crypto.Keccak256(hash, siblingBytes)
+		} else {
+			hash = crypto.Keccak256(siblingBytes, hash)
+		}
+	}
+
+	return hash, nil
+}
+
+// RecoverSigner Address of the threshold key the Merkle root is signed with
+func RecoverSigner(root []byte, signature string) (common.Address, error) {
+	signatureBytes, err := hexutil.Decode(signature)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "failed to decode signature")
+	}
+
+	if len(signatureBytes) != crypto.SignatureLength {
+		return common.Address{}, errors.Errorf("invalid signature length %d", len(signatureBytes))
+	}
+
+	if signatureBytes[crypto.RecoveryIDOffset] >= 27 {
+		signatureBytes[crypto.RecoveryIDOffset] -= 27
+	}
+
+	publicKey, err := crypto.SigToPub(root, signatureBytes)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "failed to recover public key")
+	}
+
+	return crypto.PubkeyToAddress(*publicKey), nil
+}
+
+// VerifyOperation checks that the operation is included in its confirmation and that the confirmation root
+// is signed by the anchor signer, so the operation details can be trusted regardless of the API node
+func (c *Client) VerifyOperation(ctx context.Context, operation *Operation, anchor TrustAnchor) error {
+	if operation.Status != OperationStatusSigned {
+		return errors.Wrapf(ErrOperationNotSigned, "operation %s is %s", operation.Index, operation.Status)
+	}
+
+	if !strings.EqualFold(operation.Details.Contract, anchor.Contract.Hex()) {
+		return errors.Wrapf(ErrContractMismatch, "expected %s, got %s", anchor.Contract.Hex(), operation.Details.Contract)
+	}
+
+	if anchor.Chain != "" && operation.Details.Chain != anchor.Chain {
+		return errors.Wrapf(ErrContractMismatch, "expected chain %s, got %s", anchor.Chain, operation.Details.Chain)
+	}
+
+	leaf, err := OperationContentHash(operation)
+	if err != nil {
+		return errors.Wrap(err, "failed to hash operation")
+	}
+
+	proof, err := c.GetOperationProof(ctx, operation.Index)
+	if err != nil {
+		return errors.Wrap(err, "failed to get operation proof")
+	}
+
+	root, err := ProcessMerklePath(leaf, proof.Path)
+	if err != nil {
+		return errors.Wrap(err, "failed to process Merkle path")
+	}
+
+	confirmation, err := c.GetConfirmation(ctx, hexutil.Encode(root))
+	if err != nil {
+		if IsNotFound(err) {
+			return errors.Wrapf(ErrRootMismatch, "no confirmation for root %s", hexutil.Encode(root))
+		}
+
+		return errors.Wrap(err, "failed to get confirmation")
+	}
+
+	included := false
+	for _, index := range confirmation.Indexes {
+		if index == operation.Index {
+			included = true
+			break
+		}
+	}
+
+	if !included {
+		return errors.Wrapf(ErrRootMismatch, "operation %s is not listed in confirmation %s", operation.Index, confirmation.Root)
+	}
+
+	signer, err := RecoverSigner(root, confirmation.SignatureECDSA)
+	if err != nil {
+		return errors.Wrap(err, "failed to recover confirmation signer")
+	}
+
+	if signer != anchor.Signer {
+		return errors.Wrapf(ErrSignerMismatch, "expected %s, got %s", anchor.Signer.Hex(), signer.Hex())
+	}
+
+	return nil
+}
+
+// VerifyState checks that the issuer state is included in the states root of the operation, the operation
+// must be verified with VerifyOperation first so the root itself can be trusted
+func (c *Client) VerifyState(ctx context.Context, issuerId *big.Int, state *StateInfo, operation *Operation) error {
+	leaf, err := StateLeafHash(issuerId, state)
+	if err != nil {
+		return errcode.Wrap(err, errcode.VerificationFailed, "failed to hash issuer state")
+	}
+
+	path, err := c.GetStateMerkleProof(ctx, hexutil.Encode(issuerId.Bytes()))
+	if err != nil {
+		return errors.Wrap(err, "failed to get state Merkle proof")
+	}
+
+	root, err := ProcessMerklePath(leaf, path)
+	if err != nil {
+		return errcode.Wrap(err, errcode.VerificationFailed, "failed to process state Merkle path")
+	}
+
+	statesRoot, err := hexutil.Decode(operation.Details.StateRootHash)
+	if err != nil {
+		return errcode.Wrap(err, errcode.VerificationFailed, "failed to decode states root")
+	}
+
+	if !bytes.Equal(root, common.LeftPadBytes(statesRoot, 32)) {
+		return errors.Wrapf(ErrStateMismatch, "state %s computes root %s, operation has %s",
+			state.Hash, hexutil.Encode(root), operation.Details.StateRootHash)
+	}
+
+	return nil
+}
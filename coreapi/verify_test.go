@@ -0,0 +1,187 @@
+package coreapi
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVerifyOperation(t *testing.T) {
+	key, err := crypto.HexToECDSA("1cbd5d2d1801e964736881fc0584473f23ba82669599ac65957fb4f2caf43e17")
+	if err != nil {
+		t.Fatalf("Error parsing key: %v", err)
+	}
+
+	// the core binds the operation to the source StateV2, not to the target LightweightStateV2
+	anchor := TrustAnchor{
+		Signer:   crypto.PubkeyToAddress(key.PublicKey),
+		Contract: common.HexToAddress("0x753a8678c85d5fb70A97CFaE37c84CE2fD67EDE8"),
+		Chain:    "Sepolia",
+	}
+
+	operation := Operation{
+		Index:         "0xop",
+		OperationType: OperationTypeIdentityAggregatedTransfer,
+		Details: OperationDetails{
+			Contract:      anchor.Contract.Hex(),
+			Chain:         "Sepolia",
+			GISTHash:      "0x0b",
+			StateRootHash: "0x0c",
+			Timestamp:     "1700000001",
+		},
+		Status: OperationStatusSigned,
+	}
+
+	leaf, err := OperationContentHash(&operation)
+	if err != nil {
+		t.Fatalf("Error hashing operation: %v", err)
+	}
+
+	siblings := [][]byte{crypto.Keccak256([]byte("other")), crypto.Keccak256([]byte("another"))}
+
+	root, err := ProcessMerklePath(leaf, []string{hexutil.Encode(siblings[0]), hexutil.Encode(siblings[1])})
+	if err != nil {
+		t.Fatalf("Error processing path: %v", err)
+	}
+
+	signature, err := crypto.Sign(root, key)
+	if err != nil {
+		t.Fatalf("Error signing root: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rarimo/rarimo-core/rarimocore/operation/0xop/proof", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(OperationProof{
+			Path:      []string{hexutil.Encode(siblings[0]), hexutil.Encode(siblings[1])},
+			Signature: hexutil.Encode(signature),
+		})
+	})
+	mux.HandleFunc("/rarimo/rarimo-core/rarimocore/confirmation/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rarimo/rarimo-core/rarimocore/confirmation/"+hexutil.Encode(root) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"code":5,"message":"not found"}`))
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(confirmationResponse{Confirmation: Confirmation{
+			Root:           hexutil.Encode(root),
+			Indexes:        []string{"0xother", "0xop"},
+			SignatureECDSA: hexutil.Encode(signature),
+		}})
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewClient(server.URL, server.Client())
+
+	t.Run("Should verify signed operation", func(t *testing.T) {
+		if err := client.VerifyOperation(context.Background(), &operation, anchor); err != nil {
+			t.Errorf("Error verifying operation: %v", err)
+		}
+	})
+	t.Run("Should reject forged details", func(t *testing.T) {
+		forged := operation
+		forged.Details.GISTHash = "0x0d"
+
+		if err := client.VerifyOperation(context.Background(), &forged, anchor); !errors.Is(err, ErrRootMismatch) {
+			t.Errorf("Expected root mismatch, got %v", err)
+		}
+	})
+	t.Run("Should reject unexpected signer", func(t *testing.T) {
+		otherAnchor := anchor
+		otherAnchor.Signer = common.HexToAddress("0x01")
+
+		if err := client.VerifyOperation(context.Background(), &operation, otherAnchor); !errors.Is(err, ErrSignerMismatch) {
+			t.Errorf("Expected signer mismatch, got %v", err)
+		}
+	})
+	t.Run("Should reject operation for another contract", func(t *testing.T) {
+		otherAnchor := anchor
+		otherAnchor.Contract = common.HexToAddress("0x8a9F505bD8a22BF09b0c19F65C17426cd33f3912")
+
+		if err := client.VerifyOperation(context.Background(), &operation, otherAnchor); !errors.Is(err, ErrContractMismatch) {
+			t.Errorf("Expected contract mismatch, got %v", err)
+		}
+	})
+	t.Run("Should accept 27/28 recovery id", func(t *testing.T) {
+		legacy := append([]byte{}, signature...)
+		legacy[crypto.RecoveryIDOffset] += 27
+
+		signer, err := RecoverSigner(root, hexutil.Encode(legacy))
+		if err != nil || signer != anchor.Signer {
+			t.Errorf("Unexpected signer %s: %v", signer.Hex(), err)
+		}
+	})
+}
+
+func TestVerifyState(t *testing.T) {
+	issuerId := big.NewInt(42)
+	state := StateInfo{
+		Index:              "0x2a",
+		Hash:               "0x0a",
+		CreatedAtTimestamp: 1700000000,
+	}
+
+	leaf, err := StateLeafHash(issuerId, &state)
+	if err != nil {
+		t.Fatalf("Error hashing state: %v", err)
+	}
+
+	path := []string{hexutil.Encode(crypto.Keccak256([]byte("other"))), hexutil.Encode(crypto.Keccak256([]byte("another")))}
+
+	root, err := ProcessMerklePath(leaf, path)
+	if err != nil {
+		t.Fatalf("Error processing path: %v", err)
+	}
+
+	operation := Operation{
+		Index:   "0xop",
+		Details: OperationDetails{StateRootHash: hexutil.Encode(root)},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rarimo/rarimo-core/identity/state/0x2a/proof", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(merkleProofResponse{Proof: path})
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewClient(server.URL, server.Client())
+
+	t.Run("Should verify included state", func(t *testing.T) {
+		if err := client.VerifyState(context.Background(), issuerId, &state, &operation); err != nil {
+			t.Errorf("Error verifying state: %v", err)
+		}
+	})
+	t.Run("Should reject tampered state hash", func(t *testing.T) {
+		tampered := state
+		tampered.Hash = "0x0b"
+
+		err := client.VerifyState(context.Background(), issuerId, &tampered, &operation)
+		if !errors.Is(err, ErrStateMismatch) {
+			t.Errorf("Expected state mismatch, got %v", err)
+		}
+
+		if errcode.Of(err) != errcode.VerificationFailed {
+			t.Errorf("Expected %s, got %s", errcode.VerificationFailed, errcode.Of(err))
+		}
+	})
+	t.Run("Should reject tampered timestamp", func(t *testing.T) {
+		tampered := state
+		tampered.CreatedAtTimestamp++
+
+		if err := client.VerifyState(context.Background(), issuerId, &tampered, &operation); !errors.Is(err, ErrStateMismatch) {
+			t.Errorf("Expected state mismatch, got %v", err)
+		}
+	})
+}
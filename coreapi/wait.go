@@ -0,0 +1,169 @@
+package coreapi
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"time"
+)
+
+var (
+	ErrOperationNotApproved = errcode.New(errcode.VerificationFailed, "operation is not approved")
+	ErrWaitTimeout          = errcode.New(errcode.StateNotSynced, "timed out waiting for operation to be signed")
+)
+
+const (
+	DefaultWaitTimeout         = 5 * time.Minute
+	DefaultWaitInitialInterval = 2 * time.Second
+	DefaultWaitMaxInterval     = 30 * time.Second
+)
+
+// WaitOptions Polling schedule, zero values fall back to the defaults
+type WaitOptions struct {
+	Timeout         time.Duration
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+}
+
+func (o WaitOptions) withDefaults() WaitOptions {
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultWaitTimeout
+	}
+
+	if o.InitialInterval <= 0 {
+		o.InitialInterval = DefaultWaitInitialInterval
+	}
+
+	if o.MaxInterval <= 0 {
+		o.MaxInterval = DefaultWaitMaxInterval
+	}
+
+	if o.MaxInterval < o.InitialInterval {
+		o.MaxInterval = o.InitialInterval
+	}
+
+	return o
+}
+
+// WaitEvent Progress of the wait, reported after every poll
+type WaitEvent struct {
+	Attempt   int
+	State     *StateInfo
+	Operation *Operation
+	// NextPoll is zero once the wait is over
+	NextPoll time.Duration
+}
+
+// WaitIssuerStateSigned polls the issuer state until the operation of its latest update is signed,
+// the state is re-read on every attempt since the issuer may publish a newer one meanwhile.
+// The retryable failures of the polls are retried until the timeout
+func (c *Client) WaitIssuerStateSigned(
+	ctx context.Context,
+	idHex string,
+	options WaitOptions,
+	onProgress func(WaitEvent),
+) (*StateInfo, *Operation, error) {
+	options = options.withDefaults()
+
+	ctx, cancel := context.WithTimeout(ctx, options.Timeout)
+	defer cancel()
+
+	interval := options.InitialInterval
+
+	var (
+		state     *StateInfo
+		operation *Operation
+		pollErr   error
+	)
+
+	for attempt := 1; ; attempt++ {
+		state, operation, pollErr = c.pollIssuerState(ctx, idHex)
+		if pollErr != nil {
+			if ctx.Err() != nil || !errcode.Of(pollErr).Retryable() {
+				return nil, nil, waitError(ctx, pollErr)
+			}
+		} else {
+			event := WaitEvent{
+				Attempt:   attempt,
+				State:     state,
+				Operation: operation,
+			}
+
+			switch operation.Status {
+			case OperationStatusSigned:
+				if onProgress != nil {
+					onProgress(event)
+				}
+
+				return state, operation, nil
+			case OperationStatusNotApproved:
+				if onProgress != nil {
+					onProgress(event)
+				}
+
+				return state, operation, errors.Wrapf(ErrOperationNotApproved, "operation %s", operation.Index)
+			}
+
+			event.NextPoll = interval
+
+			if onProgress != nil {
+				onProgress(event)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return state, operation, waitError(ctx, pollErr)
+		case <-time.After(interval):
+		}
+
+		interval *= 2
+		if interval > options.MaxInterval {
+			interval = options.MaxInterval
+		}
+	}
+}
+
+func (c *Client) pollIssuerState(ctx context.Context, idHex string) (*StateInfo, *Operation, error) {
+	state, err := c.GetState(ctx, idHex)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get issuer state")
+	}
+
+	operation, err := c.GetOperation(ctx, state.LastUpdateOperationIndex)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get issuer state operation")
+	}
+
+	return state, operation, nil
+}
+
+// waitTimeoutError keeps both ErrWaitTimeout and the failure of the last poll reachable with errors.Is
+type waitTimeoutError struct {
+	cause error
+}
+
+func (e *waitTimeoutError) Error() string {
+	return ErrWaitTimeout.Error() + ": " + e.cause.Error()
+}
+
+func (e *waitTimeoutError) Unwrap() []error {
+	return []error{ErrWaitTimeout, e.cause}
+}
+
+// waitError returns ErrWaitTimeout with the cause once the wait is out of time, the cause as is otherwise
+func waitError(ctx context.Context, err error) error {
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		if err == nil {
+			return ctx.Err()
+		}
+
+		return err
+	}
+
+	if err == nil {
+		return ErrWaitTimeout
+	}
+
+	return &waitTimeoutError{cause: err}
+}
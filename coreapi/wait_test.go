@@ -0,0 +1,154 @@
+package coreapi
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newWaitServer(statuses ...OperationStatus) (*httptest.Server, *int32) {
+	return newFailingWaitServer(nil, statuses...)
+}
+
+// newFailingWaitServer responds to the first state requests with the failure statuses, 0 serves the state
+func newFailingWaitServer(failures []int, statuses ...OperationStatus) (*httptest.Server, *int32) {
+	polls := new(int32)
+	stateRequests := new(int32)
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/rarimo/rarimo-core/identity/state/0x01", func(w http.ResponseWriter, r *http.Request) {
+		request := int(atomic.AddInt32(stateRequests, 1)) - 1
+		if request < len(failures) && failures[request] != 0 {
+			w.WriteHeader(failures[request])
+			_, _ = w.Write([]byte(`{"code":14,"message":"unavailable"}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"state":{"index":"0x01","hash":"0x0a","lastUpdateOperationIndex":"0xop"}}`))
+	})
+	mux.HandleFunc("/rarimo/rarimo-core/rarimocore/operation/0xop", func(w http.ResponseWriter, r *http.Request) {
+		poll := int(atomic.AddInt32(polls, 1)) - 1
+		if poll >= len(statuses) {
+			poll = len(statuses) - 1
+		}
+
+		_, _ = w.Write([]byte(`{"operation":{"index":"0xop","status":"` + string(statuses[poll]) + `"}}`))
+	})
+
+	return httptest.NewServer(mux), polls
+}
+
+func TestWaitIssuerStateSigned(t *testing.T) {
+	options := WaitOptions{
+		Timeout:         time.Second,
+		InitialInterval: time.Millisecond,
+		MaxInterval:     2 * time.Millisecond,
+	}
+
+	t.Run("Should wait until operation is signed", func(t *testing.T) {
+		server, polls := newWaitServer(OperationStatusInitialized, OperationStatusApproved, OperationStatusSigned)
+		defer server.Close()
+
+		events := make([]WaitEvent, 0)
+
+		state, operation, err := NewClient(server.URL, server.Client()).WaitIssuerStateSigned(
+			context.Background(), "0x01", options, func(event WaitEvent) {
+				events = append(events, event)
+			},
+		)
+		if err != nil {
+			t.Fatalf("Error waiting: %v", err)
+		}
+
+		if state.Hash != "0x0a" || operation.Status != OperationStatusSigned || *polls != 3 {
+			t.Errorf("Unexpected result: %+v %+v after %d polls", state, operation, *polls)
+		}
+
+		if len(events) != 3 || events[0].NextPoll != time.Millisecond || events[1].NextPoll != 2*time.Millisecond {
+			t.Fatalf("Unexpected events: %+v", events)
+		}
+
+		if events[2].Attempt != 3 || events[2].NextPoll != 0 {
+			t.Errorf("Unexpected last event: %+v", events[2])
+		}
+	})
+	t.Run("Should report not approved operation", func(t *testing.T) {
+		server, _ := newWaitServer(OperationStatusInitialized, OperationStatusNotApproved)
+		defer server.Close()
+
+		_, operation, err := NewClient(server.URL, server.Client()).WaitIssuerStateSigned(context.Background(), "0x01", options, nil)
+		if !errors.Is(err, ErrOperationNotApproved) {
+			t.Fatalf("Expected not approved error, got %v", err)
+		}
+
+		if operation.Status != OperationStatusNotApproved {
+			t.Errorf("Unexpected status %s", operation.Status)
+		}
+	})
+	t.Run("Should time out", func(t *testing.T) {
+		server, _ := newWaitServer(OperationStatusInitialized)
+		defer server.Close()
+
+		_, _, err := NewClient(server.URL, server.Client()).WaitIssuerStateSigned(context.Background(), "0x01", WaitOptions{
+			Timeout:         20 * time.Millisecond,
+			InitialInterval: 5 * time.Millisecond,
+		}, nil)
+		if !errors.Is(err, ErrWaitTimeout) {
+			t.Errorf("Expected timeout error, got %v", err)
+		}
+	})
+	t.Run("Should retry retryable failures", func(t *testing.T) {
+		server, polls := newFailingWaitServer([]int{http.StatusServiceUnavailable, http.StatusTooManyRequests}, OperationStatusSigned)
+		defer server.Close()
+
+		_, operation, err := NewClient(server.URL, server.Client()).WaitIssuerStateSigned(context.Background(), "0x01", options, nil)
+		if err != nil {
+			t.Fatalf("Error waiting: %v", err)
+		}
+
+		if operation.Status != OperationStatusSigned || *polls != 1 {
+			t.Errorf("Unexpected operation %+v after %d polls", operation, *polls)
+		}
+	})
+	t.Run("Should not retry final failures", func(t *testing.T) {
+		server, polls := newFailingWaitServer([]int{http.StatusBadRequest}, OperationStatusSigned)
+		defer server.Close()
+
+		_, _, err := NewClient(server.URL, server.Client()).WaitIssuerStateSigned(context.Background(), "0x01", options, nil)
+		if errcode.Of(err) != errcode.InvalidInput || errors.Is(err, ErrWaitTimeout) {
+			t.Errorf("Expected the invalid input error, got %v", err)
+		}
+
+		if *polls != 0 {
+			t.Errorf("Expected no operation polls, got %d", *polls)
+		}
+	})
+	t.Run("Should keep the last failure with the timeout", func(t *testing.T) {
+		failures := make([]int, 1000)
+		for i := range failures {
+			failures[i] = http.StatusServiceUnavailable
+		}
+
+		server, _ := newFailingWaitServer(failures, OperationStatusSigned)
+		defer server.Close()
+
+		_, _, err := NewClient(server.URL, server.Client()).WaitIssuerStateSigned(context.Background(), "0x01", WaitOptions{
+			Timeout:         20 * time.Millisecond,
+			InitialInterval: 5 * time.Millisecond,
+		}, nil)
+
+		apiErr := &Error{}
+		if !errors.Is(err, ErrWaitTimeout) || !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("Expected timeout with the unavailable error, got %v", err)
+		}
+
+		if !errcode.Of(err).Retryable() {
+			t.Errorf("Expected retryable code, got %s", errcode.Of(err))
+		}
+	})
+}
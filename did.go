@@ -0,0 +1,119 @@
+package zkp_iden3_exposer
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/iden3/go-iden3-core/v2/w3c"
+	"github.com/iden3/go-schema-processor/v2/verifiable"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/resolver"
+	"math/big"
+)
+
+func (c *Connector) getResolver(ctx context.Context) (*resolver.Resolver, error) {
+	stateBackend, err := c.getBackends().Get(c.CoreEvmRpcApiUrl)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting state chain backend")
+	}
+
+	chainId, err := stateBackend.ChainID(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting state chain id")
+	}
+
+	didResolver, err := resolver.NewResolver(stateBackend, chainId, c.CoreStateContractAddress)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating resolver")
+	}
+
+	return didResolver, nil
+}
+
+// ResolveDid resolves the iden3 DID against StateV2 and returns the DID document json encoded,
+// "state" and "gist" DID query params select the historical state and GIST root
+func (c *Connector) ResolveDid(didString string) (_ []byte, err error) {
+	defer withCode(&err)
+
+	did, err := w3c.ParseDID(didString)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error parsing DID")
+	}
+
+	didResolver, err := c.getResolver(c.context())
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := didResolver.Resolve(c.context(), did)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error resolving DID")
+	}
+
+	documentJson, err := json.Marshal(document)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error marshalling DID document")
+	}
+
+	return documentJson, nil
+}
+
+// GetDidStateHistory returns the latest state, the genesis flag and all the published states of the DID json encoded
+func (c *Connector) GetDidStateHistory(didString string) (_ []byte, err error) {
+	defer withCode(&err)
+
+	did, err := w3c.ParseDID(didString)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error parsing DID")
+	}
+
+	didResolver, err := c.getResolver(c.context())
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := didResolver.GetStateHistory(c.context(), did)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting state history")
+	}
+
+	historyJson, err := json.Marshal(history)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error marshalling state history")
+	}
+
+	return historyJson, nil
+}
+
+// GetDidDocument returns the W3C DID document of the holder json encoded, a non zero profileNonce
+// selects the holder profile
+func (c *Connector) GetDidDocument(profileNonce int64) (_ []byte, err error) {
+	defer withCode(&err)
+
+	identity, err := c.getIdentity()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting identity")
+	}
+
+	didResolver, err := c.getResolver(c.context())
+	if err != nil {
+		return nil, err
+	}
+
+	var document verifiable.DIDDocument
+
+	if profileNonce == 0 {
+		document, err = didResolver.HolderDocument(c.context(), identity.DID, identity.TreeState.State.BigInt())
+	} else {
+		document, err = didResolver.ProfileDocument(c.context(), identity.DID, big.NewInt(profileNonce))
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Error building DID document")
+	}
+
+	documentJson, err := json.Marshal(document)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error marshalling DID document")
+	}
+
+	return documentJson, nil
+}
@@ -0,0 +1,175 @@
+package errcode
+
+import (
+	"context"
+	"crypto/x509"
+	"github.com/pkg/errors"
+	"net"
+	"net/url"
+)
+
+// Code Stable machine-readable error class, the values never change once released
+type Code string
+
+const (
+	Unknown Code = "UNKNOWN"
+	// Network the request did not reach the service or it answered with a server error
+	Network Code = "NETWORK"
+	// Timeout the deadline passed before the operation completed
+	Timeout Code = "TIMEOUT"
+	// Cancelled the caller cancelled the operation
+	Cancelled Code = "CANCELLED"
+	// CertificateRejected the TLS certificate is untrusted or does not match the pins
+	CertificateRejected Code = "CERTIFICATE_REJECTED"
+	// IssuerRejected the issuer refused the request, e.g. the offer is unknown or the proof is invalid
+	IssuerRejected Code = "ISSUER_REJECTED"
+	// CredentialRevoked the issuer revoked the credential
+	CredentialRevoked Code = "CREDENTIAL_REVOKED"
+	// StateNotSynced the issuer state or GIST root is not signed or transited to the target chain yet
+	StateNotSynced Code = "STATE_NOT_SYNCED"
+	// NotFound the identity, state or entity does not exist
+	NotFound Code = "NOT_FOUND"
+	// VerificationFailed the data returned by a service failed the client side verification
+	VerificationFailed Code = "VERIFICATION_FAILED"
+	// InvalidQuery the proof query does not match the credential
+	InvalidQuery Code = "INVALID_QUERY"
+	// InvalidInput the arguments passed by the caller are malformed
+	InvalidInput Code = "INVALID_INPUT"
+	// InvalidConfig the connector configuration is malformed or incomplete
+	InvalidConfig Code = "INVALID_CONFIG"
+	// KeyError the private key is missing or malformed
+	KeyError Code = "KEY_ERROR"
+	// CircuitArtifact the circuit artifacts are missing or corrupted
+	CircuitArtifact Code = "CIRCUIT_ARTIFACT"
+	// ProverFailed the witness calculation or the proving failed
+	ProverFailed Code = "PROVER_FAILED"
+	// TransactionFailed the transaction was rejected or reverted
+	TransactionFailed Code = "TRANSACTION_FAILED"
+)
+
+// Retryable reports whether repeating the same operation later may succeed
+func (c Code) Retryable() bool {
+	switch c {
+	case Network, Timeout, StateNotSynced:
+		return true
+	default:
+		return false
+	}
+}
+
+// Coder is implemented by the errors that know their code, e.g. the decoded API errors
+type Coder interface {
+	ErrorCode() Code
+}
+
+// Error Error carrying the code, used both for the package sentinels and for wrapping
+type Error struct {
+	code    Code
+	message string
+	cause   error
+}
+
+// New creates the error with the code, typically a package level sentinel
+func New(code Code, message string) error {
+	return &Error{code: code, message: message}
+}
+
+// Wrap annotates err with the code and the message, nil stays nil
+func Wrap(err error, code Code, message string) error {
+	if err == nil {
+		return nil
+	}
+
+	return &Error{code: code, message: message, cause: err}
+}
+
+// Classify annotates err with the code unless its code is already known, for the broad
+// failures which may as well be caused by the network
+func Classify(err error, code Code, message string) error {
+	if err == nil {
+		return nil
+	}
+
+	if Of(err) != Unknown {
+		return errors.Wrap(err, message)
+	}
+
+	return Wrap(err, code, message)
+}
+
+func (e *Error) Error() string {
+	if e.cause == nil {
+		return e.message
+	}
+
+	return e.message + ": " + e.cause.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+func (e *Error) ErrorCode() Code {
+	return e.code
+}
+
+// Of returns the code of the outermost coded error in the chain, falling back to the
+// standard library errors for the network, TLS and context failures
+func Of(err error) Code {
+	if err == nil {
+		return ""
+	}
+
+	// the cancelled context is the reason of whatever failed after it
+	switch {
+	case errors.Is(err, context.Canceled):
+		return Cancelled
+	case errors.Is(err, context.DeadlineExceeded):
+		return Timeout
+	}
+
+	var coder Coder
+	if errors.As(err, &coder) {
+		return coder.ErrorCode()
+	}
+
+	var (
+		unknownAuthority x509.UnknownAuthorityError
+		invalid          x509.CertificateInvalidError
+		hostname         x509.HostnameError
+	)
+	if errors.As(err, &unknownAuthority) || errors.As(err, &invalid) || errors.As(err, &hostname) {
+		return CertificateRejected
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return Timeout
+		}
+
+		return Network
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return Network
+	}
+
+	return Unknown
+}
+
+// FromHttpStatus classifies the unexpected status of the service response, rejected is used
+// for the client errors the service reports on purpose
+func FromHttpStatus(statusCode int, rejected Code) Code {
+	switch {
+	case statusCode == 404:
+		return NotFound
+	case statusCode == 408 || statusCode == 429 || statusCode >= 500:
+		return Network
+	case statusCode >= 400:
+		return rejected
+	default:
+		return Unknown
+	}
+}
@@ -0,0 +1,88 @@
+package errcode
+
+import (
+	"context"
+	"crypto/x509"
+	"github.com/pkg/errors"
+	"net/url"
+	"testing"
+)
+
+func TestOf(t *testing.T) {
+	sentinel := New(NotFound, "entity not found")
+
+	cases := []struct {
+		name string
+		err  error
+		code Code
+	}{
+		{"Should return the code of the sentinel", sentinel, NotFound},
+		{"Should see through the wrapping", errors.Wrap(errors.Wrap(sentinel, "inner"), "outer"), NotFound},
+		{"Should prefer the outermost code", Wrap(sentinel, InvalidInput, "bad input"), InvalidInput},
+		{"Should classify the cancelled context", errors.Wrap(context.Canceled, "request"), Cancelled},
+		{"Should classify the deadline", Wrap(context.DeadlineExceeded, Network, "request"), Timeout},
+		{"Should classify the untrusted certificate", &url.Error{Op: "Get", URL: "https://x", Err: x509.UnknownAuthorityError{}}, CertificateRejected},
+		{"Should classify the url error", &url.Error{Op: "Get", URL: "https://x", Err: errors.New("connection refused")}, Network},
+		{"Should fall back to unknown", errors.New("plain"), Unknown},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if code := Of(c.err); code != c.code {
+				t.Errorf("Expected %s, got %s", c.code, code)
+			}
+		})
+	}
+
+	t.Run("Should return the empty code for nil", func(t *testing.T) {
+		if code := Of(nil); code != "" {
+			t.Errorf("Expected empty code, got %s", code)
+		}
+	})
+}
+
+func TestClassify(t *testing.T) {
+	t.Run("Should keep the known code", func(t *testing.T) {
+		err := Classify(New(StateNotSynced, "not signed"), TransactionFailed, "send")
+		if Of(err) != StateNotSynced {
+			t.Errorf("Expected %s, got %s", StateNotSynced, Of(err))
+		}
+	})
+
+	t.Run("Should set the code of the unknown error", func(t *testing.T) {
+		err := Classify(errors.New("reverted"), TransactionFailed, "send")
+		if Of(err) != TransactionFailed {
+			t.Errorf("Expected %s, got %s", TransactionFailed, Of(err))
+		}
+
+		if err.Error() != "send: reverted" {
+			t.Errorf("Unexpected message %q", err.Error())
+		}
+	})
+}
+
+func TestFromHttpStatus(t *testing.T) {
+	cases := map[int]Code{
+		200: Unknown,
+		400: IssuerRejected,
+		404: NotFound,
+		429: Network,
+		503: Network,
+	}
+
+	for status, code := range cases {
+		if got := FromHttpStatus(status, IssuerRejected); got != code {
+			t.Errorf("Expected %s for %d, got %s", code, status, got)
+		}
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	if !Network.Retryable() || !StateNotSynced.Retryable() {
+		t.Errorf("Expected network and state sync failures to be retryable")
+	}
+
+	if InvalidInput.Retryable() || CredentialRevoked.Retryable() {
+		t.Errorf("Expected input and revocation failures to be final")
+	}
+}
@@ -0,0 +1,108 @@
+package zkp_iden3_exposer
+
+import (
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"strings"
+)
+
+// Error codes the connector errors are prefixed with, e.g. "NETWORK: Error getting offer: ..."
+const (
+	ErrorCodeUnknown             = string(errcode.Unknown)
+	ErrorCodeNetwork             = string(errcode.Network)
+	ErrorCodeTimeout             = string(errcode.Timeout)
+	ErrorCodeCancelled           = string(errcode.Cancelled)
+	ErrorCodeCertificateRejected = string(errcode.CertificateRejected)
+	ErrorCodeIssuerRejected      = string(errcode.IssuerRejected)
+	ErrorCodeCredentialRevoked   = string(errcode.CredentialRevoked)
+	ErrorCodeStateNotSynced      = string(errcode.StateNotSynced)
+	ErrorCodeNotFound            = string(errcode.NotFound)
+	ErrorCodeVerificationFailed  = string(errcode.VerificationFailed)
+	ErrorCodeInvalidQuery        = string(errcode.InvalidQuery)
+	ErrorCodeInvalidInput        = string(errcode.InvalidInput)
+	ErrorCodeInvalidConfig       = string(errcode.InvalidConfig)
+	ErrorCodeKeyError            = string(errcode.KeyError)
+	ErrorCodeCircuitArtifact     = string(errcode.CircuitArtifact)
+	ErrorCodeProverFailed        = string(errcode.ProverFailed)
+	ErrorCodeTransactionFailed   = string(errcode.TransactionFailed)
+)
+
+var errorCodes = map[string]bool{
+	ErrorCodeUnknown:             true,
+	ErrorCodeNetwork:             true,
+	ErrorCodeTimeout:             true,
+	ErrorCodeCancelled:           true,
+	ErrorCodeCertificateRejected: true,
+	ErrorCodeIssuerRejected:      true,
+	ErrorCodeCredentialRevoked:   true,
+	ErrorCodeStateNotSynced:      true,
+	ErrorCodeNotFound:            true,
+	ErrorCodeVerificationFailed:  true,
+	ErrorCodeInvalidQuery:        true,
+	ErrorCodeInvalidInput:        true,
+	ErrorCodeInvalidConfig:       true,
+	ErrorCodeKeyError:            true,
+	ErrorCodeCircuitArtifact:     true,
+	ErrorCodeProverFailed:        true,
+	ErrorCodeTransactionFailed:   true,
+}
+
+// GetErrorCode returns the code of the error message returned by the connector,
+// the apps only see the message through the gomobile bindings
+func GetErrorCode(message string) string {
+	code, _, found := strings.Cut(message, ": ")
+	if !found || !errorCodes[code] {
+		return ErrorCodeUnknown
+	}
+
+	return code
+}
+
+// IsRetryableErrorCode reports whether repeating the failed call later may succeed
+func IsRetryableErrorCode(code string) bool {
+	return errcode.Code(code).Retryable()
+}
+
+// codedError Error returned by the exported connector methods, the message starts with the code
+type codedError struct {
+	code    errcode.Code
+	message string
+	err     error
+}
+
+func (e *codedError) Error() string {
+	return string(e.code) + ": " + e.message
+}
+
+func (e *codedError) Unwrap() error {
+	return e.err
+}
+
+func (e *codedError) ErrorCode() errcode.Code {
+	return e.code
+}
+
+// withCode prefixes the error with its code, deferred by every exported method returning an error
+func withCode(err *error) {
+	if *err == nil {
+		return
+	}
+
+	message := (*err).Error()
+
+	// the exported methods calling each other must not leave the nested prefix in the middle
+	inner := &codedError{}
+	if errors.As(*err, &inner) {
+		if inner == *err {
+			return
+		}
+
+		message = strings.Replace(message, string(inner.code)+": ", "", 1)
+	}
+
+	*err = &codedError{
+		code:    errcode.Of(*err),
+		message: message,
+		err:     *err,
+	}
+}
@@ -0,0 +1,72 @@
+package zkp_iden3_exposer
+
+import (
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestErrorCodes(t *testing.T) {
+	connector, err := NewConnectorFromNetwork(
+		"local",
+		"1cbd5d2d1801e964736881fc0584473f23ba82669599ac65957fb4f2caf43e17",
+		[]byte(localOverrides),
+	)
+	if err != nil {
+		t.Fatalf("Error creating connector: %v", err)
+	}
+
+	t.Run("Should prefix the error with the code", func(t *testing.T) {
+		_, err := connector.GetAuthV2Inputs([]byte("not json"))
+		if err == nil {
+			t.Fatalf("Expected error")
+		}
+
+		if !strings.HasPrefix(err.Error(), ErrorCodeInvalidInput+": ") {
+			t.Errorf("Expected %s prefix, got %q", ErrorCodeInvalidInput, err.Error())
+		}
+
+		if GetErrorCode(err.Error()) != ErrorCodeInvalidInput {
+			t.Errorf("Expected %s, got %s", ErrorCodeInvalidInput, GetErrorCode(err.Error()))
+		}
+
+		if errcode.Of(err) != errcode.InvalidInput {
+			t.Errorf("Expected the code to be available to the go callers")
+		}
+	})
+
+	t.Run("Should classify the issuer responses", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		_, err := connector.GetOfferJson(server.URL, "did:iden3:test", "claim")
+		if GetErrorCode(err.Error()) != ErrorCodeNetwork {
+			t.Errorf("Expected %s, got %q", ErrorCodeNetwork, err.Error())
+		}
+
+		if !IsRetryableErrorCode(GetErrorCode(err.Error())) {
+			t.Errorf("Expected the failure to be retryable")
+		}
+	})
+
+	t.Run("Should not repeat the code of the nested exported call", func(t *testing.T) {
+		_, err := connector.FetchVC([]byte("not json"))
+		if err == nil {
+			t.Fatalf("Expected error")
+		}
+
+		if strings.Count(err.Error(), ErrorCodeInvalidInput) != 1 {
+			t.Errorf("Expected single code, got %q", err.Error())
+		}
+	})
+
+	t.Run("Should fall back to unknown", func(t *testing.T) {
+		if GetErrorCode("some error: details") != ErrorCodeUnknown {
+			t.Errorf("Expected %s", ErrorCodeUnknown)
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package zkp_iden3_exposer
+
+import (
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"github.com/rarimo/zkp-iden3-exposer/progress"
+	"time"
+)
+
+// Flows reported to the EventListener, the exported method calling another one reports it as its step
+const (
+	FlowGetOffer              = "get_offer"
+	FlowGetAuthV2Inputs       = "get_auth_v2_inputs"
+	FlowGetVC                 = "get_vc"
+	FlowFetchVC               = "fetch_vc"
+	FlowGetQueryInputs        = "get_query_inputs"
+	FlowGenerateQueryProof    = "generate_query_proof"
+	FlowProve                 = "prove"
+	FlowSubmitZKPResponse     = "submit_zkp_response"
+	FlowWaitIssuerStateSigned = "wait_issuer_state_signed"
+)
+
+// Steps reported to the EventListener
+const (
+	StepIdentity          = string(progress.StepIdentity)
+	StepIssuerOffer       = string(progress.StepIssuerOffer)
+	StepIssuerAgent       = string(progress.StepIssuerAgent)
+	StepJWZToken          = string(progress.StepJWZToken)
+	StepIssuerState       = string(progress.StepIssuerState)
+	StepOperationVerify   = string(progress.StepOperationVerify)
+	StepGISTProof         = string(progress.StepGISTProof)
+	StepCredentialStatus  = string(progress.StepCredentialStatus)
+	StepRevocationStatus  = string(progress.StepRevocationStatus)
+	StepJsonLdContexts    = string(progress.StepJsonLdContexts)
+	StepResolveGISTRoot   = string(progress.StepResolveGISTRoot)
+	StepSubmitTransaction = string(progress.StepSubmitTransaction)
+)
+
+// EventListener receives the steps of the long-running connector flows with their durations.
+// The flow itself is reported as the step with the empty name, errorCode is one of ErrorCode*
+type EventListener interface {
+	OnStepStarted(flow string, step string)
+	OnStepFinished(flow string, step string, durationMillis int64)
+	OnStepFailed(flow string, step string, durationMillis int64, errorCode string, errorMessage string)
+}
+
+// SetEventListener reports the progress of the connector flows to the listener, nil disables it
+func (c *Connector) SetEventListener(listener EventListener) {
+	c.eventListener = listener
+}
+
+type flowListener struct {
+	flow     string
+	listener EventListener
+}
+
+func (l flowListener) StepStarted(step progress.Step) {
+	l.listener.OnStepStarted(l.flow, string(step))
+}
+
+func (l flowListener) StepFinished(step progress.Step, duration time.Duration, err error) {
+	if err == nil {
+		l.listener.OnStepFinished(l.flow, string(step), duration.Milliseconds())
+		return
+	}
+
+	l.listener.OnStepFailed(l.flow, string(step), duration.Milliseconds(), string(errcode.Of(err)), err.Error())
+}
+
+// startFlow returns the connector reporting the steps of the flow and the function reporting its end,
+// within the already running flow the nested one is reported as its step
+func (c *Connector) startFlow(flow string) (*Connector, func(err error)) {
+	ctx := c.context()
+
+	if progress.FromContext(ctx) != nil {
+		return c, progress.Track(ctx, progress.Step(flow))
+	}
+
+	if c.eventListener == nil {
+		return c, func(error) {}
+	}
+
+	ctx = progress.WithListener(ctx, flowListener{flow: flow, listener: c.eventListener})
+
+	return c.WithContext(ctx), progress.Track(ctx, "")
+}
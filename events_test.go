@@ -0,0 +1,104 @@
+package zkp_iden3_exposer
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type recordingListener struct {
+	mutex  sync.Mutex
+	events []string
+}
+
+func (l *recordingListener) OnStepStarted(flow string, step string) {
+	l.record(fmt.Sprintf("start %s/%s", flow, step))
+}
+
+func (l *recordingListener) OnStepFinished(flow string, step string, durationMillis int64) {
+	l.record(fmt.Sprintf("finish %s/%s", flow, step))
+}
+
+func (l *recordingListener) OnStepFailed(flow string, step string, durationMillis int64, errorCode string, errorMessage string) {
+	l.record(fmt.Sprintf("fail %s/%s %s", flow, step, errorCode))
+}
+
+func (l *recordingListener) record(event string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.events = append(l.events, event)
+}
+
+func TestEventListener(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"1","typ":"application/iden3comm-plain-json","type":"offer","body":{"url":"http://issuer","credentials":[]}}`))
+	}))
+	defer server.Close()
+
+	connector, err := NewConnectorFromNetwork(
+		"local",
+		"1cbd5d2d1801e964736881fc0584473f23ba82669599ac65957fb4f2caf43e17",
+		[]byte(localOverrides),
+	)
+	if err != nil {
+		t.Fatalf("Error creating connector: %v", err)
+	}
+
+	t.Run("Should report the flow and its steps", func(t *testing.T) {
+		listener := &recordingListener{}
+		connector.SetEventListener(listener)
+
+		if _, err := connector.GetOfferJson(server.URL, "did:iden3:test", "claim"); err != nil {
+			t.Fatalf("Error getting offer: %v", err)
+		}
+
+		expected := []string{
+			"start get_offer/",
+			"start get_offer/issuer_offer",
+			"finish get_offer/issuer_offer",
+			"finish get_offer/",
+		}
+		if !reflect.DeepEqual(listener.events, expected) {
+			t.Errorf("Expected %v, got %v", expected, listener.events)
+		}
+	})
+
+	t.Run("Should report the nested flow as the step", func(t *testing.T) {
+		listener := &recordingListener{}
+		connector.SetEventListener(listener)
+
+		if _, err := connector.FetchVC([]byte("not json")); err == nil {
+			t.Fatalf("Expected error")
+		}
+
+		expected := []string{
+			"start fetch_vc/",
+			"start fetch_vc/get_auth_v2_inputs",
+			"start fetch_vc/identity",
+			"finish fetch_vc/identity",
+			"fail fetch_vc/get_auth_v2_inputs " + ErrorCodeInvalidInput,
+			"fail fetch_vc/ " + ErrorCodeInvalidInput,
+		}
+		if !reflect.DeepEqual(listener.events, expected) {
+			t.Errorf("Expected %v, got %v", expected, listener.events)
+		}
+	})
+
+	t.Run("Should report nothing without the listener", func(t *testing.T) {
+		listener := &recordingListener{}
+		connector.SetEventListener(listener)
+		connector.SetEventListener(nil)
+
+		if _, err := connector.GetOfferJson(server.URL, "did:iden3:test", "claim"); err != nil {
+			t.Fatalf("Error getting offer: %v", err)
+		}
+
+		if len(listener.events) != 0 {
+			t.Errorf("Expected no events, got %v", listener.events)
+		}
+	})
+}
@@ -0,0 +1,379 @@
+package backend
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrNoEndpoints = errcode.New(errcode.InvalidConfig, "no rpc endpoints configured")
+
+const (
+	DefaultCallTimeout   = 15 * time.Second
+	DefaultRetries       = 2
+	DefaultRetryInterval = 500 * time.Millisecond
+)
+
+// Options Call policy, zero values fall back to the defaults
+type Options struct {
+	// CallTimeout bounds a single call to a single endpoint
+	CallTimeout time.Duration
+	// Retries is the number of extra rounds over all the endpoints after the first one failed
+	Retries       int
+	RetryInterval time.Duration
+}
+
+func (o Options) withDefaults() Options {
+	if o.CallTimeout <= 0 {
+		o.CallTimeout = DefaultCallTimeout
+	}
+
+	if o.Retries < 0 {
+		o.Retries = 0
+	} else if o.Retries == 0 {
+		o.Retries = DefaultRetries
+	}
+
+	if o.RetryInterval <= 0 {
+		o.RetryInterval = DefaultRetryInterval
+	}
+
+	return o
+}
+
+// Dialer connects to a single endpoint, ethclient.DialContext by default
+type Dialer func(ctx context.Context, url string) (*ethclient.Client, error)
+
+// HttpDialer dials the HTTP endpoints with the client, e.g. to go through a proxy or pinned TLS,
+// the websocket ones are dialed as usual
+func HttpDialer(httpClient *http.Client) Dialer {
+	return func(ctx context.Context, url string) (*ethclient.Client, error) {
+		rpcClient, err := rpc.DialOptions(ctx, url, rpc.WithHTTPClient(httpClient))
+		if err != nil {
+			return nil, err
+		}
+
+		return ethclient.NewClient(rpcClient), nil
+	}
+}
+
+type endpoint struct {
+	url    string
+	client *ethclient.Client
+}
+
+// Failover EVM backend over several RPC endpoints of the same chain. Connections are dialed lazily
+// and reused, failed calls are retried on the next endpoint with backoff between the rounds.
+// JSON-RPC errors such as reverts are returned as is since another node would answer the same
+type Failover struct {
+	mutex     sync.Mutex
+	endpoints []*endpoint
+	current   int
+	options   Options
+	dial      Dialer
+}
+
+// SplitUrls parses the comma separated RPC URLs
+func SplitUrls(rpcUrls string) []string {
+	urls := make([]string, 0)
+
+	for _, url := range strings.Split(rpcUrls, ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			urls = append(urls, url)
+		}
+	}
+
+	return urls
+}
+
+func NewFailover(urls []string, options Options, dial Dialer) (*Failover, error) {
+	if len(urls) == 0 {
+		return nil, ErrNoEndpoints
+	}
+
+	if dial == nil {
+		dial = ethclient.DialContext
+	}
+
+	endpoints := make([]*endpoint, 0, len(urls))
+	for _, url := range urls {
+		endpoints = append(endpoints, &endpoint{url: url})
+	}
+
+	return &Failover{
+		endpoints: endpoints,
+		options:   options.withDefaults(),
+		dial:      dial,
+	}, nil
+}
+
+func (f *Failover) client(ctx context.Context, index int) (*ethclient.Client, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	endpoint := f.endpoints[index]
+	if endpoint.client != nil {
+		return endpoint.client, nil
+	}
+
+	client, err := f.dial(ctx, endpoint.url)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to dial %s", endpoint.url)
+	}
+
+	endpoint.client = client
+
+	return client, nil
+}
+
+// drop closes the endpoint connection after a transport failure so the next call redials it
+func (f *Failover) drop(index int, client *ethclient.Client) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.endpoints[index].client == client {
+		f.endpoints[index].client = nil
+		client.Close()
+	}
+}
+
+func (f *Failover) setCurrent(index int) {
+	f.mutex.Lock()
+	f.current = index
+	f.mutex.Unlock()
+}
+
+func (f *Failover) getCurrent() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.current
+}
+
+// Close closes all the dialed connections
+func (f *Failover) Close() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for _, endpoint := range f.endpoints {
+		if endpoint.client != nil {
+			endpoint.client.Close()
+			endpoint.client = nil
+		}
+	}
+}
+
+func isRetryable(err error) bool {
+	if errors.Is(err, ethereum.NotFound) {
+		return false
+	}
+
+	httpErr := rpc.HTTPError{}
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests || httpErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	var rpcErr rpc.Error
+	return !errors.As(err, &rpcErr)
+}
+
+func call[T any](ctx context.Context, f *Failover, fn func(ctx context.Context, client *ethclient.Client) (T, error)) (T, error) {
+	var (
+		result  T
+		lastErr error
+	)
+
+	interval := f.options.RetryInterval
+	start := f.getCurrent()
+
+	for round := 0; round <= f.options.Retries; round++ {
+		if round != 0 {
+			select {
+			case <-ctx.Done():
+				return result, errors.Wrap(ctx.Err(), lastErr.Error())
+			case <-time.After(interval):
+			}
+
+			interval *= 2
+		}
+
+		for i := range f.endpoints {
+			index := (start + i) % len(f.endpoints)
+
+			client, err := f.client(ctx, index)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+
+			callCtx, cancel := context.WithTimeout(ctx, f.options.CallTimeout)
+			result, err = fn(callCtx, client)
+			cancel()
+
+			if err == nil {
+				f.setCurrent(index)
+				return result, nil
+			}
+
+			if ctx.Err() != nil {
+				return result, err
+			}
+
+			if !isRetryable(err) {
+				return result, err
+			}
+
+			if !errors.As(err, &rpc.HTTPError{}) {
+				f.drop(index, client)
+			}
+
+			lastErr = errors.Wrap(err, f.endpoints[index].url)
+		}
+	}
+
+	return result, lastErr
+}
+
+func callNoResult(ctx context.Context, f *Failover, fn func(ctx context.Context, client *ethclient.Client) error) error {
+	_, err := call(ctx, f, func(ctx context.Context, client *ethclient.Client) (struct{}, error) {
+		return struct{}{}, fn(ctx, client)
+	})
+
+	return err
+}
+
+func (f *Failover) ChainID(ctx context.Context) (*big.Int, error) {
+	return call(ctx, f, func(ctx context.Context, client *ethclient.Client) (*big.Int, error) {
+		return client.ChainID(ctx)
+	})
+}
+
+func (f *Failover) BlockNumber(ctx context.Context) (uint64, error) {
+	return call(ctx, f, func(ctx context.Context, client *ethclient.Client) (uint64, error) {
+		return client.BlockNumber(ctx)
+	})
+}
+
+func (f *Failover) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return call(ctx, f, func(ctx context.Context, client *ethclient.Client) ([]byte, error) {
+		return client.CodeAt(ctx, contract, blockNumber)
+	})
+}
+
+func (f *Failover) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return call(ctx, f, func(ctx context.Context, client *ethclient.Client) ([]byte, error) {
+		return client.CallContract(ctx, msg, blockNumber)
+	})
+}
+
+func (f *Failover) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return call(ctx, f, func(ctx context.Context, client *ethclient.Client) (*types.Header, error) {
+		return client.HeaderByNumber(ctx, number)
+	})
+}
+
+func (f *Failover) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return call(ctx, f, func(ctx context.Context, client *ethclient.Client) ([]byte, error) {
+		return client.PendingCodeAt(ctx, account)
+	})
+}
+
+func (f *Failover) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return call(ctx, f, func(ctx context.Context, client *ethclient.Client) (uint64, error) {
+		return client.PendingNonceAt(ctx, account)
+	})
+}
+
+func (f *Failover) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return call(ctx, f, func(ctx context.Context, client *ethclient.Client) (*big.Int, error) {
+		return client.SuggestGasPrice(ctx)
+	})
+}
+
+func (f *Failover) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return call(ctx, f, func(ctx context.Context, client *ethclient.Client) (*big.Int, error) {
+		return client.SuggestGasTipCap(ctx)
+	})
+}
+
+func (f *Failover) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return call(ctx, f, func(ctx context.Context, client *ethclient.Client) (uint64, error) {
+		return client.EstimateGas(ctx, msg)
+	})
+}
+
+// SendTransaction rebroadcasting the same signed tx is safe, the nodes deduplicate it by hash.
+// The earlier attempt may have reached the network before failing, so the node rejecting the tx
+// as already known, or its nonce as used by this very tx, means it has been sent
+func (f *Failover) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return callNoResult(ctx, f, func(ctx context.Context, client *ethclient.Client) error {
+		err := client.SendTransaction(ctx, tx)
+		if err == nil || !isTransactionSent(ctx, client, tx, err) {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// isTransactionSent reports whether the send error stands for the tx being already in the pool or on chain
+func isTransactionSent(ctx context.Context, client *ethclient.Client, tx *types.Transaction, err error) bool {
+	message := strings.ToLower(err.Error())
+
+	if strings.Contains(message, "already known") || strings.Contains(message, "known transaction") {
+		return true
+	}
+
+	if !strings.Contains(message, "nonce too low") {
+		return false
+	}
+
+	// another tx may have used the nonce, so it counts only when the node knows this one
+	known, _, err := client.TransactionByHash(ctx, tx.Hash())
+
+	return err == nil && known.Hash() == tx.Hash()
+}
+
+func (f *Failover) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	return call(ctx, f, func(ctx context.Context, client *ethclient.Client) ([]types.Log, error) {
+		return client.FilterLogs(ctx, query)
+	})
+}
+
+// SubscribeFilterLogs subscribes on the first endpoint supporting it, the subscription itself does not fail over
+func (f *Failover) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	var lastErr error = ErrNoEndpoints
+
+	for index := range f.endpoints {
+		client, err := f.client(ctx, index)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		subscription, err := client.SubscribeFilterLogs(ctx, query, ch)
+		if err == nil {
+			return subscription, nil
+		}
+
+		lastErr = errors.Wrap(err, f.endpoints[index].url)
+	}
+
+	return nil, lastErr
+}
+
+func (f *Failover) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return call(ctx, f, func(ctx context.Context, client *ethclient.Client) (*types.Receipt, error) {
+		return client.TransactionReceipt(ctx, txHash)
+	})
+}
@@ -0,0 +1,213 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type rpcRequest struct {
+	Id     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+}
+
+// newNode serves eth_chainId and eth_call, status other than 200 fails every request
+func newNode(status int, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+
+		request := rpcRequest{}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch request.Method {
+		case "eth_chainId":
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(request.Id) + `,"result":"0xaa36a7"}`))
+		default:
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(request.Id) + `,"error":{"code":3,"message":"execution reverted"}}`))
+		}
+	}))
+}
+
+// newSendNode rejects the sent tx with the message and serves the known tx by hash, known may be nil
+func newSendNode(message string, known *types.Transaction) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := rpcRequest{}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch request.Method {
+		case "eth_sendRawTransaction":
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(request.Id) + `,"error":{"code":-32000,"message":"` + message + `"}}`))
+		case "eth_getTransactionByHash":
+			result := []byte("null")
+			if known != nil {
+				result, _ = known.MarshalJSON()
+			}
+
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(request.Id) + `,"result":` + string(result) + `}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func signedTx(t *testing.T) *types.Transaction {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+
+	chainId := big.NewInt(11155111)
+
+	tx, err := types.SignTx(
+		types.NewTransaction(0, common.HexToAddress("0x01"), big.NewInt(0), 21000, big.NewInt(1), nil),
+		types.NewEIP155Signer(chainId),
+		key,
+	)
+	if err != nil {
+		t.Fatalf("Error signing tx: %v", err)
+	}
+
+	return tx
+}
+
+func TestFailover(t *testing.T) {
+	options := Options{CallTimeout: time.Second, Retries: 1, RetryInterval: time.Millisecond}
+	ctx := context.Background()
+
+	t.Run("Should fail over to healthy endpoint and stick to it", func(t *testing.T) {
+		var brokenCalls, healthyCalls int32
+
+		broken := newNode(http.StatusServiceUnavailable, &brokenCalls)
+		defer broken.Close()
+		healthy := newNode(http.StatusOK, &healthyCalls)
+		defer healthy.Close()
+
+		failover, err := NewFailover(SplitUrls(broken.URL+", "+healthy.URL), options, nil)
+		if err != nil {
+			t.Fatalf("Error creating backend: %v", err)
+		}
+		defer failover.Close()
+
+		for i := 0; i < 2; i++ {
+			chainId, err := failover.ChainID(ctx)
+			if err != nil {
+				t.Fatalf("Error getting chain id: %v", err)
+			}
+
+			if chainId.Int64() != 11155111 {
+				t.Errorf("Unexpected chain id %s", chainId)
+			}
+		}
+
+		if brokenCalls != 1 || healthyCalls != 2 {
+			t.Errorf("Unexpected calls: broken %d, healthy %d", brokenCalls, healthyCalls)
+		}
+	})
+	t.Run("Should retry rounds and give up", func(t *testing.T) {
+		var calls int32
+
+		broken := newNode(http.StatusBadGateway, &calls)
+		defer broken.Close()
+
+		failover, _ := NewFailover([]string{broken.URL}, options, nil)
+		defer failover.Close()
+
+		if _, err := failover.ChainID(ctx); err == nil {
+			t.Fatalf("Expected error")
+		}
+
+		if calls != 2 {
+			t.Errorf("Expected 2 calls, got %d", calls)
+		}
+	})
+	t.Run("Should not retry rpc errors", func(t *testing.T) {
+		var firstCalls, secondCalls int32
+
+		first := newNode(http.StatusOK, &firstCalls)
+		defer first.Close()
+		second := newNode(http.StatusOK, &secondCalls)
+		defer second.Close()
+
+		failover, _ := NewFailover([]string{first.URL, second.URL}, options, nil)
+		defer failover.Close()
+
+		to := common.HexToAddress("0x01")
+		if _, err := failover.CallContract(ctx, ethereum.CallMsg{To: &to}, nil); err == nil {
+			t.Fatalf("Expected revert error")
+		}
+
+		if firstCalls != 1 || secondCalls != 0 {
+			t.Errorf("Unexpected calls: first %d, second %d", firstCalls, secondCalls)
+		}
+	})
+	t.Run("Should treat the resent tx known to the node as sent", func(t *testing.T) {
+		var brokenCalls int32
+
+		tx := signedTx(t)
+
+		// the first endpoint fails after the tx may have reached the network
+		broken := newNode(http.StatusServiceUnavailable, &brokenCalls)
+		defer broken.Close()
+		pooled := newSendNode("already known", nil)
+		defer pooled.Close()
+		mined := newSendNode("nonce too low: next nonce 1, tx nonce 0", tx)
+		defer mined.Close()
+
+		for _, node := range []*httptest.Server{pooled, mined} {
+			failover, _ := NewFailover([]string{broken.URL, node.URL}, options, nil)
+
+			if err := failover.SendTransaction(ctx, tx); err != nil {
+				t.Errorf("Expected the tx to be sent, got %v", err)
+			}
+
+			failover.Close()
+		}
+	})
+	t.Run("Should reject the nonce used by another tx", func(t *testing.T) {
+		node := newSendNode("nonce too low: next nonce 1, tx nonce 0", nil)
+		defer node.Close()
+
+		failover, _ := NewFailover([]string{node.URL}, options, nil)
+		defer failover.Close()
+
+		if err := failover.SendTransaction(ctx, signedTx(t)); err == nil {
+			t.Errorf("Expected nonce error")
+		}
+	})
+	t.Run("Should share backends in pool", func(t *testing.T) {
+		pool := NewPool(options, nil)
+		defer pool.Close()
+
+		first, err := pool.Get("http://a, http://b")
+		if err != nil {
+			t.Fatalf("Error getting backend: %v", err)
+		}
+
+		second, _ := pool.Get("http://a,http://b")
+		if first != second {
+			t.Errorf("Expected the same backend")
+		}
+
+		if _, err := pool.Get(" , "); err != ErrNoEndpoints {
+			t.Errorf("Expected no endpoints error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package backend
+
+import (
+	"strings"
+	"sync"
+)
+
+// Pool Shares the failover backends between the callers, keyed by the endpoints list
+type Pool struct {
+	mutex    sync.Mutex
+	options  Options
+	dial     Dialer
+	backends map[string]*Failover
+}
+
+var defaultPool = NewPool(Options{}, nil)
+
+// Default is the process wide pool used by the connector and the helpers
+func Default() *Pool {
+	return defaultPool
+}
+
+func NewPool(options Options, dial Dialer) *Pool {
+	return &Pool{
+		options:  options,
+		dial:     dial,
+		backends: map[string]*Failover{},
+	}
+}
+
+// Get returns the backend for the comma separated RPC URLs, creating it on the first use.
+// The nil pool stands for the default one
+func (p *Pool) Get(rpcUrls string) (*Failover, error) {
+	if p == nil {
+		p = Default()
+	}
+
+	urls := SplitUrls(rpcUrls)
+	key := strings.Join(urls, ",")
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if backend, ok := p.backends[key]; ok {
+		return backend, nil
+	}
+
+	backend, err := NewFailover(urls, p.options, p.dial)
+	if err != nil {
+		return nil, err
+	}
+
+	p.backends[key] = backend
+
+	return backend, nil
+}
+
+// Close closes the connections of every backend, the pool stays usable and redials on demand
+func (p *Pool) Close() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, backend := range p.backends {
+		backend.Close()
+	}
+}
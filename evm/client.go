@@ -0,0 +1,173 @@
+package evm
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/helpers"
+	"math/big"
+	"strings"
+)
+
+// Backend is the part of the EVM node API the client relies on, ethclient.Client and backend.Failover satisfy it
+type Backend interface {
+	bind.ContractBackend
+	bind.DeployBackend
+}
+
+type Client struct {
+	Backend    Backend
+	ChainId    *big.Int
+	PrivateKey *ecdsa.PrivateKey
+	Address    common.Address
+}
+
+// NewPrivateKey parses the hex encoded secp256k1 key, the same one the Rarimo wallet is derived from
+func NewPrivateKey(privateKeyHex string) (*ecdsa.PrivateKey, error) {
+	privateKeyBytes, err := hex.DecodeString(strings.TrimPrefix(privateKeyHex, "0x"))
+	if err != nil {
+		return nil, errcode.Wrap(err, errcode.KeyError, "Error decoding private key")
+	}
+
+	privateKey, err := crypto.ToECDSA(privateKeyBytes)
+	if err != nil {
+		return nil, errcode.Wrap(err, errcode.KeyError, "Error parsing private key")
+	}
+
+	return privateKey, nil
+}
+
+// GetAddress derives the Ethereum address from the hex encoded secp256k1 key
+func GetAddress(privateKeyHex string) (*common.Address, error) {
+	privateKey, err := NewPrivateKey(privateKeyHex)
+	if err != nil {
+		return nil, err
+	}
+
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+
+	return &address, nil
+}
+
+func NewClient(backend Backend, chainId *big.Int, privateKeyHex string) (*Client, error) {
+	privateKey, err := NewPrivateKey(privateKeyHex)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		Backend:    backend,
+		ChainId:    chainId,
+		PrivateKey: privateKey,
+		Address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+	}, nil
+}
+
+// BuildTx creates the signed EIP-1559 transaction, the gas limit is estimated by the node
+func (c *Client) BuildTx(ctx context.Context, to common.Address, calldata []byte, value *big.Int) (*types.Transaction, error) {
+	if value == nil {
+		value = big.NewInt(0)
+	}
+
+	nonce, err := c.Backend.PendingNonceAt(ctx, c.Address)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get nonce")
+	}
+
+	head, err := c.Backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get latest header")
+	}
+
+	if head.BaseFee == nil {
+		return nil, errcode.New(errcode.InvalidConfig, "Chain does not support EIP-1559")
+	}
+
+	gasTipCap, err := c.Backend.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to suggest gas tip cap")
+	}
+
+	// leaves room for the base fee to double before the tx becomes unexecutable
+	gasFeeCap := new(big.Int).Add(gasTipCap, new(big.Int).Mul(head.BaseFee, big.NewInt(2)))
+
+	gasLimit, err := c.Backend.EstimateGas(ctx, ethereum.CallMsg{
+		From:      c.Address,
+		To:        &to,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Value:     value,
+		Data:      calldata,
+	})
+	if err != nil {
+		return nil, errcode.Classify(err, errcode.TransactionFailed, "Failed to estimate gas")
+	}
+
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   c.ChainId,
+		Nonce:     nonce,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       gasLimit,
+		To:        &to,
+		Value:     value,
+		Data:      calldata,
+	})
+
+	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(c.ChainId), c.PrivateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to sign tx")
+	}
+
+	return signedTx, nil
+}
+
+// WaitMined blocks until the tx is included and fails if it was reverted
+func (c *Client) WaitMined(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
+	receipt, err := bind.WaitMined(ctx, c.Backend, tx)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to wait for tx")
+	}
+
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return receipt, errcode.New(errcode.TransactionFailed, "Tx "+tx.Hash().Hex()+" reverted")
+	}
+
+	return receipt, nil
+}
+
+// Transact builds, signs and broadcasts the tx, then waits for the receipt
+func (c *Client) Transact(ctx context.Context, to common.Address, calldata []byte, value *big.Int) (*types.Receipt, error) {
+	tx, err := c.BuildTx(ctx, to, calldata, value)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.Backend.SendTransaction(ctx, tx); err != nil {
+		return nil, errcode.Classify(err, errcode.TransactionFailed, "Failed to send tx")
+	}
+
+	return c.WaitMined(ctx, tx)
+}
+
+// SubmitZKPResponse sends the proof to the ZKPVerifier contract
+func (c *Client) SubmitZKPResponse(ctx context.Context, verifier common.Address, response helpers.ZKPResponse) (*types.Receipt, error) {
+	calldata, err := response.Pack()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to pack calldata")
+	}
+
+	receipt, err := c.Transact(ctx, verifier, calldata, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to submit ZKP response")
+	}
+
+	return receipt, nil
+}
@@ -0,0 +1,211 @@
+package evm
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	rapidsnarkTypes "github.com/iden3/go-rapidsnark/types"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/helpers"
+	"math/big"
+	"sync"
+	"testing"
+)
+
+// mockBackend mines every sent tx instantly, go-ethereum's simulated backend does not build
+// next to the pebble version required by cosmos-sdk
+type mockBackend struct {
+	mutex    sync.Mutex
+	chainId  *big.Int
+	nonces   map[common.Address]uint64
+	receipts map[common.Hash]*types.Receipt
+	sent     []*types.Transaction
+	revert   bool
+}
+
+func newMockBackend(chainId *big.Int) *mockBackend {
+	return &mockBackend{
+		chainId:  chainId,
+		nonces:   map[common.Address]uint64{},
+		receipts: map[common.Hash]*types.Receipt{},
+	}
+}
+
+func (m *mockBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{0x00}, nil
+}
+
+func (m *mockBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func (m *mockBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(1), BaseFee: big.NewInt(7)}, nil
+}
+
+func (m *mockBackend) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return []byte{0x00}, nil
+}
+
+func (m *mockBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.nonces[account], nil
+}
+
+func (m *mockBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(10), nil
+}
+
+func (m *mockBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (m *mockBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	return 21000 + uint64(len(call.Data))*16, nil
+}
+
+func (m *mockBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	from, err := types.Sender(types.LatestSignerForChainID(m.chainId), tx)
+	if err != nil {
+		return errors.Wrap(err, "invalid signature")
+	}
+
+	if tx.Nonce() != m.nonces[from] {
+		return errors.New("nonce too low")
+	}
+
+	m.nonces[from]++
+	m.sent = append(m.sent, tx)
+
+	status := types.ReceiptStatusSuccessful
+	if m.revert {
+		status = types.ReceiptStatusFailed
+	}
+
+	m.receipts[tx.Hash()] = &types.Receipt{
+		Type:        tx.Type(),
+		Status:      status,
+		TxHash:      tx.Hash(),
+		GasUsed:     tx.Gas(),
+		BlockNumber: big.NewInt(int64(len(m.sent))),
+	}
+
+	return nil
+}
+
+func (m *mockBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	return nil, nil
+}
+
+func (m *mockBackend) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return nil, errors.New("not supported")
+}
+
+func (m *mockBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	receipt, ok := m.receipts[txHash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+
+	return receipt, nil
+}
+
+func TestClient(t *testing.T) {
+	pk := "1cbd5d2d1801e964736881fc0584473f23ba82669599ac65957fb4f2caf43e17"
+	chainId := big.NewInt(11155111)
+	verifier := common.HexToAddress("0x8a9F505bD8a22BF09b0c19F65C17426cd33f3912")
+
+	response, err := helpers.NewZKPResponse(1, rapidsnarkTypes.ZKProof{
+		Proof: &rapidsnarkTypes.ProofData{
+			A:        []string{"1", "2", "1"},
+			B:        [][]string{{"3", "4"}, {"5", "6"}, {"1", "0"}},
+			C:        []string{"7", "8", "1"},
+			Protocol: "groth16",
+		},
+		PubSignals: []string{"9"},
+	})
+	if err != nil {
+		t.Fatalf("Error creating response: %v", err)
+	}
+
+	t.Run("Should derive address", func(t *testing.T) {
+		privateKey, err := NewPrivateKey(pk)
+		if err != nil {
+			t.Fatalf("Error parsing key: %v", err)
+		}
+
+		address, err := GetAddress("0x" + pk)
+		if err != nil {
+			t.Fatalf("Error deriving address: %v", err)
+		}
+
+		if *address != crypto.PubkeyToAddress(privateKey.PublicKey) {
+			t.Errorf("Unexpected address %s", address.Hex())
+		}
+	})
+	t.Run("Should submit ZKP response", func(t *testing.T) {
+		backend := newMockBackend(chainId)
+
+		client, err := NewClient(backend, chainId, pk)
+		if err != nil {
+			t.Fatalf("Error creating client: %v", err)
+		}
+
+		for i := 0; i < 2; i++ {
+			receipt, err := client.SubmitZKPResponse(context.Background(), verifier, *response)
+			if err != nil {
+				t.Fatalf("Error submitting response: %v", err)
+			}
+
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				t.Errorf("Unexpected receipt status %d", receipt.Status)
+			}
+		}
+
+		tx := backend.sent[1]
+
+		if tx.Type() != types.DynamicFeeTxType {
+			t.Errorf("Expected EIP-1559 tx, got type %d", tx.Type())
+		}
+
+		if tx.Nonce() != 1 {
+			t.Errorf("Expected nonce 1, got %d", tx.Nonce())
+		}
+
+		if *tx.To() != verifier {
+			t.Errorf("Unexpected recipient %s", tx.To().Hex())
+		}
+
+		calldata, _ := response.Pack()
+		if tx.Gas() != 21000+uint64(len(calldata))*16 {
+			t.Errorf("Unexpected gas limit %d", tx.Gas())
+		}
+
+		if tx.GasFeeCap().Int64() != 15 || tx.GasTipCap().Int64() != 1 {
+			t.Errorf("Unexpected fees %s/%s", tx.GasFeeCap(), tx.GasTipCap())
+		}
+	})
+	t.Run("Should fail on reverted tx", func(t *testing.T) {
+		backend := newMockBackend(chainId)
+		backend.revert = true
+
+		client, err := NewClient(backend, chainId, pk)
+		if err != nil {
+			t.Fatalf("Error creating client: %v", err)
+		}
+
+		if _, err := client.SubmitZKPResponse(context.Background(), verifier, *response); err == nil {
+			t.Errorf("Expected error for reverted tx")
+		}
+	})
+}
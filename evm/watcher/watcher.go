@@ -0,0 +1,193 @@
+package watcher
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/contracts"
+	"math/big"
+	"time"
+)
+
+const (
+	DefaultPollInterval = 15 * time.Second
+	// DefaultMaxBlockRange keeps eth_getLogs under the range limits of the public nodes
+	DefaultMaxBlockRange = 5000
+)
+
+// Backend is the part of the EVM node API the watcher relies on, backend.Failover satisfies it
+type Backend interface {
+	bind.ContractFilterer
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// Transit SignedStateTransited emitted by the LightweightStateV2
+type Transit struct {
+	GISTRoot             *big.Int
+	IdentitiesStatesRoot common.Hash
+	BlockNumber          uint64
+	TxHash               common.Hash
+}
+
+// Handler receives the transits in the chain order, returning true stops the watch
+type Handler func(transit Transit) (stop bool)
+
+type Options struct {
+	PollInterval  time.Duration
+	MaxBlockRange uint64
+	// FromBlock is the first block scanned, zero starts from the current head. With a subscription
+	// the blocks from it up to the head are scanned once before the notifications are handled
+	FromBlock uint64
+}
+
+// Watcher tracks the GIST roots transited to the target chain. It subscribes when the backend supports
+// notifications (WS endpoints) and polls the logs otherwise
+type Watcher struct {
+	backend  Backend
+	filterer *contracts.LightweightStateV2Filterer
+	options  Options
+}
+
+func NewWatcher(backend Backend, lightweightStateAddress common.Address, options Options) (*Watcher, error) {
+	filterer, err := contracts.NewLightweightStateV2Filterer(lightweightStateAddress, backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create lightweight state filterer")
+	}
+
+	if options.PollInterval <= 0 {
+		options.PollInterval = DefaultPollInterval
+	}
+
+	if options.MaxBlockRange == 0 {
+		options.MaxBlockRange = DefaultMaxBlockRange
+	}
+
+	return &Watcher{
+		backend:  backend,
+		filterer: filterer,
+		options:  options,
+	}, nil
+}
+
+func toTransit(event *contracts.LightweightStateV2SignedStateTransited) Transit {
+	return Transit{
+		GISTRoot:             event.NewGistRoot,
+		IdentitiesStatesRoot: event.NewIdentitesStatesRoot,
+		BlockNumber:          event.Raw.BlockNumber,
+		TxHash:               event.Raw.TxHash,
+	}
+}
+
+// Watch blocks until the handler stops it, the context is done or the node fails
+func (w *Watcher) Watch(ctx context.Context, handler Handler) error {
+	sink := make(chan *contracts.LightweightStateV2SignedStateTransited)
+
+	subscription, err := w.filterer.WatchSignedStateTransited(&bind.WatchOpts{Context: ctx}, sink)
+	if err != nil {
+		return w.poll(ctx, handler)
+	}
+	defer subscription.Unsubscribe()
+
+	// the transits mined before the subscription started are scanned once, the notifications repeating them are skipped
+	var scanned uint64
+	if w.options.FromBlock != 0 {
+		head, err := w.backend.BlockNumber(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to get block number")
+		}
+
+		stop, err := w.scanRange(ctx, w.options.FromBlock, head, handler)
+		if err != nil || stop {
+			return err
+		}
+
+		scanned = head
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-subscription.Err():
+			if err == nil {
+				return nil
+			}
+
+			return errors.Wrap(err, "subscription failed")
+		case event := <-sink:
+			if event.Raw.Removed || event.Raw.BlockNumber <= scanned {
+				continue
+			}
+
+			if handler(toTransit(event)) {
+				return nil
+			}
+		}
+	}
+}
+
+func (w *Watcher) poll(ctx context.Context, handler Handler) error {
+	from := w.options.FromBlock
+
+	for {
+		head, err := w.backend.BlockNumber(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to get block number")
+		}
+
+		if from == 0 {
+			from = head
+		}
+
+		stop, err := w.scanRange(ctx, from, head, handler)
+		if err != nil || stop {
+			return err
+		}
+
+		if head >= from {
+			from = head + 1
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(w.options.PollInterval):
+		}
+	}
+}
+
+// scanRange scans the blocks from-to in the chunks of MaxBlockRange
+func (w *Watcher) scanRange(ctx context.Context, from uint64, to uint64, handler Handler) (bool, error) {
+	for from <= to {
+		end := from + w.options.MaxBlockRange - 1
+		if end > to {
+			end = to
+		}
+
+		stop, err := w.scan(ctx, from, end, handler)
+		if err != nil || stop {
+			return stop, err
+		}
+
+		from = end + 1
+	}
+
+	return false, nil
+}
+
+func (w *Watcher) scan(ctx context.Context, from uint64, to uint64, handler Handler) (bool, error) {
+	iterator, err := w.filterer.FilterSignedStateTransited(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to filter transits in blocks %d-%d", from, to)
+	}
+	defer iterator.Close()
+
+	for iterator.Next() {
+		if handler(toTransit(iterator.Event)) {
+			return true, nil
+		}
+	}
+
+	return false, errors.Wrap(iterator.Error(), "failed to iterate transits")
+}
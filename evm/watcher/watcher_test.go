@@ -0,0 +1,185 @@
+package watcher
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/contracts"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+)
+
+var lightweightStateAddress = common.HexToAddress("0x02")
+
+// mockChain grows one block per BlockNumber call, transits are emitted at the given blocks
+type mockChain struct {
+	t         *testing.T
+	mutex     sync.Mutex
+	head      uint64
+	transits  map[uint64]*big.Int
+	subscribe bool
+	ranges    [][2]uint64
+}
+
+func (m *mockChain) newLog(block uint64, root *big.Int) types.Log {
+	parsed, err := contracts.LightweightStateV2MetaData.GetAbi()
+	if err != nil {
+		m.t.Fatalf("Error parsing abi: %v", err)
+	}
+
+	transitEvent := parsed.Events["SignedStateTransited"]
+
+	data, err := transitEvent.Inputs.Pack(root, common.BigToHash(root))
+	if err != nil {
+		m.t.Fatalf("Error packing event: %v", err)
+	}
+
+	return types.Log{
+		Address:     lightweightStateAddress,
+		Topics:      []common.Hash{transitEvent.ID},
+		Data:        data,
+		BlockNumber: block,
+	}
+}
+
+func (m *mockChain) BlockNumber(ctx context.Context) (uint64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.head++
+
+	return m.head, nil
+}
+
+func (m *mockChain) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	from, to := query.FromBlock.Uint64(), query.ToBlock.Uint64()
+	m.ranges = append(m.ranges, [2]uint64{from, to})
+
+	logs := make([]types.Log, 0)
+	for block := from; block <= to; block++ {
+		if root, ok := m.transits[block]; ok {
+			logs = append(logs, m.newLog(block, root))
+		}
+	}
+
+	return logs, nil
+}
+
+func (m *mockChain) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	if !m.subscribe {
+		return nil, errors.New("notifications not supported")
+	}
+
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		for block := uint64(1); block <= 3; block++ {
+			select {
+			case ch <- m.newLog(block, big.NewInt(int64(block))):
+			case <-quit:
+				return nil
+			}
+		}
+
+		<-quit
+		return nil
+	}), nil
+}
+
+func TestWatcher(t *testing.T) {
+	t.Run("Should poll transits in block ranges", func(t *testing.T) {
+		chain := &mockChain{t: t, head: 9, transits: map[uint64]*big.Int{4: big.NewInt(40), 12: big.NewInt(120)}}
+
+		transitWatcher, err := NewWatcher(chain, lightweightStateAddress, Options{
+			PollInterval:  time.Millisecond,
+			MaxBlockRange: 4,
+			FromBlock:     1,
+		})
+		if err != nil {
+			t.Fatalf("Error creating watcher: %v", err)
+		}
+
+		roots := make([]int64, 0)
+
+		err = transitWatcher.Watch(context.Background(), func(transit Transit) bool {
+			roots = append(roots, transit.GISTRoot.Int64())
+			return transit.GISTRoot.Int64() == 120
+		})
+		if err != nil {
+			t.Fatalf("Error watching: %v", err)
+		}
+
+		if len(roots) != 2 || roots[0] != 40 {
+			t.Errorf("Unexpected roots %v", roots)
+		}
+
+		if chain.ranges[0] != [2]uint64{1, 4} || chain.ranges[1] != [2]uint64{5, 8} || chain.ranges[2] != [2]uint64{9, 10} {
+			t.Errorf("Unexpected ranges %v", chain.ranges)
+		}
+	})
+	t.Run("Should use subscription when supported", func(t *testing.T) {
+		chain := &mockChain{t: t, subscribe: true}
+
+		transitWatcher, _ := NewWatcher(chain, lightweightStateAddress, Options{})
+
+		var last Transit
+
+		err := transitWatcher.Watch(context.Background(), func(transit Transit) bool {
+			last = transit
+			return transit.BlockNumber == 3
+		})
+		if err != nil {
+			t.Fatalf("Error watching: %v", err)
+		}
+
+		if last.GISTRoot.Int64() != 3 || last.IdentitiesStatesRoot != common.BigToHash(big.NewInt(3)) {
+			t.Errorf("Unexpected transit %+v", last)
+		}
+
+		if len(chain.ranges) != 0 {
+			t.Errorf("Expected no polling, got %v", chain.ranges)
+		}
+	})
+	t.Run("Should scan the blocks before the subscription", func(t *testing.T) {
+		chain := &mockChain{t: t, subscribe: true, head: 1, transits: map[uint64]*big.Int{2: big.NewInt(20)}}
+
+		transitWatcher, _ := NewWatcher(chain, lightweightStateAddress, Options{FromBlock: 1})
+
+		roots := make([]int64, 0)
+
+		err := transitWatcher.Watch(context.Background(), func(transit Transit) bool {
+			roots = append(roots, transit.GISTRoot.Int64())
+			return transit.BlockNumber == 3
+		})
+		if err != nil {
+			t.Fatalf("Error watching: %v", err)
+		}
+
+		// block 2 comes from the scan, the notifications up to the scanned head are skipped
+		if len(roots) != 2 || roots[0] != 20 || roots[1] != 3 {
+			t.Errorf("Unexpected roots %v", roots)
+		}
+
+		if len(chain.ranges) != 1 || chain.ranges[0] != [2]uint64{1, 2} {
+			t.Errorf("Unexpected ranges %v", chain.ranges)
+		}
+	})
+	t.Run("Should stop on context cancel", func(t *testing.T) {
+		chain := &mockChain{t: t, transits: map[uint64]*big.Int{}}
+
+		transitWatcher, _ := NewWatcher(chain, lightweightStateAddress, Options{PollInterval: time.Millisecond})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		if err := transitWatcher.Watch(ctx, func(transit Transit) bool { return false }); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected deadline error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package zkp_iden3_exposer
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"github.com/rarimo/zkp-iden3-exposer/progress"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/contracts"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/helpers"
+	"math/big"
+)
+
+const (
+	GISTReferenceOperation = ""
+	GISTReferenceLatest    = "latest"
+	GISTReferenceRoot      = "root"
+	GISTReferenceTime      = "time"
+	GISTReferenceBlock     = "block"
+	// GISTReferenceTarget picks the newest root already transited to the target LightweightStateV2
+	GISTReferenceTarget = "target"
+)
+
+// gistTargetSearchDepth Number of the source GIST roots checked against the target state
+const gistTargetSearchDepth = 256
+
+// SetGISTReference selects the GIST root the on-chain proofs are built against, value is the decimal
+// or 0x prefixed root, timestamp or block number. The empty kind uses the root of the issuer state operation
+func (c *Connector) SetGISTReference(kind string, value string) (err error) {
+	defer withCode(&err)
+
+	switch kind {
+	case GISTReferenceOperation, GISTReferenceLatest, GISTReferenceTarget:
+		c.gistReference = gistReference{kind: kind}
+		return nil
+	case GISTReferenceRoot, GISTReferenceTime, GISTReferenceBlock:
+		parsed, ok := new(big.Int).SetString(value, 0)
+		if !ok {
+			return errcode.New(errcode.InvalidInput, fmt.Sprintf("Invalid GIST reference value %q", value))
+		}
+
+		c.gistReference = gistReference{kind: kind, value: parsed}
+		return nil
+	default:
+		return errcode.New(errcode.InvalidInput, fmt.Sprintf("Unknown GIST reference kind %q", kind))
+	}
+}
+
+type gistReference struct {
+	kind  string
+	value *big.Int
+}
+
+func (c *Connector) resolveGISTReference(ctx context.Context) (helpers.GISTReference, error) {
+	switch c.gistReference.kind {
+	case GISTReferenceLatest:
+		return helpers.LatestGIST(), nil
+	case GISTReferenceRoot:
+		return helpers.GISTRoot(c.gistReference.value), nil
+	case GISTReferenceTime:
+		return helpers.GISTAtTime(c.gistReference.value), nil
+	case GISTReferenceBlock:
+		return helpers.GISTAtBlock(c.gistReference.value), nil
+	case GISTReferenceTarget:
+		done := progress.Track(ctx, progress.StepResolveGISTRoot)
+		root, err := c.findTargetGISTRoot(ctx)
+		done(err)
+		if err != nil {
+			return helpers.GISTReference{}, errors.Wrap(err, "Error finding GIST root on target chain")
+		}
+
+		return helpers.GISTRoot(root), nil
+	}
+
+	return helpers.GISTReference{}, nil
+}
+
+func (c *Connector) findTargetGISTRoot(ctx context.Context) (*big.Int, error) {
+	stateBackend, err := c.getBackends().Get(c.CoreEvmRpcApiUrl)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting state chain backend")
+	}
+
+	targetBackend, err := c.getBackends().Get(c.TargetRpcUrl)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting target chain backend")
+	}
+
+	stateV2Caller, err := contracts.NewStateV2Caller(common.HexToAddress(c.CoreStateContractAddress), stateBackend)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating state caller")
+	}
+
+	lightweightStateCaller, err := contracts.NewLightweightStateV2Caller(common.HexToAddress(c.TargetStateContractAddress), targetBackend)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating lightweight state caller")
+	}
+
+	return helpers.FindNewestCommonGISTRoot(ctx, stateV2Caller, lightweightStateCaller, gistTargetSearchDepth)
+}
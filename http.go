@@ -0,0 +1,121 @@
+package zkp_iden3_exposer
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"github.com/rarimo/zkp-iden3-exposer/evm/backend"
+	"github.com/rarimo/zkp-iden3-exposer/transport"
+	"net/http"
+	"time"
+)
+
+// HttpConfig Outbound HTTP policy of the connector, see transport.Options
+type HttpConfig struct {
+	// RootCAs are the PEM encoded certificates trusted in addition to the system ones
+	RootCAs string `json:"rootCAs"`
+	// Pins maps the host to the "sha256/<base64>" digests of the accepted public keys
+	Pins      map[string][]string `json:"pins"`
+	Proxy     string              `json:"proxy"`
+	UserAgent string              `json:"userAgent"`
+
+	TimeoutMillis             int64 `json:"timeoutMillis"`
+	DialTimeoutMillis         int64 `json:"dialTimeoutMillis"`
+	TLSHandshakeTimeoutMillis int64 `json:"tlsHandshakeTimeoutMillis"`
+}
+
+// HttpListener observes every outbound HTTP request, errorMessage is empty on success
+type HttpListener interface {
+	OnRequest(method string, url string)
+	OnResponse(method string, url string, statusCode int, durationMillis int64, errorMessage string)
+}
+
+// SetHttpTransport routes the issuer, core, revocation, JSON-LD and EVM RPC requests through
+// the client built from the json encoded HttpConfig, listener may be nil. The grpc connection
+// follows the same CA, pin, proxy and user agent settings
+func (c *Connector) SetHttpTransport(configJson []byte, listener HttpListener) (err error) {
+	defer withCode(&err)
+
+	config := HttpConfig{}
+	if len(configJson) != 0 {
+		if err := json.Unmarshal(configJson, &config); err != nil {
+			return errcode.Wrap(err, errcode.InvalidConfig, "Error unmarshalling http config")
+		}
+	}
+
+	options := transport.Options{
+		RootCAs:             []byte(config.RootCAs),
+		Pins:                config.Pins,
+		ProxyUrl:            config.Proxy,
+		UserAgent:           config.UserAgent,
+		Timeout:             time.Duration(config.TimeoutMillis) * time.Millisecond,
+		DialTimeout:         time.Duration(config.DialTimeoutMillis) * time.Millisecond,
+		TLSHandshakeTimeout: time.Duration(config.TLSHandshakeTimeoutMillis) * time.Millisecond,
+	}
+
+	if listener != nil {
+		options.OnRequest = func(request *http.Request) {
+			listener.OnRequest(request.Method, request.URL.String())
+		}
+		options.OnResponse = func(request *http.Request, response *http.Response, err error, duration time.Duration) {
+			statusCode, errorMessage := 0, ""
+			if response != nil {
+				statusCode = response.StatusCode
+			}
+			if err != nil {
+				errorMessage = err.Error()
+			}
+
+			listener.OnResponse(request.Method, request.URL.String(), statusCode, duration.Milliseconds(), errorMessage)
+		}
+	}
+
+	httpClient, err := transport.NewClient(options)
+	if err != nil {
+		return errors.Wrap(err, "Error creating http client")
+	}
+
+	c.SetHttpClient(httpClient)
+
+	return nil
+}
+
+// SetHttpClient routes every outbound HTTP request of the connector through the client,
+// nil restores the default one. The grpc connection reuses the TLS config, the dialer and the proxy
+// of the client, so its transport has to be an http.Transport or come from SetHttpTransport,
+// the grpc calls fail with InvalidConfig for the other round trippers
+func (c *Connector) SetHttpClient(httpClient *http.Client) {
+	c.httpClient = httpClient
+}
+
+func (c *Connector) getHttpClient() *http.Client {
+	if c.httpClient == nil {
+		return http.DefaultClient
+	}
+
+	return c.httpClient
+}
+
+// getBackends returns the EVM backends dialed with the connector HTTP client, the process wide
+// pool is used while the default client is
+func (c *Connector) getBackends() *backend.Pool {
+	if c.httpClient == nil {
+		return backend.Default()
+	}
+
+	s := c.getSession()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.backends == nil || s.backendsHttpClient != c.httpClient {
+		if s.backends != nil {
+			s.backends.Close()
+		}
+
+		s.backends = backend.NewPool(backend.Options{}, backend.HttpDialer(c.httpClient))
+		s.backendsHttpClient = c.httpClient
+	}
+
+	return s.backends
+}
@@ -0,0 +1,87 @@
+package zkp_iden3_exposer
+
+import (
+	"encoding/json"
+	"github.com/rarimo/zkp-iden3-exposer/evm/backend"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type recordingHttpListener struct {
+	requests  []string
+	responses []int
+}
+
+func (l *recordingHttpListener) OnRequest(method string, url string) {
+	l.requests = append(l.requests, method+" "+url)
+}
+
+func (l *recordingHttpListener) OnResponse(method string, url string, statusCode int, durationMillis int64, errorMessage string) {
+	l.responses = append(l.responses, statusCode)
+}
+
+func TestHttpTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.UserAgent() != "exposer-test/1.0" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"id": "offer", "from": "issuer"})
+	}))
+	defer server.Close()
+
+	connector, err := NewConnectorFromNetwork(
+		"local",
+		"1cbd5d2d1801e964736881fc0584473f23ba82669599ac65957fb4f2caf43e17",
+		[]byte(localOverrides),
+	)
+	if err != nil {
+		t.Fatalf("Error creating connector: %v", err)
+	}
+
+	t.Run("Should route requests through the configured transport", func(t *testing.T) {
+		listener := &recordingHttpListener{}
+
+		err := connector.SetHttpTransport([]byte(`{"userAgent":"exposer-test/1.0","timeoutMillis":5000}`), listener)
+		if err != nil {
+			t.Fatalf("Error setting http transport: %v", err)
+		}
+
+		if _, err := connector.GetOfferJson(server.URL, "did:iden3:test", "claim"); err != nil {
+			t.Fatalf("Error getting offer: %v", err)
+		}
+
+		expected := "GET " + server.URL + "/v1/credentials/did:iden3:test/claim"
+		if len(listener.requests) != 1 || listener.requests[0] != expected {
+			t.Errorf("Unexpected requests: %v", listener.requests)
+		}
+
+		if len(listener.responses) != 1 || listener.responses[0] != http.StatusOK {
+			t.Errorf("Unexpected responses: %v", listener.responses)
+		}
+	})
+	t.Run("Should dial EVM backends with the configured transport", func(t *testing.T) {
+		backends := connector.getBackends()
+
+		if backends == backend.Default() {
+			t.Fatalf("Expected the connector backends")
+		}
+
+		if connector.getBackends() != backends {
+			t.Errorf("Expected the backends to be reused")
+		}
+
+		connector.SetHttpClient(nil)
+
+		if connector.getBackends() != backend.Default() {
+			t.Errorf("Expected the default backends for the default client")
+		}
+	})
+	t.Run("Should reject invalid config", func(t *testing.T) {
+		if err := connector.SetHttpTransport([]byte(`{"rootCAs":"not a certificate"}`), nil); err == nil {
+			t.Errorf("Expected error for invalid root CAs")
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package zkp_iden3_exposer
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/didtype"
+)
+
+// BuildIdType returns the two bytes identity type of the DID method on the blockchain network,
+// e.g. ("iden3", "polygon", "amoy") or ("polygonid", "polygon", "main"), the custom networks
+// have to be registered with RegisterDidNetwork first
+func BuildIdType(method string, blockchain string, network string) (_ []byte, err error) {
+	defer withCode(&err)
+
+	idType, err := didtype.Build(method, blockchain, network)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error building id type")
+	}
+
+	return idType[:], nil
+}
+
+// RegisterDidNetwork makes the custom blockchain network known to the DID method, methodByte
+// is only required for the methods unknown to iden3 and chainId may be zero
+func RegisterDidNetwork(
+	method string,
+	blockchain string,
+	network string,
+	methodByte int,
+	networkFlag int,
+	chainId int,
+) (err error) {
+	defer withCode(&err)
+
+	if methodByte < 0 || methodByte > 0xff || networkFlag < 0 || networkFlag > 0xff {
+		return errcode.New(errcode.InvalidInput, "Method byte and network flag must fit in a byte")
+	}
+
+	err = didtype.Register(didtype.Registration{
+		Method:      method,
+		Blockchain:  blockchain,
+		Network:     network,
+		MethodByte:  byte(methodByte),
+		NetworkFlag: byte(networkFlag),
+		ChainId:     chainId,
+	})
+	if err != nil {
+		return errors.Wrap(err, "Error registering DID network")
+	}
+
+	return nil
+}
+
+// SetIdType switches the connector to the DID method network
+func (c *Connector) SetIdType(method string, blockchain string, network string) (err error) {
+	defer withCode(&err)
+
+	idType, err := BuildIdType(method, blockchain, network)
+	if err != nil {
+		return err
+	}
+
+	c.IdType = idType
+
+	return nil
+}
+
+// GetIdTypeJson describes the configured identity type as {"method","blockchain","network"}
+func (c *Connector) GetIdTypeJson() (_ []byte, err error) {
+	defer withCode(&err)
+
+	idType, err := didtype.Parse(c.IdType)
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid id type")
+	}
+
+	description, err := didtype.Describe(idType)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error describing id type")
+	}
+
+	descriptionJson, err := json.Marshal(description)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error marshalling id type")
+	}
+
+	return descriptionJson, nil
+}
+
+// GetNetworkDidString returns the DID of the same key on another DID method network,
+// the configured id type is left untouched
+func (c *Connector) GetNetworkDidString(method string, blockchain string, network string) (_ string, err error) {
+	defer withCode(&err)
+
+	idType, err := didtype.Build(method, blockchain, network)
+	if err != nil {
+		return "", errors.Wrap(err, "Error building id type")
+	}
+
+	identity, err := getIdentityInstance(*c.getIdentityConfigFor(idType))
+	if err != nil {
+		return "", errors.Wrap(err, "Error getting identity")
+	}
+
+	return identity.DID.String(), nil
+}
+
+// GetNetworkIdBigIntString returns the identifier of the same key on another DID method network
+func (c *Connector) GetNetworkIdBigIntString(method string, blockchain string, network string) (_ string, err error) {
+	defer withCode(&err)
+
+	idType, err := didtype.Build(method, blockchain, network)
+	if err != nil {
+		return "", errors.Wrap(err, "Error building id type")
+	}
+
+	identity, err := getIdentityInstance(*c.getIdentityConfigFor(idType))
+	if err != nil {
+		return "", errors.Wrap(err, "Error getting identity")
+	}
+
+	id, err := identity.ID()
+	if err != nil {
+		return "", errors.Wrap(err, "Error getting ID")
+	}
+
+	return id.BigInt().String(), nil
+}
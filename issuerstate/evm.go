@@ -0,0 +1,61 @@
+package issuerstate
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"github.com/rarimo/zkp-iden3-exposer/progress"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/contracts"
+	"math/big"
+)
+
+var ErrIssuerNotFound = errcode.New(errcode.NotFound, "issuer state is not published")
+
+// EvmProvider reads the issuer state and the current GIST root straight from an iden3 StateV2 deployment
+type EvmProvider struct {
+	stateV2Caller *contracts.StateV2Caller
+}
+
+func NewEvmProvider(caller bind.ContractCaller, stateContractAddress string) (*EvmProvider, error) {
+	stateV2Caller, err := contracts.NewStateV2Caller(common.HexToAddress(stateContractAddress), caller)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create state caller")
+	}
+
+	return &EvmProvider{stateV2Caller: stateV2Caller}, nil
+}
+
+func (p *EvmProvider) GetIssuerState(ctx context.Context, issuerId *big.Int) (_ *IssuerState, err error) {
+	done := progress.Track(ctx, progress.StepIssuerState)
+	defer func() { done(err) }()
+
+	opts := &bind.CallOpts{Context: ctx}
+
+	exists, err := p.stateV2Caller.IdExists(opts, issuerId)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check issuer existence")
+	}
+
+	if !exists {
+		return nil, errors.Wrap(ErrIssuerNotFound, toIdHex(issuerId))
+	}
+
+	stateInfo, err := p.stateV2Caller.GetStateInfoById(opts, issuerId)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get issuer state info")
+	}
+
+	gistRoot, err := p.stateV2Caller.GetGISTRoot(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get GIST root")
+	}
+
+	return &IssuerState{
+		StateHash:          toHash(stateInfo.State),
+		GISTHash:           toHash(gistRoot),
+		CreatedAtTimestamp: stateInfo.CreatedAtTimestamp.Uint64(),
+		CreatedAtBlock:     stateInfo.CreatedAtBlock.Uint64(),
+	}, nil
+}
@@ -0,0 +1,100 @@
+package issuerstate
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/contracts"
+	"math/big"
+	"testing"
+)
+
+// mockStateV2 answers StateV2 calls from the in-memory states
+type mockStateV2 struct {
+	abi      *abi.ABI
+	states   map[string]contracts.IStateStateInfo
+	gistRoot *big.Int
+}
+
+func (m *mockStateV2) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{0x00}, nil
+}
+
+func (m *mockStateV2) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	method, err := m.abi.MethodById(call.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+
+	args, err := method.Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+
+	switch method.Name {
+	case "idExists":
+		_, ok := m.states[args[0].(*big.Int).String()]
+		return method.Outputs.Pack(ok)
+	case "getStateInfoById":
+		return method.Outputs.Pack(m.states[args[0].(*big.Int).String()])
+	case "getGISTRoot":
+		return method.Outputs.Pack(m.gistRoot)
+	}
+
+	return nil, errors.Errorf("unexpected call %s", method.Name)
+}
+
+func TestEvmProvider(t *testing.T) {
+	stateV2Abi, err := contracts.StateV2MetaData.GetAbi()
+	if err != nil {
+		t.Fatalf("Error parsing abi: %v", err)
+	}
+
+	issuerId := big.NewInt(42)
+	backend := &mockStateV2{
+		abi: stateV2Abi,
+		states: map[string]contracts.IStateStateInfo{
+			issuerId.String(): {
+				Id:                  issuerId,
+				State:               big.NewInt(0x0a),
+				ReplacedByState:     big.NewInt(0),
+				CreatedAtTimestamp:  big.NewInt(1700000000),
+				ReplacedAtTimestamp: big.NewInt(0),
+				CreatedAtBlock:      big.NewInt(7),
+				ReplacedAtBlock:     big.NewInt(0),
+			},
+		},
+		gistRoot: big.NewInt(0x0b),
+	}
+
+	provider, err := NewEvmProvider(backend, "0x134B1BE34911E39A8397ec6289782989729807a4")
+	if err != nil {
+		t.Fatalf("Error creating provider: %v", err)
+	}
+
+	t.Run("Should read issuer state", func(t *testing.T) {
+		state, err := provider.GetIssuerState(context.Background(), issuerId)
+		if err != nil {
+			t.Fatalf("Error getting issuer state: %v", err)
+		}
+
+		if state.StateHash != "0x000000000000000000000000000000000000000000000000000000000000000a" {
+			t.Errorf("Unexpected state hash %s", state.StateHash)
+		}
+
+		if state.GISTHash != "0x000000000000000000000000000000000000000000000000000000000000000b" {
+			t.Errorf("Unexpected GIST hash %s", state.GISTHash)
+		}
+
+		if state.CreatedAtTimestamp != 1700000000 || state.CreatedAtBlock != 7 {
+			t.Errorf("Unexpected state info: %+v", state)
+		}
+	})
+	t.Run("Should report unknown issuer", func(t *testing.T) {
+		if _, err := provider.GetIssuerState(context.Background(), big.NewInt(1)); !errors.Is(err, ErrIssuerNotFound) {
+			t.Errorf("Expected issuer not found, got %v", err)
+		}
+	})
+}
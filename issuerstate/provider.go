@@ -0,0 +1,35 @@
+package issuerstate
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"math/big"
+)
+
+const (
+	SourceRest = "rest"
+	SourceEvm  = "evm"
+)
+
+// IssuerState Issuer state and the GIST root the on-chain proof is built against,
+// hashes are 0x prefixed 32 bytes big endian hex as returned by the core
+type IssuerState struct {
+	StateHash          string
+	GISTHash           string
+	CreatedAtTimestamp uint64
+	CreatedAtBlock     uint64
+}
+
+// Provider resolves the latest issuer state by the issuer id
+type Provider interface {
+	GetIssuerState(ctx context.Context, issuerId *big.Int) (*IssuerState, error)
+}
+
+func toHash(value *big.Int) string {
+	return hexutil.Encode(common.LeftPadBytes(value.Bytes(), 32))
+}
+
+func toIdHex(issuerId *big.Int) string {
+	return hexutil.Encode(issuerId.Bytes())
+}
@@ -0,0 +1,53 @@
+package issuerstate
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/coreapi"
+	"github.com/rarimo/zkp-iden3-exposer/progress"
+	"math/big"
+)
+
+// RestProvider reads the issuer state from the Rarimo core, waits for its transit operation to be signed,
+// verifies it against the trust anchor and checks the state is included in the signed states root before returning
+type RestProvider struct {
+	Client      *coreapi.Client
+	TrustAnchor coreapi.TrustAnchor
+	Wait        coreapi.WaitOptions
+	OnProgress  func(coreapi.WaitEvent)
+}
+
+func NewRestProvider(client *coreapi.Client, trustAnchor coreapi.TrustAnchor) *RestProvider {
+	return &RestProvider{
+		Client:      client,
+		TrustAnchor: trustAnchor,
+	}
+}
+
+func (p *RestProvider) GetIssuerState(ctx context.Context, issuerId *big.Int) (*IssuerState, error) {
+	done := progress.Track(ctx, progress.StepIssuerState)
+	state, operation, err := p.Client.WaitIssuerStateSigned(ctx, toIdHex(issuerId), p.Wait, p.OnProgress)
+	done(err)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wait for issuer state to be signed")
+	}
+
+	done = progress.Track(ctx, progress.StepOperationVerify)
+	err = p.Client.VerifyOperation(ctx, operation, p.TrustAnchor)
+	done(err)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to verify issuer state operation")
+	}
+
+	// the operation only signs the states root, the state itself is trusted once it is proven under the root
+	if err := p.Client.VerifyState(ctx, issuerId, state, operation); err != nil {
+		return nil, errors.Wrap(err, "failed to verify issuer state")
+	}
+
+	return &IssuerState{
+		StateHash:          state.Hash,
+		GISTHash:           operation.Details.GISTHash,
+		CreatedAtTimestamp: state.CreatedAtTimestamp,
+		CreatedAtBlock:     state.CreatedAtBlock,
+	}, nil
+}
@@ -0,0 +1,73 @@
+package zkp_iden3_exposer
+
+import (
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/network"
+)
+
+// NewConnectorFromNetwork creates the connector from the named network preset, overridesJson
+// optionally replaces single fields of the preset, e.g. {"chainInfo":{"targetRpcUrl":"..."}}.
+// The connector is validated like NewConnectorFromJSON, so the fields the preset lacks must be overridden
+func NewConnectorFromNetwork(networkName string, pkHex string, overridesJson []byte) (_ *Connector, err error) {
+	defer withCode(&err)
+
+	preset, err := network.Default().Get(networkName)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting network")
+	}
+
+	preset, err = preset.Override(overridesJson)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error overriding network")
+	}
+
+	if err := preset.Validate(); err != nil {
+		return nil, errors.Wrap(err, "Invalid network")
+	}
+
+	idType, err := preset.GetIdType()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting id type")
+	}
+
+	connector := NewConnector(
+		pkHex,
+
+		idType[:],
+		preset.SchemaHashHex,
+
+		preset.ChainInfo.TargetChainId,
+		preset.ChainInfo.TargetRpcUrl,
+		preset.ChainInfo.TargetStateContractAddress,
+
+		preset.ChainInfo.CoreApiUrl,
+		preset.ChainInfo.CoreEvmRpcApiUrl,
+		preset.ChainInfo.CoreStateContractAddress,
+
+		preset.Chain.ChainId,
+		preset.AddrPrefix,
+		preset.Chain.Denom,
+		preset.RpcApi,
+		int(preset.Chain.MinGasPrice),
+		int(preset.Chain.GasLimit),
+		preset.IsTLS,
+	)
+
+	// the presets leave the per deployment fields empty, so the result is checked like the json config
+	if err := connector.Validate(); err != nil {
+		return nil, errors.Wrapf(err, "Invalid connector for network %s", networkName)
+	}
+
+	return connector, nil
+}
+
+// LoadNetworks registers the networks from the JSON or YAML file for NewConnectorFromNetwork
+func LoadNetworks(path string) (err error) {
+	defer withCode(&err)
+
+	if err := network.Default().LoadFile(path); err != nil {
+		return errors.Wrap(err, "Error loading networks")
+	}
+
+	return nil
+}
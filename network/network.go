@@ -0,0 +1,117 @@
+package network
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/wallet"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/didtype"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/types"
+)
+
+// AuthBJJSchemaHash Schema hash of the iden3 BJJ auth claim the holder identity is built from
+const AuthBJJSchemaHash = "cca3371a6cb1b715004407e325bd993c"
+
+// Network Everything the connector needs to talk to one Rarimo deployment and its target chain
+type Network struct {
+	Name string `json:"name"`
+	// IdType is the hex encoded two bytes DID type, takes precedence over Did
+	IdType string `json:"idType,omitempty"`
+	// Did is the DID method, blockchain and network the IdType is built from
+	Did           *didtype.Type `json:"did,omitempty"`
+	SchemaHashHex string        `json:"schemaHashHex"`
+
+	ChainInfo types.ChainZkpInfo `json:"chainInfo"`
+	Chain     wallet.ChainConfig `json:"chain"`
+
+	AddrPrefix string `json:"addrPrefix"`
+	RpcApi     string `json:"rpcApi"`
+	IsTLS      bool   `json:"tls"`
+}
+
+func (n *Network) GetIdType() ([2]byte, error) {
+	if n.IdType == "" {
+		if n.Did == nil {
+			return [2]byte{}, errors.New("either id type or did is required")
+		}
+
+		return didtype.Build(n.Did.Method, n.Did.Blockchain, n.Did.Network)
+	}
+
+	idType, err := hex.DecodeString(n.IdType)
+	if err != nil {
+		return [2]byte{}, errors.Wrap(err, "failed to decode id type")
+	}
+
+	return didtype.Parse(idType)
+}
+
+func (n *Network) Validate() error {
+	if n.Name == "" {
+		return errors.New("network name is required")
+	}
+
+	if _, err := n.GetIdType(); err != nil {
+		return errors.Wrapf(err, "network %s", n.Name)
+	}
+
+	return nil
+}
+
+// Override returns the copy of the network with the fields present in the overrides json replaced,
+// nested objects are merged field by field
+func (n Network) Override(overridesJson []byte) (*Network, error) {
+	if len(overridesJson) == 0 {
+		return &n, nil
+	}
+
+	baseJson, err := json.Marshal(n)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal network")
+	}
+
+	base := map[string]interface{}{}
+	if err := json.Unmarshal(baseJson, &base); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal network")
+	}
+
+	overrides := map[string]interface{}{}
+	if err := json.Unmarshal(overridesJson, &overrides); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal overrides")
+	}
+
+	// the id type built from the overridden did must not be shadowed by the inherited one
+	_, hasDid := overrides["did"]
+	_, hasIdType := overrides["idType"]
+	if hasDid && !hasIdType {
+		delete(base, "idType")
+	}
+
+	mergedJson, err := json.Marshal(merge(base, overrides))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal merged network")
+	}
+
+	merged := Network{}
+	if err := json.Unmarshal(mergedJson, &merged); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal merged network")
+	}
+
+	return &merged, nil
+}
+
+func merge(base map[string]interface{}, overrides map[string]interface{}) map[string]interface{} {
+	for key, value := range overrides {
+		baseObject, baseIsObject := base[key].(map[string]interface{})
+		overrideObject, overrideIsObject := value.(map[string]interface{})
+
+		if baseIsObject && overrideIsObject {
+			base[key] = merge(baseObject, overrideObject)
+			continue
+		}
+
+		base[key] = value
+	}
+
+	return base
+}
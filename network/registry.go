@@ -0,0 +1,197 @@
+package network
+
+import (
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"github.com/rarimo/zkp-iden3-exposer/wallet"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/types"
+	"os"
+	"sigs.k8s.io/yaml"
+	"sort"
+	"sync"
+)
+
+const (
+	Mainnet = "mainnet"
+	// MainnetBeta is the former name of the mainnet preset, kept for the existing configs
+	MainnetBeta = "mainnet-beta"
+	Local       = "local"
+)
+
+var ErrNetworkNotFound = errcode.New(errcode.InvalidConfig, "network not found")
+
+// builtinNetworks the presets of the known deployments, the other ones (e.g. the testnet) are registered
+// with Register or Load
+func builtinNetworks() []Network {
+	mainnet := Network{
+		Name:          Mainnet,
+		IdType:        "0100",
+		SchemaHashHex: AuthBJJSchemaHash,
+		ChainInfo: types.ChainZkpInfo{
+			TargetChainId:              11155111,
+			TargetRpcUrl:               "https://endpoints.omniatech.io/v1/eth/sepolia/public",
+			TargetStateContractAddress: "0x8a9F505bD8a22BF09b0c19F65C17426cd33f3912",
+			CoreApiUrl:                 "https://rpc-api.node1.mainnet-beta.rarimo.com",
+			CoreEvmRpcApiUrl:           "https://rpc.evm.node1.mainnet-beta.rarimo.com",
+			CoreStateContractAddress:   "0x753a8678c85d5fb70A97CFaE37c84CE2fD67EDE8",
+		},
+		Chain: wallet.ChainConfig{
+			ChainId:     "rarimo_42-1",
+			Denom:       "stake",
+			MinGasPrice: 0,
+			GasLimit:    1000000,
+		},
+		AddrPrefix: "rarimo",
+		RpcApi:     "104.196.227.66:9090",
+		IsTLS:      true,
+	}
+
+	mainnetBeta := mainnet
+	mainnetBeta.Name = MainnetBeta
+
+	return []Network{
+		mainnet,
+		mainnetBeta,
+		// the devnet started from the rarimo-core config.yml, the target chain and the contracts
+		// are deployed per run so they have to be overridden, the connector config is invalid without them
+		{
+			Name:          Local,
+			IdType:        "0100",
+			SchemaHashHex: AuthBJJSchemaHash,
+			ChainInfo: types.ChainZkpInfo{
+				CoreApiUrl:       "http://localhost:1317",
+				CoreEvmRpcApiUrl: "http://localhost:8545",
+			},
+			Chain: wallet.ChainConfig{
+				ChainId:     "rarimo",
+				Denom:       "stake",
+				MinGasPrice: 0,
+				GasLimit:    1000000,
+			},
+			AddrPrefix: "rarimo",
+			RpcApi:     "localhost:9090",
+			IsTLS:      false,
+		},
+	}
+}
+
+type Registry struct {
+	mutex    sync.RWMutex
+	networks map[string]Network
+}
+
+var defaultRegistry = NewRegistry()
+
+// Default is the process wide registry NewConnectorFromNetwork looks the networks up in
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// NewRegistry creates the registry with the built-in presets
+func NewRegistry() *Registry {
+	registry := &Registry{networks: map[string]Network{}}
+
+	for _, network := range builtinNetworks() {
+		registry.networks[network.Name] = network
+	}
+
+	return registry
+}
+
+// Register adds or replaces the network
+func (r *Registry) Register(network Network) error {
+	if err := network.Validate(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.networks[network.Name] = network
+
+	return nil
+}
+
+func (r *Registry) Get(name string) (*Network, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	network, ok := r.networks[name]
+	if !ok {
+		return nil, errors.Wrap(ErrNetworkNotFound, name)
+	}
+
+	return &network, nil
+}
+
+func (r *Registry) Names() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	names := make([]string, 0, len(r.networks))
+	for name := range r.networks {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// networksFile Networks file layout, an entry with "extends" starts from the named network
+// and overrides only the fields it sets
+type networksFile struct {
+	Networks []map[string]interface{} `json:"networks"`
+}
+
+// Load registers the networks from the JSON or YAML document
+func (r *Registry) Load(data []byte) error {
+	file := networksFile{}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return errors.Wrap(err, "failed to parse networks")
+	}
+
+	for i, entry := range file.Networks {
+		base := Network{}
+
+		if extends, ok := entry["extends"].(string); ok {
+			parent, err := r.Get(extends)
+			if err != nil {
+				return errors.Wrapf(err, "network %d extends unknown network", i)
+			}
+
+			base = *parent
+			delete(entry, "extends")
+		}
+
+		entryJson, err := yaml.Marshal(entry)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal network %d", i)
+		}
+
+		entryJson, err = yaml.YAMLToJSON(entryJson)
+		if err != nil {
+			return errors.Wrapf(err, "failed to convert network %d", i)
+		}
+
+		network, err := base.Override(entryJson)
+		if err != nil {
+			return errors.Wrapf(err, "failed to build network %d", i)
+		}
+
+		if err := r.Register(*network); err != nil {
+			return errors.Wrapf(err, "failed to register network %d", i)
+		}
+	}
+
+	return nil
+}
+
+func (r *Registry) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to read networks file")
+	}
+
+	return r.Load(data)
+}
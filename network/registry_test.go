@@ -0,0 +1,151 @@
+package network
+
+import (
+	"github.com/pkg/errors"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Run("Should have the built-in networks", func(t *testing.T) {
+		registry := NewRegistry()
+
+		names := registry.Names()
+		if len(names) != 3 || names[0] != Local || names[1] != Mainnet || names[2] != MainnetBeta {
+			t.Fatalf("unexpected networks: %v", names)
+		}
+
+		mainnet, err := registry.Get(Mainnet)
+		if err != nil {
+			t.Fatalf("failed to get network: %v", err)
+		}
+
+		mainnetBeta, err := registry.Get(MainnetBeta)
+		if err != nil || mainnetBeta.ChainInfo != mainnet.ChainInfo || mainnetBeta.Chain != mainnet.Chain {
+			t.Errorf("expected %s to stay the alias of %s: %+v, %v", MainnetBeta, Mainnet, mainnetBeta, err)
+		}
+
+		idType, err := mainnet.GetIdType()
+		if err != nil {
+			t.Fatalf("failed to get id type: %v", err)
+		}
+
+		if idType != [2]byte{1, 0} {
+			t.Errorf("unexpected id type: %x", idType)
+		}
+	})
+	t.Run("Should report unknown network", func(t *testing.T) {
+		_, err := NewRegistry().Get("unknown")
+		if !errors.Is(err, ErrNetworkNotFound) {
+			t.Errorf("expected ErrNetworkNotFound, got %v", err)
+		}
+	})
+	t.Run("Should override single fields", func(t *testing.T) {
+		mainnet, err := NewRegistry().Get(MainnetBeta)
+		if err != nil {
+			t.Fatalf("failed to get network: %v", err)
+		}
+
+		overridden, err := mainnet.Override([]byte(`{"chainInfo":{"targetRpcUrl":"http://localhost:8545"},"chain":{"gasLimit":5}}`))
+		if err != nil {
+			t.Fatalf("failed to override network: %v", err)
+		}
+
+		if overridden.ChainInfo.TargetRpcUrl != "http://localhost:8545" {
+			t.Errorf("target rpc url was not overridden: %s", overridden.ChainInfo.TargetRpcUrl)
+		}
+
+		if overridden.ChainInfo.CoreApiUrl != mainnet.ChainInfo.CoreApiUrl {
+			t.Errorf("core api url was lost: %s", overridden.ChainInfo.CoreApiUrl)
+		}
+
+		if overridden.Chain.GasLimit != 5 || overridden.Chain.ChainId != mainnet.Chain.ChainId {
+			t.Errorf("unexpected chain config: %+v", overridden.Chain)
+		}
+
+		if mainnet.ChainInfo.TargetRpcUrl == "http://localhost:8545" {
+			t.Errorf("original network was modified")
+		}
+	})
+	t.Run("Should load networks from YAML", func(t *testing.T) {
+		registry := NewRegistry()
+
+		err := registry.Load([]byte(`
+networks:
+  - name: testnet
+    extends: mainnet-beta
+    chainInfo:
+      coreApiUrl: https://core.testnet.example
+    chain:
+      chainId: rarimo_201411-1
+  - name: amoy
+    extends: mainnet-beta
+    did:
+      method: polygonid
+      blockchain: polygon
+      network: amoy
+  - name: custom
+    idType: "0212"
+    schemaHashHex: cca3371a6cb1b715004407e325bd993c
+    addrPrefix: rarimo
+`))
+		if err != nil {
+			t.Fatalf("failed to load networks: %v", err)
+		}
+
+		testnet, err := registry.Get("testnet")
+		if err != nil {
+			t.Fatalf("failed to get network: %v", err)
+		}
+
+		if testnet.ChainInfo.CoreApiUrl != "https://core.testnet.example" || testnet.Chain.ChainId != "rarimo_201411-1" {
+			t.Errorf("overrides were not applied: %+v", testnet)
+		}
+
+		if testnet.ChainInfo.TargetChainId != 11155111 || testnet.RpcApi != "104.196.227.66:9090" {
+			t.Errorf("extended fields were lost: %+v", testnet)
+		}
+
+		amoy, err := registry.Get("amoy")
+		if err != nil {
+			t.Fatalf("failed to get network: %v", err)
+		}
+
+		idType, err := amoy.GetIdType()
+		if err != nil || idType != [2]byte{0x02, 0x13} {
+			t.Errorf("unexpected amoy id type %x: %v", idType, err)
+		}
+
+		custom, err := registry.Get("custom")
+		if err != nil {
+			t.Fatalf("failed to get network: %v", err)
+		}
+
+		idType, err = custom.GetIdType()
+		if err != nil || idType != [2]byte{0x02, 0x12} {
+			t.Errorf("unexpected id type %x: %v", idType, err)
+		}
+	})
+	t.Run("Should load networks from JSON", func(t *testing.T) {
+		registry := NewRegistry()
+
+		err := registry.Load([]byte(`{"networks":[{"name":"dev","extends":"local","chain":{"chainId":"rarimo-dev"}}]}`))
+		if err != nil {
+			t.Fatalf("failed to load networks: %v", err)
+		}
+
+		dev, err := registry.Get("dev")
+		if err != nil {
+			t.Fatalf("failed to get network: %v", err)
+		}
+
+		if dev.Chain.ChainId != "rarimo-dev" || dev.Chain.Denom != "stake" {
+			t.Errorf("unexpected chain config: %+v", dev.Chain)
+		}
+	})
+	t.Run("Should reject invalid id type", func(t *testing.T) {
+		err := NewRegistry().Load([]byte(`{"networks":[{"name":"broken","idType":"01"}]}`))
+		if err == nil {
+			t.Errorf("expected error for one byte id type")
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package zkp_iden3_exposer
+
+import (
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"strings"
+	"testing"
+)
+
+// localOverrides sets the devnet fields the local preset leaves empty
+const localOverrides = `{"chainInfo":{"coreStateContractAddress":"0x0000000000000000000000000000000000000001"}}`
+
+func TestNewConnectorFromNetwork(t *testing.T) {
+	t.Run("Should create connector from preset", func(t *testing.T) {
+		connector, err := NewConnectorFromNetwork(
+			"mainnet-beta",
+			"1cbd5d2d1801e964736881fc0584473f23ba82669599ac65957fb4f2caf43e17",
+			[]byte(`{"chain":{"gasLimit":2000000}}`),
+		)
+		if err != nil {
+			t.Fatalf("Error creating connector: %v", err)
+		}
+
+		if connector.ChainId != "rarimo_42-1" || connector.GasLimit != 2000000 || !connector.IsTLS {
+			t.Errorf("Unexpected chain config: %+v", connector)
+		}
+
+		if len(connector.IdType) != 2 || connector.IdType[0] != 1 || connector.IdType[1] != 0 {
+			t.Errorf("Unexpected id type: %x", connector.IdType)
+		}
+
+		if _, err := connector.GetDidString(); err != nil {
+			t.Errorf("Error getting DID: %v", err)
+		}
+	})
+	t.Run("Should reject the preset without the deployment fields", func(t *testing.T) {
+		_, err := NewConnectorFromNetwork("local", "1cbd5d2d1801e964736881fc0584473f23ba82669599ac65957fb4f2caf43e17", nil)
+		if errcode.Of(err) != errcode.InvalidConfig || !strings.Contains(err.Error(), "coreStateContractAddress is required") {
+			t.Errorf("Expected %s for the missing contract, got %v", ErrorCodeInvalidConfig, err)
+		}
+
+		_, err = NewConnectorFromNetwork("mainnet", "", nil)
+		if errcode.Of(err) != errcode.InvalidConfig || !strings.Contains(err.Error(), "pkHex is required") {
+			t.Errorf("Expected %s for the missing key, got %v", ErrorCodeInvalidConfig, err)
+		}
+
+		_, err = NewConnectorFromNetwork("mainnet", "1cbd5d2d1801e964736881fc0584473f23ba82669599ac65957fb4f2caf43e17", []byte(`{"chainInfo":{"coreApiUrl":""}}`))
+		if errcode.Of(err) != errcode.InvalidConfig || !strings.Contains(err.Error(), "coreApiUrl is required") {
+			t.Errorf("Expected %s for the missing url, got %v", ErrorCodeInvalidConfig, err)
+		}
+	})
+	t.Run("Should fail on unknown network", func(t *testing.T) {
+		if _, err := NewConnectorFromNetwork("unknown", "", nil); err == nil {
+			t.Errorf("Expected error for unknown network")
+		}
+	})
+}
+
+func TestIdType(t *testing.T) {
+	connector := NewConnector(
+		"1cbd5d2d1801e964736881fc0584473f23ba82669599ac65957fb4f2caf43e17",
+		[]byte{1},
+		"cca3371a6cb1b715004407e325bd993c",
+		0, "", "", "", "", "", "", "", "", "", 0, 0, false,
+	)
+
+	t.Run("Should reject invalid id type instead of panicking", func(t *testing.T) {
+		if _, err := connector.GetDidString(); err == nil {
+			t.Errorf("Expected error for one byte id type")
+		}
+	})
+	t.Run("Should manage DIDs for several networks", func(t *testing.T) {
+		if err := connector.SetIdType("polygonid", "polygon", "amoy"); err != nil {
+			t.Fatalf("Error setting id type: %v", err)
+		}
+
+		did, err := connector.GetDidString()
+		if err != nil {
+			t.Fatalf("Error getting DID: %v", err)
+		}
+
+		if !strings.HasPrefix(did, "did:polygonid:polygon:amoy:") {
+			t.Errorf("Unexpected DID: %s", did)
+		}
+
+		// the flag is arbitrary, rarimo has no assigned network flags
+		if err := RegisterDidNetwork("iden3", "rarimo", "main", 0, 0b0100_0001, 0); err != nil {
+			t.Fatalf("Error registering network: %v", err)
+		}
+
+		rarimoDid, err := connector.GetNetworkDidString("iden3", "rarimo", "main")
+		if err != nil {
+			t.Fatalf("Error getting network DID: %v", err)
+		}
+
+		if !strings.HasPrefix(rarimoDid, "did:iden3:rarimo:main:") {
+			t.Errorf("Unexpected network DID: %s", rarimoDid)
+		}
+
+		idTypeJson, err := connector.GetIdTypeJson()
+		if err != nil {
+			t.Fatalf("Error getting id type: %v", err)
+		}
+
+		if string(idTypeJson) != `{"method":"polygonid","blockchain":"polygon","network":"amoy"}` {
+			t.Errorf("Unexpected id type: %s", idTypeJson)
+		}
+	})
+}
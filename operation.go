@@ -0,0 +1,118 @@
+package zkp_iden3_exposer
+
+import (
+	"encoding/json"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	core "github.com/iden3/go-iden3-core/v2"
+	"github.com/iden3/go-iden3-core/v2/w3c"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/coreapi"
+	"github.com/rarimo/zkp-iden3-exposer/issuerstate"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/helpers"
+	"math/big"
+	"time"
+)
+
+// OperationWaitListener receives the progress of the wait for the issuer state operation,
+// nextPollMillis is zero once the operation is signed or rejected
+type OperationWaitListener interface {
+	OnProgress(attempt int, operationIndex string, status string, nextPollMillis int64)
+}
+
+// SetOperationWait configures how long the on-chain inputs builder waits for the issuer state
+// operation to be signed, zero timeout falls back to the default one
+func (c *Connector) SetOperationWait(timeoutSeconds int, listener OperationWaitListener) {
+	c.operationWait.Timeout = time.Duration(timeoutSeconds) * time.Second
+	c.operationListener = listener
+}
+
+// WaitIssuerStateSigned blocks until the latest state operation of the issuer is signed and returns it json encoded
+func (c *Connector) WaitIssuerStateSigned(issuerDid string) (_ []byte, err error) {
+	defer withCode(&err)
+
+	c, done := c.startFlow(FlowWaitIssuerStateSigned)
+	defer func() { done(err) }()
+
+	issuerId, err := getIssuerId(issuerDid)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting issuer ID")
+	}
+
+	_, operation, err := coreapi.NewClient(c.CoreApiUrl, c.getHttpClient()).WaitIssuerStateSigned(
+		c.context(),
+		hexutil.Encode(issuerId.Bytes()),
+		c.operationWait,
+		c.onOperationProgress(),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error waiting for issuer state to be signed")
+	}
+
+	operationJson, err := json.Marshal(operation)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error marshalling operation")
+	}
+
+	return operationJson, nil
+}
+
+func (c *Connector) onOperationProgress() func(coreapi.WaitEvent) {
+	listener := c.operationListener
+	if listener == nil {
+		return nil
+	}
+
+	return func(event coreapi.WaitEvent) {
+		listener.OnProgress(
+			event.Attempt,
+			event.Operation.Index,
+			string(event.Operation.Status),
+			event.NextPoll.Milliseconds(),
+		)
+	}
+}
+
+// getIssuerStateProvider returns the provider for the configured source
+func (c *Connector) getIssuerStateProvider() (issuerstate.Provider, error) {
+	switch c.IssuerStateSource {
+	case "", issuerstate.SourceRest:
+		trustAnchor, err := helpers.GetTrustAnchor(c.context(), c.getBackends(), c.TargetRpcUrl, c.TargetStateContractAddress)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error getting trust anchor")
+		}
+
+		provider := issuerstate.NewRestProvider(coreapi.NewClient(c.CoreApiUrl, c.getHttpClient()), *trustAnchor)
+		provider.Wait = c.operationWait
+		provider.OnProgress = c.onOperationProgress()
+
+		return provider, nil
+	case issuerstate.SourceEvm:
+		stateBackend, err := c.getBackends().Get(c.CoreEvmRpcApiUrl)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error getting state chain backend")
+		}
+
+		provider, err := issuerstate.NewEvmProvider(stateBackend, c.CoreStateContractAddress)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error creating evm issuer state provider")
+		}
+
+		return provider, nil
+	default:
+		return nil, errors.Errorf("Unknown issuer state source %q", c.IssuerStateSource)
+	}
+}
+
+func getIssuerId(issuerDid string) (*big.Int, error) {
+	did, err := w3c.ParseDID(issuerDid)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error parsing issuer DID")
+	}
+
+	id, err := core.IDFromDID(*did)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting ID from DID")
+	}
+
+	return id.BigInt(), nil
+}
@@ -0,0 +1,63 @@
+package progress
+
+import (
+	"context"
+	"time"
+)
+
+// Step Stable name of the step of the long-running flows, reported to the listener
+type Step string
+
+const (
+	StepIdentity          Step = "identity"
+	StepIssuerOffer       Step = "issuer_offer"
+	StepIssuerAgent       Step = "issuer_agent"
+	StepJWZToken          Step = "jwz_token"
+	StepIssuerState       Step = "issuer_state"
+	StepOperationVerify   Step = "operation_verify"
+	StepGISTProof         Step = "gist_proof"
+	StepCredentialStatus  Step = "credential_status"
+	StepRevocationStatus  Step = "revocation_status"
+	StepJsonLdContexts    Step = "jsonld_contexts"
+	StepResolveGISTRoot   Step = "resolve_gist_root"
+	StepSubmitTransaction Step = "submit_transaction"
+)
+
+// Listener receives the steps of the flow, StepFinished gets the nil error on success
+type Listener interface {
+	StepStarted(step Step)
+	StepFinished(step Step, duration time.Duration, err error)
+}
+
+type listenerKey struct{}
+
+// WithListener returns the context reporting the steps run with it to the listener
+func WithListener(ctx context.Context, listener Listener) context.Context {
+	return context.WithValue(ctx, listenerKey{}, listener)
+}
+
+// FromContext returns the listener of the context, nil if there is none
+func FromContext(ctx context.Context) Listener {
+	listener, _ := ctx.Value(listenerKey{}).(Listener)
+	return listener
+}
+
+// Track reports the start of the step and returns the function reporting its end, it is a no-op
+// for the context without the listener:
+//
+//	done := progress.Track(ctx, progress.StepGISTProof)
+//	proof, err := fetch()
+//	done(err)
+func Track(ctx context.Context, step Step) func(err error) {
+	listener := FromContext(ctx)
+	if listener == nil {
+		return func(error) {}
+	}
+
+	started := time.Now()
+	listener.StepStarted(step)
+
+	return func(err error) {
+		listener.StepFinished(step, time.Since(started), err)
+	}
+}
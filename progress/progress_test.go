@@ -0,0 +1,57 @@
+package progress
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"testing"
+	"time"
+)
+
+type step struct {
+	name     Step
+	finished bool
+	err      error
+}
+
+type listener struct {
+	steps []step
+}
+
+func (l *listener) StepStarted(name Step) {
+	l.steps = append(l.steps, step{name: name})
+}
+
+func (l *listener) StepFinished(name Step, duration time.Duration, err error) {
+	l.steps = append(l.steps, step{name: name, finished: true, err: err})
+}
+
+func TestTrack(t *testing.T) {
+	t.Run("Should report the start and the end of the step", func(t *testing.T) {
+		l := &listener{}
+		ctx := WithListener(context.Background(), l)
+		failure := errors.New("failure")
+
+		done := Track(ctx, StepGISTProof)
+		done(failure)
+
+		if len(l.steps) != 2 {
+			t.Fatalf("Expected 2 events, got %d", len(l.steps))
+		}
+
+		if l.steps[0].name != StepGISTProof || l.steps[0].finished {
+			t.Errorf("Unexpected start event %+v", l.steps[0])
+		}
+
+		if !l.steps[1].finished || l.steps[1].err != failure {
+			t.Errorf("Unexpected finish event %+v", l.steps[1])
+		}
+	})
+
+	t.Run("Should do nothing without the listener", func(t *testing.T) {
+		if FromContext(context.Background()) != nil {
+			t.Fatalf("Expected no listener")
+		}
+
+		Track(context.Background(), StepIdentity)(nil)
+	})
+}
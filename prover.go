@@ -0,0 +1,123 @@
+package zkp_iden3_exposer
+
+import (
+	"encoding/json"
+	"github.com/iden3/go-circuits/v2"
+	"github.com/iden3/go-rapidsnark/types"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/prover"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/registry"
+	"os"
+)
+
+// Prover generates the groth16 proof for the circuit inputs and returns it json encoded,
+// implement it in the host application to plug in a native prover
+type Prover interface {
+	Prove(circuitId string, inputs []byte) ([]byte, error)
+}
+
+type hostProver struct {
+	prover Prover
+}
+
+func (h *hostProver) Prove(circuitId circuits.CircuitID, inputs []byte) (*types.ZKProof, error) {
+	proofJson, err := h.prover.Prove(string(circuitId), inputs)
+	if err != nil {
+		return nil, errcode.Classify(err, errcode.ProverFailed, "Host prover failed")
+	}
+
+	proof := types.ZKProof{}
+	if err := json.Unmarshal(proofJson, &proof); err != nil {
+		return nil, errcode.Wrap(err, errcode.ProverFailed, "Error unmarshalling proof")
+	}
+
+	return &proof, nil
+}
+
+// SetProver replaces the built-in Go prover with the host one
+func (c *Connector) SetProver(p Prover) {
+	if p == nil {
+		c.prover = nil
+		return
+	}
+
+	c.prover = &hostProver{prover: p}
+}
+
+func (c *Connector) getCircuits() *registry.Registry {
+	if c.circuits == nil {
+		c.circuits = registry.NewRegistry(nil, registry.BundledDigests())
+	}
+
+	return c.circuits
+}
+
+// SetCircuitsDir loads the circuit artifacts from <dir>/<circuitId>/ on demand, the pins set before are kept
+func (c *Connector) SetCircuitsDir(dir string) {
+	c.circuits = c.getCircuits().WithFS(os.DirFS(dir))
+
+	if _, ok := c.prover.(*prover.Groth16Prover); ok {
+		c.prover = nil
+	}
+}
+
+// PinCircuit sets the hex encoded SHA-256 digests the circuit artifacts must match, the artifacts
+// with the empty digest are rejected unless AllowUnpinnedCircuits is set
+func (c *Connector) PinCircuit(circuitId string, wasmDigest string, provingKeyDigest string, verificationKeyDigest string) {
+	c.getCircuits().Pin(circuits.CircuitID(circuitId), registry.Digests{
+		Wasm:            wasmDigest,
+		ProvingKey:      provingKeyDigest,
+		VerificationKey: verificationKeyDigest,
+	})
+}
+
+// AllowUnpinnedCircuits accepts the circuit artifacts without the pinned digest, only meant for development
+func (c *Connector) AllowUnpinnedCircuits(allow bool) {
+	c.getCircuits().AllowUnpinned(allow)
+}
+
+// SetCircuit registers the circuit artifacts used by the built-in Go prover, they must match the pins
+func (c *Connector) SetCircuit(circuitId string, wasm []byte, provingKey []byte) (err error) {
+	defer withCode(&err)
+
+	circuitsRegistry := c.getCircuits()
+
+	if err := circuitsRegistry.Set(circuits.CircuitID(circuitId), registry.ArtifactWasm, wasm); err != nil {
+		return errors.Wrap(err, "Error setting wasm")
+	}
+
+	if err := circuitsRegistry.Set(circuits.CircuitID(circuitId), registry.ArtifactProvingKey, provingKey); err != nil {
+		return errors.Wrap(err, "Error setting proving key")
+	}
+
+	return nil
+}
+
+func (c *Connector) getProver() prover.Prover {
+	if c.prover == nil {
+		c.prover = prover.NewGroth16Prover(c.getCircuits())
+	}
+
+	return c.prover
+}
+
+// Prove generates the proof for the circuit inputs with the configured prover
+func (c *Connector) Prove(circuitId string, inputs []byte) (_ []byte, err error) {
+	defer withCode(&err)
+
+	c, done := c.startFlow(FlowProve)
+	defer func() { done(err) }()
+
+	proof, err := c.getProver().Prove(circuits.CircuitID(circuitId), inputs)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error generating proof")
+	}
+
+	proofJson, err := json.Marshal(proof)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error marshalling proof")
+	}
+
+	return proofJson, nil
+}
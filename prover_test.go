@@ -0,0 +1,99 @@
+package zkp_iden3_exposer
+
+import (
+	"encoding/json"
+	"github.com/iden3/go-circuits/v2"
+	"github.com/iden3/go-rapidsnark/types"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/registry"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type mockProver struct {
+	circuitId string
+}
+
+func (m *mockProver) Prove(circuitId string, inputs []byte) ([]byte, error) {
+	m.circuitId = circuitId
+
+	return json.Marshal(types.ZKProof{
+		Proof: &types.ProofData{
+			A:        []string{"1", "2", "1"},
+			B:        [][]string{{"1", "2"}, {"3", "4"}, {"1", "0"}},
+			C:        []string{"1", "2", "1"},
+			Protocol: "groth16",
+		},
+		PubSignals: []string{string(inputs)},
+	})
+}
+
+func TestConnectorProver(t *testing.T) {
+	t.Run("Should fail without circuit artifacts", func(t *testing.T) {
+		connector := Connector{}
+
+		if _, err := connector.Prove(string(circuits.AuthV2CircuitID), []byte("{}")); err == nil {
+			t.Errorf("Expected error for missing circuit")
+		}
+	})
+	t.Run("Should use host prover", func(t *testing.T) {
+		connector := Connector{}
+		host := mockProver{}
+
+		connector.SetProver(&host)
+
+		proofJson, err := connector.Prove(string(circuits.AuthV2CircuitID), []byte("42"))
+		if err != nil {
+			t.Fatalf("Error proving: %v", err)
+		}
+
+		proof := types.ZKProof{}
+		if err := json.Unmarshal(proofJson, &proof); err != nil {
+			t.Fatalf("Error unmarshalling proof: %v", err)
+		}
+
+		if host.circuitId != string(circuits.AuthV2CircuitID) {
+			t.Errorf("Expected circuit %s, got %s", circuits.AuthV2CircuitID, host.circuitId)
+		}
+
+		if len(proof.PubSignals) != 1 || proof.PubSignals[0] != "42" {
+			t.Errorf("Unexpected public signals: %v", proof.PubSignals)
+		}
+	})
+	t.Run("Should keep the pins when the circuits dir changes", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(dir, "auth"), 0o755); err != nil {
+			t.Fatalf("Error creating circuit dir: %v", err)
+		}
+
+		for _, artifact := range []registry.Artifact{registry.ArtifactWasm, registry.ArtifactProvingKey} {
+			if err := os.WriteFile(filepath.Join(dir, "auth", string(artifact)), []byte("artifact"), 0o644); err != nil {
+				t.Fatalf("Error writing artifact: %v", err)
+			}
+		}
+
+		connector := Connector{}
+		connector.PinCircuit(string(circuits.AuthV2CircuitID), strings.Repeat("00", 32), "", "")
+		connector.SetCircuitsDir(dir)
+
+		if _, err := connector.Prove(string(circuits.AuthV2CircuitID), []byte("{}")); !errors.Is(err, registry.ErrDigestMismatch) {
+			t.Errorf("Expected ErrDigestMismatch, got %v", err)
+		}
+
+		connector.PinCircuit(string(circuits.AuthV2CircuitID), "", "", "")
+
+		if _, err := connector.Prove(string(circuits.AuthV2CircuitID), []byte("{}")); !errors.Is(err, registry.ErrUnpinnedArtifact) {
+			t.Errorf("Expected ErrUnpinnedArtifact, got %v", err)
+		}
+
+		connector.AllowUnpinnedCircuits(true)
+		connector.SetCircuitsDir(dir)
+
+		_, err := connector.Prove(string(circuits.AuthV2CircuitID), []byte("{}"))
+		if err == nil || errors.Is(err, registry.ErrUnpinnedArtifact) {
+			t.Errorf("Expected the artifacts to be loaded, got %v", err)
+		}
+	})
+}
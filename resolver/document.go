@@ -0,0 +1,109 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	core "github.com/iden3/go-iden3-core/v2"
+	"github.com/iden3/go-iden3-core/v2/w3c"
+	"github.com/iden3/go-schema-processor/v2/verifiable"
+	"github.com/pkg/errors"
+	"math/big"
+)
+
+var documentContext = []string{
+	"https://www.w3.org/ns/did/v1",
+	"https://schema.iden3.io/core/jsonld/auth.jsonld",
+}
+
+// NewDocument DID document with the single Iden3StateInfo2023 verification method, the DID query is dropped.
+// stateContractAddress is "<chainId>:<address>" of the StateV2 the state is published to
+func NewDocument(did w3c.DID, stateContractAddress string, identityState verifiable.IdentityState) verifiable.DIDDocument {
+	did = withoutQuery(did)
+	didString := did.String()
+
+	return verifiable.DIDDocument{
+		Context: documentContext,
+		ID:      didString,
+		VerificationMethod: []verifiable.CommonVerificationMethod{
+			{
+				ID:                   fmt.Sprintf("%s#%s", didString, StateInfoFragment),
+				Type:                 StateInfoVerificationMethod,
+				Controller:           didString,
+				StateContractAddress: stateContractAddress,
+				IdentityState:        identityState,
+			},
+		},
+	}
+}
+
+func withoutQuery(did w3c.DID) w3c.DID {
+	return w3c.DID{Method: did.Method, ID: did.ID, IDStrings: did.IDStrings}
+}
+
+// HolderDocument DID document of the holder, while the holder is in the genesis state
+// the state info carries the genesis state so verifiers can check it against the DID
+func (r *Resolver) HolderDocument(ctx context.Context, did w3c.DID, genesisState *big.Int) (verifiable.DIDDocument, error) {
+	did = withoutQuery(did)
+
+	identityState, err := r.ResolveIdentityState(ctx, &did)
+	if err != nil {
+		return verifiable.DIDDocument{}, err
+	}
+
+	if !*identityState.Published {
+		if identityState.Info.State, err = toHashHex(genesisState); err != nil {
+			return verifiable.DIDDocument{}, errors.Wrap(err, "invalid genesis state")
+		}
+	}
+
+	return NewDocument(did, r.StateContractAddress(), *identityState), nil
+}
+
+// ProfileDocument DID document of the holder profile with the given nonce. Profiles are never published,
+// the GIST info is the current one
+func (r *Resolver) ProfileDocument(ctx context.Context, did w3c.DID, nonce *big.Int) (verifiable.DIDDocument, error) {
+	profileDid, err := ProfileDID(did, nonce)
+	if err != nil {
+		return verifiable.DIDDocument{}, err
+	}
+
+	holderDid := withoutQuery(did)
+
+	identityState, err := r.ResolveIdentityState(ctx, &holderDid)
+	if err != nil {
+		return verifiable.DIDDocument{}, err
+	}
+
+	published := false
+
+	return NewDocument(*profileDid, r.StateContractAddress(), verifiable.IdentityState{
+		Published: &published,
+		Info:      &verifiable.StateInfo{ID: profileDid.String()},
+		Global:    identityState.Global,
+	}), nil
+}
+
+// ProfileDID DID of the holder profile, the zero nonce is the holder DID itself
+func ProfileDID(did w3c.DID, nonce *big.Int) (*w3c.DID, error) {
+	if nonce.Sign() == 0 {
+		holderDid := withoutQuery(did)
+		return &holderDid, nil
+	}
+
+	id, err := core.IDFromDID(did)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get ID from DID")
+	}
+
+	profileId, err := core.ProfileID(id, nonce)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to derive profile ID")
+	}
+
+	profileDid, err := core.ParseDIDFromID(profileId)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build profile DID")
+	}
+
+	return profileDid, nil
+}
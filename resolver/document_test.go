@@ -0,0 +1,65 @@
+package resolver
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/iden3/go-schema-processor/v2/verifiable"
+	"math/big"
+	"testing"
+)
+
+func TestDocument(t *testing.T) {
+	didResolver, identities := newTestResolver(t)
+	ctx := context.Background()
+
+	t.Run("Should generate genesis holder document", func(t *testing.T) {
+		document, err := didResolver.HolderDocument(ctx, *identities.genesisDid, newGenesisState(2))
+		if err != nil {
+			t.Fatalf("Error generating document: %v", err)
+		}
+
+		documentJson, err := json.Marshal(document)
+		if err != nil {
+			t.Fatalf("Error marshalling document: %v", err)
+		}
+
+		parsed := verifiable.DIDDocument{}
+		if err := json.Unmarshal(documentJson, &parsed); err != nil {
+			t.Fatalf("Error parsing document: %v", err)
+		}
+
+		method := parsed.VerificationMethod[0]
+		genesisState, _ := toHashHex(newGenesisState(2))
+
+		if parsed.ID != identities.genesisDid.String() || method.ID != parsed.ID+"#state-info" {
+			t.Errorf("Unexpected ids %s %s", parsed.ID, method.ID)
+		}
+
+		if *method.Published || method.Info.State != genesisState || method.Global == nil {
+			t.Errorf("Unexpected identity state %+v", method.IdentityState)
+		}
+	})
+	t.Run("Should generate profile document", func(t *testing.T) {
+		document, err := didResolver.ProfileDocument(ctx, *identities.publishedDid, big.NewInt(7))
+		if err != nil {
+			t.Fatalf("Error generating document: %v", err)
+		}
+
+		profileDid, _ := ProfileDID(*identities.publishedDid, big.NewInt(7))
+
+		if document.ID == identities.publishedDid.String() || document.ID != profileDid.String() {
+			t.Errorf("Unexpected profile DID %s", document.ID)
+		}
+
+		method := document.VerificationMethod[0]
+		if *method.Published || method.Info.ID != document.ID || method.StateContractAddress != didResolver.StateContractAddress() {
+			t.Errorf("Unexpected verification method %+v", method)
+		}
+	})
+	t.Run("Should keep holder DID for zero nonce", func(t *testing.T) {
+		did, err := ProfileDID(*identities.publishedDid, big.NewInt(0))
+		if err != nil || did.String() != identities.publishedDid.String() {
+			t.Errorf("Unexpected DID %v: %v", did, err)
+		}
+	})
+}
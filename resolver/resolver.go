@@ -0,0 +1,301 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	core "github.com/iden3/go-iden3-core/v2"
+	"github.com/iden3/go-iden3-core/v2/w3c"
+	"github.com/iden3/go-merkletree-sql/v2"
+	"github.com/iden3/go-schema-processor/v2/verifiable"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/contracts"
+	"math/big"
+	"net/url"
+)
+
+const (
+	StateInfoVerificationMethod = "Iden3StateInfo2023"
+	StateInfoFragment           = "state-info"
+)
+
+var (
+	ErrStateNotFound    = errcode.New(errcode.NotFound, "state is not published for the identity")
+	ErrGISTRootNotFound = errcode.New(errcode.NotFound, "GIST root is not published")
+)
+
+// historyPageSize Number of states fetched per GetStateInfoHistoryById call
+const historyPageSize = 50
+
+// StateHistory Published states of the identity from the oldest one, Latest is nil while it is in the genesis state
+type StateHistory struct {
+	DID     string                 `json:"did"`
+	Genesis bool                   `json:"genesis"`
+	Latest  *verifiable.StateInfo  `json:"latest,omitempty"`
+	History []verifiable.StateInfo `json:"history"`
+}
+
+// Resolver resolves iden3 DIDs against a StateV2 deployment, it implements verifiable.DIDResolver and
+// honours the "state" and "gist" DID query params the same way the iden3 resolver driver does
+type Resolver struct {
+	stateV2Caller        *contracts.StateV2Caller
+	chainId              *big.Int
+	stateContractAddress common.Address
+}
+
+var _ verifiable.DIDResolver = (*Resolver)(nil)
+
+func NewResolver(caller bind.ContractCaller, chainId *big.Int, stateContractAddress string) (*Resolver, error) {
+	address := common.HexToAddress(stateContractAddress)
+
+	stateV2Caller, err := contracts.NewStateV2Caller(address, caller)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create state caller")
+	}
+
+	return &Resolver{
+		stateV2Caller:        stateV2Caller,
+		chainId:              chainId,
+		stateContractAddress: address,
+	}, nil
+}
+
+// StateContractAddress Contract reference used in the state info verification method
+func (r *Resolver) StateContractAddress() string {
+	return fmt.Sprintf("%s:%s", r.chainId, r.stateContractAddress.Hex())
+}
+
+func toStateInfo(did string, info contracts.IStateStateInfo) (*verifiable.StateInfo, error) {
+	state, err := toHashHex(info.State)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid state")
+	}
+
+	replacedByState, err := toHashHex(info.ReplacedByState)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid replaced by state")
+	}
+
+	return &verifiable.StateInfo{
+		ID:                  did,
+		State:               state,
+		ReplacedByState:     replacedByState,
+		CreatedAtTimestamp:  info.CreatedAtTimestamp.String(),
+		ReplacedAtTimestamp: info.ReplacedAtTimestamp.String(),
+		CreatedAtBlock:      info.CreatedAtBlock.String(),
+		ReplacedAtBlock:     info.ReplacedAtBlock.String(),
+	}, nil
+}
+
+func toGistInfo(info contracts.IStateGistRootInfo) (*verifiable.GistInfo, error) {
+	root, err := toHashHex(info.Root)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid root")
+	}
+
+	replacedByRoot, err := toHashHex(info.ReplacedByRoot)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid replaced by root")
+	}
+
+	return &verifiable.GistInfo{
+		Root:                root,
+		ReplacedByRoot:      replacedByRoot,
+		CreatedAtTimestamp:  info.CreatedAtTimestamp.String(),
+		ReplacedAtTimestamp: info.ReplacedAtTimestamp.String(),
+		CreatedAtBlock:      info.CreatedAtBlock.String(),
+		ReplacedAtBlock:     info.ReplacedAtBlock.String(),
+	}, nil
+}
+
+// toHashHex Little endian hex used by iden3 for the state and root hashes
+func toHashHex(value *big.Int) (string, error) {
+	hash, err := merkletree.NewHashFromBigInt(value)
+	if err != nil {
+		return "", err
+	}
+
+	return hash.Hex(), nil
+}
+
+func fromHashHex(value string) (*big.Int, error) {
+	hash, err := merkletree.NewHashFromHex(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return hash.BigInt(), nil
+}
+
+// GetStateHistory Latest state and the full state history of the DID
+func (r *Resolver) GetStateHistory(ctx context.Context, did *w3c.DID) (*StateHistory, error) {
+	id, err := core.IDFromDID(*did)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get ID from DID")
+	}
+
+	opts := &bind.CallOpts{Context: ctx}
+	didString := did.String()
+
+	history := &StateHistory{DID: didString, History: []verifiable.StateInfo{}}
+
+	exists, err := r.stateV2Caller.IdExists(opts, id.BigInt())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check identity existence")
+	}
+
+	if !exists {
+		history.Genesis = true
+		return history, nil
+	}
+
+	latest, err := r.stateV2Caller.GetStateInfoById(opts, id.BigInt())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get latest state")
+	}
+
+	if history.Latest, err = toStateInfo(didString, latest); err != nil {
+		return nil, err
+	}
+
+	length, err := r.stateV2Caller.GetStateInfoHistoryLengthById(opts, id.BigInt())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get state history length")
+	}
+
+	for start := int64(0); start < length.Int64(); start += historyPageSize {
+		count := length.Int64() - start
+		if count > historyPageSize {
+			count = historyPageSize
+		}
+
+		page, err := r.stateV2Caller.GetStateInfoHistoryById(opts, id.BigInt(), big.NewInt(start), big.NewInt(count))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get state history from %d", start)
+		}
+
+		for _, info := range page {
+			stateInfo, err := toStateInfo(didString, info)
+			if err != nil {
+				return nil, err
+			}
+
+			history.History = append(history.History, *stateInfo)
+		}
+	}
+
+	return history, nil
+}
+
+// ResolveIdentityState State info of the DID, the "state" and "gist" query params select historical values
+func (r *Resolver) ResolveIdentityState(ctx context.Context, did *w3c.DID) (*verifiable.IdentityState, error) {
+	id, err := core.IDFromDID(*did)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get ID from DID")
+	}
+
+	query, err := url.ParseQuery(did.Query)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse DID query")
+	}
+
+	opts := &bind.CallOpts{Context: ctx}
+	didWithoutQuery := withoutQuery(*did)
+	identityState := &verifiable.IdentityState{}
+
+	exists, err := r.stateV2Caller.IdExists(opts, id.BigInt())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check identity existence")
+	}
+
+	published := exists
+	identityState.Published = &published
+
+	switch {
+	case query.Get("state") != "":
+		state, err := fromHashHex(query.Get("state"))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid state query")
+		}
+
+		isGenesis, err := core.CheckGenesisStateID(id.BigInt(), state)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to check genesis state")
+		}
+
+		stateExists := false
+		if exists {
+			if stateExists, err = r.stateV2Caller.StateExists(opts, id.BigInt(), state); err != nil {
+				return nil, errors.Wrap(err, "failed to check state existence")
+			}
+		}
+
+		if !stateExists {
+			if !isGenesis {
+				return nil, errors.Wrap(ErrStateNotFound, query.Get("state"))
+			}
+
+			published = false
+			identityState.Info = &verifiable.StateInfo{ID: didWithoutQuery.String(), State: query.Get("state")}
+			break
+		}
+
+		info, err := r.stateV2Caller.GetStateInfoByIdAndState(opts, id.BigInt(), state)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get state info")
+		}
+
+		if identityState.Info, err = toStateInfo(didWithoutQuery.String(), info); err != nil {
+			return nil, err
+		}
+	case exists:
+		info, err := r.stateV2Caller.GetStateInfoById(opts, id.BigInt())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get state info")
+		}
+
+		if identityState.Info, err = toStateInfo(didWithoutQuery.String(), info); err != nil {
+			return nil, err
+		}
+	default:
+		identityState.Info = &verifiable.StateInfo{ID: didWithoutQuery.String()}
+	}
+
+	gistRoot, err := r.stateV2Caller.GetGISTRoot(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get GIST root")
+	}
+
+	if query.Get("gist") != "" {
+		if gistRoot, err = fromHashHex(query.Get("gist")); err != nil {
+			return nil, errors.Wrap(err, "invalid gist query")
+		}
+	}
+
+	gistInfo, err := r.stateV2Caller.GetGISTRootInfo(opts, gistRoot)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get GIST root info")
+	}
+
+	if gistInfo.CreatedAtTimestamp.Sign() == 0 {
+		return nil, errors.Wrap(ErrGISTRootNotFound, query.Get("gist"))
+	}
+
+	if identityState.Global, err = toGistInfo(gistInfo); err != nil {
+		return nil, err
+	}
+
+	return identityState, nil
+}
+
+// Resolve DID document with the Iden3StateInfo2023 verification method
+func (r *Resolver) Resolve(ctx context.Context, did *w3c.DID) (verifiable.DIDDocument, error) {
+	identityState, err := r.ResolveIdentityState(ctx, did)
+	if err != nil {
+		return verifiable.DIDDocument{}, err
+	}
+
+	return NewDocument(*did, r.StateContractAddress(), *identityState), nil
+}
@@ -0,0 +1,257 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	core "github.com/iden3/go-iden3-core/v2"
+	"github.com/iden3/go-iden3-core/v2/w3c"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/contracts"
+	"math/big"
+	"testing"
+)
+
+// mockStateV2 keeps the state history per identity, the last state is the latest one
+type mockStateV2 struct {
+	abi     *abi.ABI
+	history map[string][]contracts.IStateStateInfo
+	gist    contracts.IStateGistRootInfo
+}
+
+func (m *mockStateV2) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{0x00}, nil
+}
+
+func (m *mockStateV2) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	method, err := m.abi.MethodById(call.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+
+	args, err := method.Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+
+	var history []contracts.IStateStateInfo
+	if len(args) != 0 {
+		if id, ok := args[0].(*big.Int); ok {
+			history = m.history[id.String()]
+		}
+	}
+
+	findState := func(state *big.Int) *contracts.IStateStateInfo {
+		for _, info := range history {
+			if info.State.Cmp(state) == 0 {
+				return &info
+			}
+		}
+
+		return nil
+	}
+
+	switch method.Name {
+	case "idExists":
+		return method.Outputs.Pack(len(history) != 0)
+	case "getStateInfoById":
+		return method.Outputs.Pack(history[len(history)-1])
+	case "getStateInfoHistoryLengthById":
+		return method.Outputs.Pack(big.NewInt(int64(len(history))))
+	case "getStateInfoHistoryById":
+		start, length := args[1].(*big.Int).Int64(), args[2].(*big.Int).Int64()
+		return method.Outputs.Pack(history[start : start+length])
+	case "stateExists":
+		return method.Outputs.Pack(findState(args[1].(*big.Int)) != nil)
+	case "getStateInfoByIdAndState":
+		return method.Outputs.Pack(*findState(args[1].(*big.Int)))
+	case "getGISTRoot":
+		return method.Outputs.Pack(m.gist.Root)
+	case "getGISTRootInfo":
+		if args[0].(*big.Int).Cmp(m.gist.Root) != 0 {
+			return method.Outputs.Pack(newGistInfo(big.NewInt(0), 0))
+		}
+
+		return method.Outputs.Pack(m.gist)
+	}
+
+	return nil, errors.Errorf("unexpected call %s", method.Name)
+}
+
+func newGistInfo(root *big.Int, createdAt int64) contracts.IStateGistRootInfo {
+	return contracts.IStateGistRootInfo{
+		Root:                root,
+		ReplacedByRoot:      big.NewInt(0),
+		CreatedAtTimestamp:  big.NewInt(createdAt),
+		ReplacedAtTimestamp: big.NewInt(0),
+		CreatedAtBlock:      big.NewInt(createdAt),
+		ReplacedAtBlock:     big.NewInt(0),
+	}
+}
+
+func newStateInfo(id *big.Int, state int64, replacedBy int64, createdAt int64) contracts.IStateStateInfo {
+	return contracts.IStateStateInfo{
+		Id:                  id,
+		State:               big.NewInt(state),
+		ReplacedByState:     big.NewInt(replacedBy),
+		CreatedAtTimestamp:  big.NewInt(createdAt),
+		ReplacedAtTimestamp: big.NewInt(0),
+		CreatedAtBlock:      big.NewInt(createdAt / 10),
+		ReplacedAtBlock:     big.NewInt(0),
+	}
+}
+
+// newGenesisState fills all the bytes, the ID keeps only a part of the genesis state
+func newGenesisState(n byte) *big.Int {
+	return new(big.Int).SetBytes(bytes.Repeat([]byte{n}, 31))
+}
+
+func newDID(t *testing.T, genesisState *big.Int) (*w3c.DID, core.ID) {
+	typ, err := core.BuildDIDType(core.DIDMethodPolygonID, core.Polygon, core.Mumbai)
+	if err != nil {
+		t.Fatalf("Error building DID type: %v", err)
+	}
+
+	id, err := core.NewIDFromIdenState(typ, genesisState)
+	if err != nil {
+		t.Fatalf("Error building ID: %v", err)
+	}
+
+	did, err := core.ParseDIDFromID(*id)
+	if err != nil {
+		t.Fatalf("Error building DID: %v", err)
+	}
+
+	return did, *id
+}
+
+type testIdentities struct {
+	publishedDid *w3c.DID
+	genesisDid   *w3c.DID
+}
+
+func newTestResolver(t *testing.T) (*Resolver, testIdentities) {
+	stateV2Abi, err := contracts.StateV2MetaData.GetAbi()
+	if err != nil {
+		t.Fatalf("Error parsing abi: %v", err)
+	}
+
+	publishedDid, publishedId := newDID(t, newGenesisState(1))
+	genesisDid, _ := newDID(t, newGenesisState(2))
+
+	history := make([]contracts.IStateStateInfo, 0)
+	for i := int64(1); i <= 60; i++ {
+		replacedBy := i + 1
+		if i == 60 {
+			replacedBy = 0
+		}
+
+		history = append(history, newStateInfo(publishedId.BigInt(), i, replacedBy, 1000+i))
+	}
+
+	backend := &mockStateV2{
+		abi:     stateV2Abi,
+		history: map[string][]contracts.IStateStateInfo{publishedId.BigInt().String(): history},
+		gist:    newGistInfo(big.NewInt(77), 5000),
+	}
+
+	didResolver, err := NewResolver(backend, big.NewInt(80001), "0x134B1BE34911E39A8397ec6289782989729807a4")
+	if err != nil {
+		t.Fatalf("Error creating resolver: %v", err)
+	}
+
+	return didResolver, testIdentities{publishedDid: publishedDid, genesisDid: genesisDid}
+}
+
+func TestResolver(t *testing.T) {
+	didResolver, identities := newTestResolver(t)
+	publishedDid, genesisDid := identities.publishedDid, identities.genesisDid
+
+	ctx := context.Background()
+
+	t.Run("Should get full state history", func(t *testing.T) {
+		stateHistory, err := didResolver.GetStateHistory(ctx, publishedDid)
+		if err != nil {
+			t.Fatalf("Error getting history: %v", err)
+		}
+
+		if stateHistory.Genesis || len(stateHistory.History) != 60 {
+			t.Fatalf("Unexpected history: genesis %v, %d states", stateHistory.Genesis, len(stateHistory.History))
+		}
+
+		latest, _ := toHashHex(big.NewInt(60))
+		if stateHistory.Latest.State != latest || stateHistory.Latest.CreatedAtTimestamp != "1060" {
+			t.Errorf("Unexpected latest state %+v", stateHistory.Latest)
+		}
+
+		if stateHistory.History[10].ReplacedByState != stateHistory.History[11].State {
+			t.Errorf("Broken replaced by link %+v", stateHistory.History[10])
+		}
+	})
+	t.Run("Should report genesis identity", func(t *testing.T) {
+		stateHistory, err := didResolver.GetStateHistory(ctx, genesisDid)
+		if err != nil {
+			t.Fatalf("Error getting history: %v", err)
+		}
+
+		if !stateHistory.Genesis || stateHistory.Latest != nil || len(stateHistory.History) != 0 {
+			t.Errorf("Unexpected genesis history %+v", stateHistory)
+		}
+	})
+	t.Run("Should resolve DID document", func(t *testing.T) {
+		document, err := didResolver.Resolve(ctx, publishedDid)
+		if err != nil {
+			t.Fatalf("Error resolving DID: %v", err)
+		}
+
+		if document.ID != publishedDid.String() || len(document.VerificationMethod) != 1 {
+			t.Fatalf("Unexpected document %+v", document)
+		}
+
+		method := document.VerificationMethod[0]
+		if method.Type != StateInfoVerificationMethod || method.StateContractAddress != "80001:0x134B1BE34911E39A8397ec6289782989729807a4" {
+			t.Errorf("Unexpected verification method %+v", method)
+		}
+
+		root, _ := toHashHex(big.NewInt(77))
+		if !*method.Published || method.Info.CreatedAtTimestamp != "1060" || method.Global.Root != root {
+			t.Errorf("Unexpected identity state %+v %+v", method.Info, method.Global)
+		}
+	})
+	t.Run("Should resolve historical state", func(t *testing.T) {
+		state, _ := toHashHex(big.NewInt(5))
+
+		did, _ := w3c.ParseDID(publishedDid.String() + "?state=" + state)
+
+		identityState, err := didResolver.ResolveIdentityState(ctx, did)
+		if err != nil {
+			t.Fatalf("Error resolving state: %v", err)
+		}
+
+		if identityState.Info.CreatedAtTimestamp != "1005" || identityState.Info.ID != publishedDid.String() {
+			t.Errorf("Unexpected state info %+v", identityState.Info)
+		}
+	})
+	t.Run("Should accept genesis state and reject unknown one", func(t *testing.T) {
+		genesisState, _ := toHashHex(newGenesisState(2))
+		did, _ := w3c.ParseDID(genesisDid.String() + "?state=" + genesisState)
+
+		identityState, err := didResolver.ResolveIdentityState(ctx, did)
+		if err != nil {
+			t.Fatalf("Error resolving genesis state: %v", err)
+		}
+
+		if *identityState.Published || identityState.Info.State != genesisState {
+			t.Errorf("Unexpected genesis state %+v", identityState.Info)
+		}
+
+		unknownState, _ := toHashHex(newGenesisState(3))
+		did, _ = w3c.ParseDID(genesisDid.String() + "?state=" + unknownState)
+
+		if _, err := didResolver.ResolveIdentityState(ctx, did); !errors.Is(err, ErrStateNotFound) {
+			t.Errorf("Expected state not found, got %v", err)
+		}
+	})
+}
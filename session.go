@@ -0,0 +1,248 @@
+package zkp_iden3_exposer
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/evm"
+	"github.com/rarimo/zkp-iden3-exposer/evm/backend"
+	"github.com/rarimo/zkp-iden3-exposer/progress"
+	"github.com/rarimo/zkp-iden3-exposer/transport"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/helpers"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/instances"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// session Keeps what is expensive to rebuild between the connector calls. Every entry remembers
+// the config it was built from and is rebuilt as soon as the connector fields change
+type session struct {
+	mutex sync.Mutex
+
+	identityKey string
+	identity    *instances.Identity
+	gistProofs  *helpers.GISTProofCache
+
+	evmClientKey string
+	evmClient    *evm.Client
+
+	grpcKey  string
+	grpcConn *grpc.ClientConn
+
+	backendsHttpClient *http.Client
+	backends           *backend.Pool
+}
+
+func newSession() *session {
+	return &session{
+		gistProofs: helpers.NewGISTProofCache(helpers.DefaultGISTProofLatestTTL, helpers.DefaultGISTProofCapacity),
+	}
+}
+
+// sessionMutex guards the lazy creation of the connector sessions, the connectors are plain structs
+// the apps may build without the constructor
+var sessionMutex sync.Mutex
+
+func (c *Connector) getSession() *session {
+	sessionMutex.Lock()
+	defer sessionMutex.Unlock()
+
+	if c.session == nil {
+		c.session = newSession()
+	}
+
+	return c.session
+}
+
+func sessionKey(parts ...string) string {
+	return strings.Join(parts, "|")
+}
+
+func (c *Connector) identitySessionKey() string {
+	return sessionKey(
+		c.PkHex,
+		hex.EncodeToString(c.IdType),
+		c.SchemaHashHex,
+		strconv.Itoa(c.TargetChainId),
+		c.TargetRpcUrl,
+		c.TargetStateContractAddress,
+		c.CoreApiUrl,
+		fmt.Sprintf("%p", c.httpClient),
+		c.CoreEvmRpcApiUrl,
+		c.CoreStateContractAddress,
+	)
+}
+
+func (c *Connector) getIdentity() (*instances.Identity, error) {
+	s := c.getSession()
+	key := c.identitySessionKey()
+	backends := c.getBackends()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.identity != nil && s.identityKey == key {
+		return s.identity, nil
+	}
+
+	identityConfig, err := c.getIdentityConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	done := progress.Track(c.context(), progress.StepIdentity)
+	identity, err := getIdentityInstance(*identityConfig)
+	done(err)
+	if err != nil {
+		return nil, err
+	}
+
+	s.setIdentity(key, identity, c.httpClient, backends)
+
+	return identity, nil
+}
+
+// restoreIdentity caches the identity restored from the exported state in the session
+func (c *Connector) restoreIdentity(identity *instances.Identity) {
+	s := c.getSession()
+	key := c.identitySessionKey()
+	backends := c.getBackends()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.setIdentity(key, identity, c.httpClient, backends)
+}
+
+// setIdentity must be called with the session mutex held
+func (s *session) setIdentity(key string, identity *instances.Identity, httpClient *http.Client, backends *backend.Pool) {
+	if s.identityKey != key {
+		s.gistProofs.Clear()
+	}
+
+	identity.GISTProofs = s.gistProofs
+	identity.HttpClient = httpClient
+	identity.Backends = backends
+
+	s.identityKey = key
+	s.identity = identity
+}
+
+func (c *Connector) getEvmClient() (*evm.Client, error) {
+	s := c.getSession()
+	key := sessionKey(c.PkHex, strconv.Itoa(c.TargetChainId), c.TargetRpcUrl, fmt.Sprintf("%p", c.httpClient))
+	backends := c.getBackends()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.evmClient != nil && s.evmClientKey == key {
+		return s.evmClient, nil
+	}
+
+	targetBackend, err := backends.Get(c.TargetRpcUrl)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting target chain backend")
+	}
+
+	evmClient, err := evm.NewClient(targetBackend, big.NewInt(int64(c.TargetChainId)), c.PkHex)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating evm client")
+	}
+
+	s.evmClientKey = key
+	s.evmClient = evmClient
+
+	return evmClient, nil
+}
+
+func (c *Connector) getGrpcConn() (*grpc.ClientConn, error) {
+	s := c.getSession()
+	key := sessionKey(c.RpcApi, strconv.FormatBool(c.IsTLS), fmt.Sprintf("%p", c.httpClient))
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.grpcConn != nil && s.grpcKey == key {
+		return s.grpcConn, nil
+	}
+
+	if s.grpcConn != nil {
+		if err := s.grpcConn.Close(); err != nil {
+			return nil, errors.Wrap(err, "Error closing previous grpc connection")
+		}
+
+		s.grpcConn = nil
+	}
+
+	// the connection follows the same TLS, proxy and user agent policy as the HTTP requests
+	dialOptions, err := transport.GrpcDialOptions(c.getHttpClient(), c.IsTLS)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error applying http transport to grpc")
+	}
+
+	grpcConn, err := grpc.Dial(
+		c.RpcApi,
+		append(dialOptions, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    10 * time.Second, // wait time before ping if no activity
+			Timeout: 20 * time.Second, // ping timeout
+		}))...,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error dialing grpc")
+	}
+
+	s.grpcKey = key
+	s.grpcConn = grpcConn
+
+	return grpcConn, nil
+}
+
+// InvalidateSession drops the cached identity, clients and GIST proofs, they are rebuilt on the next call.
+// Changing the connector fields invalidates the affected entries on its own
+func (c *Connector) InvalidateSession() error {
+	return c.Close()
+}
+
+// Close releases the gRPC connection and drops everything cached by the connector,
+// the connector stays usable and reconnects on demand
+func (c *Connector) Close() error {
+	sessionMutex.Lock()
+	s := c.session
+	c.session = nil
+	sessionMutex.Unlock()
+
+	if s == nil {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// the connectors bound with WithContext share the session, leave nothing stale for them
+	s.gistProofs.Clear()
+	s.identity = nil
+	s.evmClient = nil
+
+	if s.backends != nil {
+		s.backends.Close()
+		s.backends = nil
+	}
+
+	if s.grpcConn != nil {
+		grpcConn := s.grpcConn
+		s.grpcConn = nil
+
+		if err := grpcConn.Close(); err != nil {
+			return errors.Wrap(err, "Error closing grpc connection")
+		}
+	}
+
+	return nil
+}
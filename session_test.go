@@ -0,0 +1,169 @@
+package zkp_iden3_exposer
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestSession(t *testing.T) {
+	connector, err := NewConnectorFromNetwork(
+		"local",
+		"1cbd5d2d1801e964736881fc0584473f23ba82669599ac65957fb4f2caf43e17",
+		[]byte(localOverrides),
+	)
+	if err != nil {
+		t.Fatalf("Error creating connector: %v", err)
+	}
+
+	t.Run("Should reuse the identity", func(t *testing.T) {
+		first, err := connector.getIdentity()
+		if err != nil {
+			t.Fatalf("Error getting identity: %v", err)
+		}
+
+		second, err := connector.getIdentity()
+		if err != nil {
+			t.Fatalf("Error getting identity: %v", err)
+		}
+
+		if first != second {
+			t.Errorf("Expected the cached identity")
+		}
+
+		if first.GISTProofs == nil {
+			t.Errorf("Expected the identity to share the session GIST proofs")
+		}
+	})
+	t.Run("Should rebuild the identity when config changes", func(t *testing.T) {
+		first, err := connector.getIdentity()
+		if err != nil {
+			t.Fatalf("Error getting identity: %v", err)
+		}
+
+		connector.PkHex = "2cbd5d2d1801e964736881fc0584473f23ba82669599ac65957fb4f2caf43e17"
+
+		second, err := connector.getIdentity()
+		if err != nil {
+			t.Fatalf("Error getting identity: %v", err)
+		}
+
+		if first == second || first.DID.String() == second.DID.String() {
+			t.Errorf("Expected the identity of the new key")
+		}
+	})
+	t.Run("Should reuse the grpc connection until the endpoint changes", func(t *testing.T) {
+		first, err := connector.getGrpcConn()
+		if err != nil {
+			t.Fatalf("Error getting grpc connection: %v", err)
+		}
+
+		second, err := connector.getGrpcConn()
+		if err != nil {
+			t.Fatalf("Error getting grpc connection: %v", err)
+		}
+
+		if first != second {
+			t.Errorf("Expected the cached grpc connection")
+		}
+
+		connector.RpcApi = "localhost:9091"
+
+		third, err := connector.getGrpcConn()
+		if err != nil {
+			t.Fatalf("Error getting grpc connection: %v", err)
+		}
+
+		if third == first {
+			t.Errorf("Expected a new grpc connection")
+		}
+	})
+	t.Run("Should drop everything on close", func(t *testing.T) {
+		first, err := connector.getIdentity()
+		if err != nil {
+			t.Fatalf("Error getting identity: %v", err)
+		}
+
+		if err := connector.Close(); err != nil {
+			t.Fatalf("Error closing connector: %v", err)
+		}
+
+		if connector.session != nil {
+			t.Errorf("Expected the session to be dropped")
+		}
+
+		second, err := connector.getIdentity()
+		if err != nil {
+			t.Fatalf("Error getting identity: %v", err)
+		}
+
+		if first == second {
+			t.Errorf("Expected a new identity after close")
+		}
+
+		if err := connector.Close(); err != nil {
+			t.Fatalf("Error closing connector: %v", err)
+		}
+	})
+}
+
+// firstByte accepts one connection and returns the first byte the client sends, 0x16 starts the TLS handshake
+func firstByte(t *testing.T, listener net.Listener) byte {
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("Error accepting connection: %v", err)
+	}
+	defer conn.Close()
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	buffer := make([]byte, 1)
+	if _, err := conn.Read(buffer); err != nil {
+		t.Fatalf("Error reading from connection: %v", err)
+	}
+
+	return buffer[0]
+}
+
+func TestGrpcTransportCredentials(t *testing.T) {
+	for _, c := range []struct {
+		name  string
+		isTLS bool
+		first byte
+	}{
+		{"Should dial with TLS when enabled", true, 0x16},
+		{"Should dial in plaintext when disabled", false, 'P'},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("Error listening: %v", err)
+			}
+			defer listener.Close()
+
+			connector, err := NewConnectorFromNetwork(
+				"local",
+				"1cbd5d2d1801e964736881fc0584473f23ba82669599ac65957fb4f2caf43e17",
+				[]byte(`{"rpcApi":"`+listener.Addr().String()+`","chainInfo":{"coreStateContractAddress":"0x0000000000000000000000000000000000000001"}}`),
+			)
+			if err != nil {
+				t.Fatalf("Error creating connector: %v", err)
+			}
+			defer connector.Close()
+
+			connector.IsTLS = c.isTLS
+
+			conn, err := connector.getGrpcConn()
+			if err != nil {
+				t.Fatalf("Error getting grpc connection: %v", err)
+			}
+
+			conn.Connect()
+
+			// the plaintext HTTP/2 connection starts with the "PRI * HTTP/2.0" preface
+			if first := firstByte(t, listener); first != c.first {
+				t.Errorf("Expected the connection to start with %#x, got %#x", c.first, first)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package zkp_iden3_exposer
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/instances"
+	"time"
+)
+
+// stateVersion Version of the exported state format, bumped on the incompatible changes
+const stateVersion = 1
+
+type connectorState struct {
+	Version int             `json:"version"`
+	Config  json.RawMessage `json:"config"`
+
+	GISTReferenceKind    string `json:"gistReferenceKind,omitempty"`
+	GISTReferenceValue   string `json:"gistReferenceValue,omitempty"`
+	OperationWaitSeconds int    `json:"operationWaitSeconds,omitempty"`
+
+	Identity *instances.IdentityState `json:"identity,omitempty"`
+}
+
+// ExportState serialises the config of the connector, its GIST reference and operation wait settings
+// and the identity, so NewConnectorFromState skips rebuilding it. The state contains the private key
+// and belongs to the secure storage of the app. The listeners, the prover and the http transport
+// are not exported
+func (c *Connector) ExportState() (_ []byte, err error) {
+	defer withCode(&err)
+
+	configJson, err := json.Marshal(c)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error marshalling connector config")
+	}
+
+	identity, err := c.getIdentity()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting identity")
+	}
+
+	identityState := identity.State()
+
+	state := connectorState{
+		Version:              stateVersion,
+		Config:               configJson,
+		GISTReferenceKind:    c.gistReference.kind,
+		OperationWaitSeconds: int(c.operationWait.Timeout / time.Second),
+		Identity:             &identityState,
+	}
+
+	if c.gistReference.value != nil {
+		state.GISTReferenceValue = c.gistReference.value.String()
+	}
+
+	stateJson, err := json.Marshal(state)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error marshalling connector state")
+	}
+
+	return stateJson, nil
+}
+
+// NewConnectorFromState restores the connector exported with ExportState, the config is validated
+// the same way NewConnectorFromJSON does and the identity is checked to belong to its key
+func NewConnectorFromState(stateJson []byte) (_ *Connector, err error) {
+	defer withCode(&err)
+
+	state := connectorState{}
+	if err := decodeStrict(stateJson, &state); err != nil {
+		return nil, errcode.Wrap(err, errcode.InvalidConfig, "Error decoding connector state")
+	}
+
+	if state.Version != stateVersion {
+		return nil, errcode.New(errcode.InvalidConfig, "Unsupported connector state version")
+	}
+
+	connector, err := NewConnectorFromJSON(state.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := connector.SetGISTReference(state.GISTReferenceKind, state.GISTReferenceValue); err != nil {
+		return nil, err
+	}
+
+	connector.SetOperationWait(state.OperationWaitSeconds, nil)
+
+	if state.Identity == nil {
+		return connector, nil
+	}
+
+	identityConfig, err := connector.getIdentityConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := instances.RestoreIdentity(
+		toInstanceIdentityConfig(*identityConfig),
+		identityConfig.PkHex,
+		*state.Identity,
+	)
+	if err != nil {
+		return nil, errcode.Wrap(err, errcode.InvalidConfig, "Error restoring identity")
+	}
+
+	connector.restoreIdentity(identity)
+
+	return connector, nil
+}
@@ -0,0 +1,77 @@
+package zkp_iden3_exposer
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestConnectorState(t *testing.T) {
+	connector, err := NewConnectorFromJSON(getConfigJson(t, nil))
+	if err != nil {
+		t.Fatalf("Error creating connector: %v", err)
+	}
+
+	if err := connector.SetGISTReference(GISTReferenceBlock, "12345"); err != nil {
+		t.Fatalf("Error setting GIST reference: %v", err)
+	}
+
+	connector.SetOperationWait(90, nil)
+
+	stateJson, err := connector.ExportState()
+	if err != nil {
+		t.Fatalf("Error exporting state: %v", err)
+	}
+
+	t.Run("Should restore connector from state", func(t *testing.T) {
+		restored, err := NewConnectorFromState(stateJson)
+		if err != nil {
+			t.Fatalf("Error restoring state: %v", err)
+		}
+
+		if restored.gistReference.kind != GISTReferenceBlock || restored.gistReference.value.Int64() != 12345 {
+			t.Errorf("Unexpected GIST reference %+v", restored.gistReference)
+		}
+
+		if restored.operationWait.Timeout.Seconds() != 90 {
+			t.Errorf("Unexpected operation wait %v", restored.operationWait.Timeout)
+		}
+
+		if restored.session == nil || restored.session.identity == nil {
+			t.Fatalf("Expected identity to be restored into the session")
+		}
+
+		expectedDid, _ := connector.GetDidString()
+		restoredDid, err := restored.GetDidString()
+		if err != nil {
+			t.Fatalf("Error getting DID: %v", err)
+		}
+
+		if restoredDid != expectedDid {
+			t.Errorf("Expected %s, got %s", expectedDid, restoredDid)
+		}
+	})
+
+	t.Run("Should reject identity of another key", func(t *testing.T) {
+		state := map[string]json.RawMessage{}
+		if err := json.Unmarshal(stateJson, &state); err != nil {
+			t.Fatalf("Error unmarshalling state: %v", err)
+		}
+
+		state["config"] = getConfigJson(t, map[string]interface{}{
+			"pkHex": "9a5305fa4c55cbf517c99693a7ec6766203c88feab50c944c00feec051d5dab7",
+		})
+
+		tampered, _ := json.Marshal(state)
+
+		_, err := NewConnectorFromState(tampered)
+		if err == nil || GetErrorCode(err.Error()) != ErrorCodeInvalidConfig {
+			t.Errorf("Expected %s, got %v", ErrorCodeInvalidConfig, err)
+		}
+	})
+
+	t.Run("Should reject unknown state version", func(t *testing.T) {
+		if _, err := NewConnectorFromState([]byte(`{"version":2,"config":{}}`)); err == nil {
+			t.Errorf("Expected error")
+		}
+	})
+}
@@ -0,0 +1,33 @@
+package zkp_iden3_exposer
+
+import (
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"strings"
+	"testing"
+)
+
+func TestSubmitZKPResponse(t *testing.T) {
+	connector, err := NewConnectorFromNetwork(
+		"local",
+		"1cbd5d2d1801e964736881fc0584473f23ba82669599ac65957fb4f2caf43e17",
+		[]byte(localOverrides),
+	)
+	if err != nil {
+		t.Fatalf("Error creating connector: %v", err)
+	}
+
+	t.Run("Should reject malformed verifier address", func(t *testing.T) {
+		for _, address := range []string{"0x01", "8a9F505bD8a22BF09b0c19F65C17426cd33f3912", "0x8a9f505bD8a22BF09b0c19F65C17426cd33f3912"} {
+			_, err := connector.SubmitZKPResponse(address, 1, []byte(`{}`))
+			if errcode.Of(err) != errcode.InvalidInput || !strings.Contains(err.Error(), "verifierAddress") {
+				t.Errorf("Expected %s for %s, got %v", ErrorCodeInvalidInput, address, err)
+			}
+		}
+	})
+	t.Run("Should accept checksummed verifier address", func(t *testing.T) {
+		_, err := connector.SubmitZKPResponse("0x8a9F505bD8a22BF09b0c19F65C17426cd33f3912", 1, []byte("not json"))
+		if err == nil || strings.Contains(err.Error(), "verifierAddress") {
+			t.Errorf("Expected the proof to be rejected instead of the address, got %v", err)
+		}
+	})
+}
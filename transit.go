@@ -0,0 +1,249 @@
+package zkp_iden3_exposer
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/coreapi"
+	"github.com/rarimo/zkp-iden3-exposer/evm/watcher"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/contracts"
+	"math/big"
+	"strings"
+)
+
+// TransitListener receives the states transited to the target chain. OnIssuerStateCovered fires once
+// the watched issuer state can be proven on the target chain, the watch stops after it or OnError
+type TransitListener interface {
+	OnTransit(gistRoot string, identitiesStatesRoot string, blockNumber int64)
+	OnIssuerStateCovered(gistRoot string)
+	OnError(message string)
+}
+
+// TransitWatch Handle of the running transit watch
+type TransitWatch struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Stop cancels the watch and waits for it to exit
+func (w *TransitWatch) Stop() {
+	w.cancel()
+	<-w.done
+}
+
+// WatchIssuerStateTransit notifies the listener when the latest state of the issuer reaches
+// the LightweightStateV2 on the target chain
+func (c *Connector) WatchIssuerStateTransit(issuerDid string, listener TransitListener) (_ *TransitWatch, err error) {
+	defer withCode(&err)
+
+	_, operation, gistRoot, err := c.getIssuerStateOperation(issuerDid)
+	if err != nil {
+		return nil, err
+	}
+
+	targetBackend, err := c.getBackends().Get(c.TargetRpcUrl)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting target chain backend")
+	}
+
+	ctx, cancel := context.WithCancel(c.context())
+	watch := &TransitWatch{cancel: cancel, done: make(chan struct{})}
+
+	coverage, err := newStateCoverage(c.TargetStateContractAddress, targetBackend, operation, gistRoot)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// the head is read before the check, so a transit landing in between is still scanned by the watcher
+	fromBlock, err := targetBackend.BlockNumber(ctx)
+	if err != nil {
+		cancel()
+		return nil, errors.Wrap(err, "Error getting target block number")
+	}
+
+	targetRoot, covered, err := coverage.latest(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	if covered {
+		close(watch.done)
+		listener.OnIssuerStateCovered(hexutil.Encode(common.LeftPadBytes(targetRoot.Bytes(), 32)))
+
+		return watch, nil
+	}
+
+	transitWatcher, err := watcher.NewWatcher(targetBackend, common.HexToAddress(c.TargetStateContractAddress), watcher.Options{
+		FromBlock: fromBlock,
+	})
+	if err != nil {
+		cancel()
+		return nil, errors.Wrap(err, "Error creating transit watcher")
+	}
+
+	go func() {
+		defer close(watch.done)
+
+		err := transitWatcher.Watch(ctx, func(transit watcher.Transit) bool {
+			transitRoot := hexutil.Encode(common.LeftPadBytes(transit.GISTRoot.Bytes(), 32))
+
+			listener.OnTransit(transitRoot, transit.IdentitiesStatesRoot.Hex(), int64(transit.BlockNumber))
+
+			covered, err := coverage.covers(ctx, transit.GISTRoot)
+			if err != nil {
+				listener.OnError(err.Error())
+				return true
+			}
+
+			if !covered {
+				return false
+			}
+
+			listener.OnIssuerStateCovered(transitRoot)
+
+			return true
+		})
+		if err != nil && ctx.Err() == nil {
+			listener.OnError(err.Error())
+		}
+	}()
+
+	return watch, nil
+}
+
+// stateContractSync Sync status of the latest issuer state, Synced means the latest GIST root of the target chain
+// covers it, see stateCoverage
+type stateContractSync struct {
+	IssuerState     string `json:"issuerState"`
+	OperationIndex  string `json:"operationIndex"`
+	OperationStatus string `json:"operationStatus"`
+	GISTRoot        string `json:"gistRoot"`
+	TargetGISTRoot  string `json:"targetGistRoot"`
+	Signed          bool   `json:"signed"`
+	Synced          bool   `json:"synced"`
+}
+
+// CheckStateContractSync reports whether the latest state of the issuer is signed on the core and transited
+// to the LightweightStateV2 on the target chain, so the on-chain proofs against it are accepted right away
+func (c *Connector) CheckStateContractSync(issuerDid string) (_ []byte, err error) {
+	defer withCode(&err)
+
+	issuerState, operation, gistRoot, err := c.getIssuerStateOperation(issuerDid)
+	if err != nil {
+		return nil, err
+	}
+
+	targetBackend, err := c.getBackends().Get(c.TargetRpcUrl)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting target chain backend")
+	}
+
+	coverage, err := newStateCoverage(c.TargetStateContractAddress, targetBackend, operation, gistRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	targetRoot, covered, err := coverage.latest(c.context())
+	if err != nil {
+		return nil, err
+	}
+
+	syncJson, err := json.Marshal(stateContractSync{
+		IssuerState:     issuerState.Hash,
+		OperationIndex:  operation.Index,
+		OperationStatus: string(operation.Status),
+		GISTRoot:        operation.Details.GISTHash,
+		TargetGISTRoot:  hexutil.Encode(common.LeftPadBytes(targetRoot.Bytes(), 32)),
+		Signed:          operation.Status == coreapi.OperationStatusSigned,
+		Synced:          covered,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Error marshalling sync status")
+	}
+
+	return syncJson, nil
+}
+
+// stateCoverage decides whether a GIST root on the target chain covers the issuer state the way the
+// LightweightStateV2 does: the roots are transited in order, so any root created at or after the operation has the state
+type stateCoverage struct {
+	caller    *contracts.LightweightStateV2Caller
+	gistRoot  *big.Int
+	timestamp *big.Int
+}
+
+func newStateCoverage(targetStateContractAddress string, targetBackend bind.ContractCaller, operation *coreapi.Operation, gistRoot *big.Int) (*stateCoverage, error) {
+	timestamp, ok := new(big.Int).SetString(operation.Details.Timestamp, 10)
+	if !ok {
+		return nil, errors.Errorf("Invalid operation timestamp %q", operation.Details.Timestamp)
+	}
+
+	caller, err := contracts.NewLightweightStateV2Caller(common.HexToAddress(targetStateContractAddress), targetBackend)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating lightweight state caller")
+	}
+
+	return &stateCoverage{caller: caller, gistRoot: gistRoot, timestamp: timestamp}, nil
+}
+
+// covers reports whether the target root covers the state, the roots unknown to the target do not
+func (s *stateCoverage) covers(ctx context.Context, root *big.Int) (bool, error) {
+	rootInfo, err := s.caller.GetGISTRootInfo(&bind.CallOpts{Context: ctx}, root)
+	if err != nil {
+		return false, errors.Wrap(err, "Error getting target GIST root info")
+	}
+
+	if rootInfo.CreatedAtTimestamp.Sign() == 0 {
+		return false, nil
+	}
+
+	return root.Cmp(s.gistRoot) == 0 || rootInfo.CreatedAtTimestamp.Cmp(s.timestamp) >= 0, nil
+}
+
+// latest returns the current target root and whether it covers the state
+func (s *stateCoverage) latest(ctx context.Context) (*big.Int, bool, error) {
+	targetRoot, err := s.caller.GetGISTRoot(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, false, errors.Wrap(err, "Error getting target GIST root")
+	}
+
+	covered, err := s.covers(ctx, targetRoot)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return targetRoot, covered, nil
+}
+
+// getIssuerStateOperation returns the latest state of the issuer with the operation that transfers it
+// and the GIST root the operation carries
+func (c *Connector) getIssuerStateOperation(issuerDid string) (*coreapi.StateInfo, *coreapi.Operation, *big.Int, error) {
+	issuerId, err := getIssuerId(issuerDid)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "Error getting issuer ID")
+	}
+
+	coreApi := coreapi.NewClient(c.CoreApiUrl, c.getHttpClient())
+
+	issuerState, err := coreApi.GetState(c.context(), hexutil.Encode(issuerId.Bytes()))
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "Error getting issuer state")
+	}
+
+	operation, err := coreApi.GetOperation(c.context(), issuerState.LastUpdateOperationIndex)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "Error getting issuer state operation")
+	}
+
+	gistRoot, ok := new(big.Int).SetString(strings.TrimPrefix(operation.Details.GISTHash, "0x"), 16)
+	if !ok {
+		return nil, nil, nil, errors.Errorf("Invalid operation GIST hash %q", operation.Details.GISTHash)
+	}
+
+	return issuerState, operation, gistRoot, nil
+}
@@ -0,0 +1,227 @@
+package zkp_iden3_exposer
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/coreapi"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/contracts"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// mockLightweightState serves the roots transited to the target chain with their creation timestamps
+type mockLightweightState struct {
+	abi        *abi.ABI
+	targetRoot *big.Int
+	roots      map[string]int64
+}
+
+func (m *mockLightweightState) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{0x00}, nil
+}
+
+func (m *mockLightweightState) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	method, err := m.abi.MethodById(call.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+
+	args, err := method.Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+
+	switch method.Name {
+	case "getGISTRoot":
+		return method.Outputs.Pack(m.targetRoot)
+	case "getGISTRootInfo":
+		root := args[0].(*big.Int)
+
+		return method.Outputs.Pack(contracts.ILightweightStateV2GistRootData{
+			Root:               root,
+			CreatedAtTimestamp: big.NewInt(m.roots[root.String()]),
+		})
+	}
+
+	return nil, errors.Errorf("unexpected call %s", method.Name)
+}
+
+// mockEthService serves the eth_call of the target chain JSON-RPC from the mock state
+type mockEthService struct {
+	target *mockLightweightState
+}
+
+func (s *mockEthService) Call(args struct {
+	To    *common.Address `json:"to"`
+	Input hexutil.Bytes   `json:"input"`
+}, block string) (hexutil.Bytes, error) {
+	return s.target.CallContract(context.Background(), ethereum.CallMsg{To: args.To, Data: args.Input}, nil)
+}
+
+// newSyncConnector returns the connector reading the issuer operation from the mock core API
+// and the roots from the mock target chain
+func newSyncConnector(t *testing.T, gistHash string, target *mockLightweightState) *Connector {
+	core := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/rarimo/rarimo-core/identity/state/"):
+			_, _ = w.Write([]byte(`{"state":{"index":"0x01","hash":"0x0a","lastUpdateOperationIndex":"0xop"}}`))
+		case r.URL.Path == "/rarimo/rarimo-core/rarimocore/operation/0xop":
+			_, _ = w.Write([]byte(`{"operation":{"index":"0xop","status":"SIGNED","details":{"GISTHash":"` + gistHash + `","timestamp":"1000"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(core.Close)
+
+	rpcServer := rpc.NewServer()
+	if err := rpcServer.RegisterName("eth", &mockEthService{target: target}); err != nil {
+		t.Fatalf("Error registering eth service: %v", err)
+	}
+	t.Cleanup(rpcServer.Stop)
+
+	targetRpc := httptest.NewServer(rpcServer)
+	t.Cleanup(targetRpc.Close)
+
+	connector, err := NewConnectorFromNetwork(
+		"local",
+		"1cbd5d2d1801e964736881fc0584473f23ba82669599ac65957fb4f2caf43e17",
+		[]byte(localOverrides),
+	)
+	if err != nil {
+		t.Fatalf("Error creating connector: %v", err)
+	}
+
+	connector.CoreApiUrl = core.URL
+	connector.TargetRpcUrl = targetRpc.URL
+	connector.TargetStateContractAddress = "0x0000000000000000000000000000000000000002"
+	// the connector owned backends keep the mock endpoint out of the default pool
+	connector.SetHttpClient(&http.Client{})
+
+	return connector
+}
+
+func TestCheckStateContractSync(t *testing.T) {
+	targetAbi, err := contracts.LightweightStateV2MetaData.GetAbi()
+	if err != nil {
+		t.Fatalf("Error parsing lightweight state abi: %v", err)
+	}
+
+	issuerDid := "did:iden3:readonly:tSpQ56dBXo3Druez8wAbTTqd9yV1K2q4TwFu2taQj"
+
+	checkSync := func(t *testing.T, connector *Connector) stateContractSync {
+		syncJson, err := connector.CheckStateContractSync(issuerDid)
+		if err != nil {
+			t.Fatalf("Error checking sync: %v", err)
+		}
+
+		sync := stateContractSync{}
+		if err := json.Unmarshal(syncJson, &sync); err != nil {
+			t.Fatalf("Error unmarshalling sync: %v", err)
+		}
+
+		return sync
+	}
+
+	t.Run("Should be synced by a later root", func(t *testing.T) {
+		target := &mockLightweightState{abi: targetAbi, targetRoot: big.NewInt(700), roots: map[string]int64{"700": 1200}}
+		sync := checkSync(t, newSyncConnector(t, "0x01f4", target))
+
+		if !sync.Signed || !sync.Synced || sync.IssuerState != "0x0a" || sync.OperationIndex != "0xop" {
+			t.Errorf("Unexpected sync status %+v", sync)
+		}
+
+		if sync.TargetGISTRoot != hexutil.Encode(common.LeftPadBytes(big.NewInt(700).Bytes(), 32)) {
+			t.Errorf("Unexpected target root %s", sync.TargetGISTRoot)
+		}
+	})
+	t.Run("Should not be synced by an earlier root", func(t *testing.T) {
+		target := &mockLightweightState{abi: targetAbi, targetRoot: big.NewInt(400), roots: map[string]int64{"400": 999}}
+
+		if sync := checkSync(t, newSyncConnector(t, "0x01f4", target)); sync.Synced {
+			t.Errorf("Expected not synced, got %+v", sync)
+		}
+	})
+	t.Run("Should reject invalid operation GIST hash", func(t *testing.T) {
+		connector := newSyncConnector(t, "0xzz", &mockLightweightState{abi: targetAbi})
+
+		if _, _, _, err := connector.getIssuerStateOperation(issuerDid); err == nil {
+			t.Errorf("Expected error for invalid GIST hash")
+		}
+	})
+	t.Run("Should reject invalid issuer DID", func(t *testing.T) {
+		connector := newSyncConnector(t, "0x01f4", &mockLightweightState{abi: targetAbi})
+
+		if _, err := connector.CheckStateContractSync("did:iden3:invalid"); err == nil {
+			t.Errorf("Expected error for invalid DID")
+		}
+	})
+}
+
+func TestStateCoverage(t *testing.T) {
+	targetAbi, err := contracts.LightweightStateV2MetaData.GetAbi()
+	if err != nil {
+		t.Fatalf("Error parsing lightweight state abi: %v", err)
+	}
+
+	ctx := context.Background()
+	operation := &coreapi.Operation{Details: coreapi.OperationDetails{Timestamp: "1000"}}
+	gistRoot := big.NewInt(500)
+
+	newCoverage := func(t *testing.T, target *mockLightweightState) *stateCoverage {
+		coverage, err := newStateCoverage("0x02", target, operation, gistRoot)
+		if err != nil {
+			t.Fatalf("Error creating coverage: %v", err)
+		}
+
+		return coverage
+	}
+
+	t.Run("Should cover by the operation GIST root", func(t *testing.T) {
+		coverage := newCoverage(t, &mockLightweightState{abi: targetAbi, roots: map[string]int64{"500": 1000}})
+
+		if covered, err := coverage.covers(ctx, gistRoot); err != nil || !covered {
+			t.Errorf("Expected covered, got %v: %v", covered, err)
+		}
+	})
+	t.Run("Should cover by a later root", func(t *testing.T) {
+		target := &mockLightweightState{abi: targetAbi, targetRoot: big.NewInt(700), roots: map[string]int64{"600": 1000, "700": 1200}}
+		coverage := newCoverage(t, target)
+
+		if covered, err := coverage.covers(ctx, big.NewInt(600)); err != nil || !covered {
+			t.Errorf("Expected covered at the operation time, got %v: %v", covered, err)
+		}
+
+		root, covered, err := coverage.latest(ctx)
+		if err != nil || !covered || root.Int64() != 700 {
+			t.Errorf("Expected latest root 700 covered, got %v %v: %v", root, covered, err)
+		}
+	})
+	t.Run("Should not cover by an earlier or unknown root", func(t *testing.T) {
+		target := &mockLightweightState{abi: targetAbi, targetRoot: big.NewInt(400), roots: map[string]int64{"400": 999}}
+		coverage := newCoverage(t, target)
+
+		if _, covered, err := coverage.latest(ctx); err != nil || covered {
+			t.Errorf("Expected earlier root not covered, got %v: %v", covered, err)
+		}
+
+		if covered, err := coverage.covers(ctx, big.NewInt(900)); err != nil || covered {
+			t.Errorf("Expected unknown root not covered, got %v: %v", covered, err)
+		}
+	})
+	t.Run("Should reject invalid operation timestamp", func(t *testing.T) {
+		invalid := &coreapi.Operation{Details: coreapi.OperationDetails{Timestamp: "soon"}}
+
+		if _, err := newStateCoverage("0x02", &mockLightweightState{abi: targetAbi}, invalid, gistRoot); err == nil {
+			t.Errorf("Expected error for invalid timestamp")
+		}
+	})
+}
@@ -0,0 +1,181 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+var ErrUnsupportedTransport = errcode.New(
+	errcode.InvalidConfig,
+	"grpc can only reuse the http.Transport or the client built by NewClient",
+)
+
+// GrpcDialOptions returns the dial options applying the policy of the HTTP client to the grpc connection:
+// the TLS config with the root CAs and the pins, the dialer, the proxy and the user agent.
+// The policy of the custom round trippers can not be read, so they are rejected
+func GrpcDialOptions(client *http.Client, isTLS bool) ([]grpc.DialOption, error) {
+	base, userAgent, err := unwrapTransport(client)
+	if err != nil {
+		return nil, err
+	}
+
+	transportCredentials := insecure.NewCredentials()
+
+	if isTLS {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		if base.TLSClientConfig != nil {
+			tlsConfig = base.TLSClientConfig.Clone()
+		}
+
+		// the HTTP transport adds its own protocols, grpc negotiates h2 itself
+		tlsConfig.NextProtos = nil
+
+		transportCredentials = credentials.NewTLS(tlsConfig)
+	}
+
+	options := []grpc.DialOption{
+		grpc.WithTransportCredentials(transportCredentials),
+		grpc.WithContextDialer(newGrpcDialer(base, userAgent, isTLS)),
+	}
+
+	if userAgent != "" {
+		options = append(options, grpc.WithUserAgent(userAgent))
+	}
+
+	return options, nil
+}
+
+func unwrapTransport(client *http.Client) (*http.Transport, string, error) {
+	roundTripper := http.DefaultTransport
+	if client != nil && client.Transport != nil {
+		roundTripper = client.Transport
+	}
+
+	userAgent := ""
+	if hooks, ok := roundTripper.(*hooksTransport); ok {
+		roundTripper = hooks.base
+		userAgent = hooks.userAgent
+	}
+
+	base, ok := roundTripper.(*http.Transport)
+	if !ok {
+		return nil, "", errors.Wrapf(ErrUnsupportedTransport, "got %T", roundTripper)
+	}
+
+	return base, userAgent, nil
+}
+
+// newGrpcDialer dials the address with the dialer of the transport, through the HTTP CONNECT tunnel
+// when the transport proxies the address. grpc skips its own proxy handling for the custom dialers
+func newGrpcDialer(base *http.Transport, userAgent string, isTLS bool) func(ctx context.Context, address string) (net.Conn, error) {
+	dial := base.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: DefaultDialTimeout}).DialContext
+	}
+
+	scheme := "http"
+	if isTLS {
+		scheme = "https"
+	}
+
+	return func(ctx context.Context, address string) (net.Conn, error) {
+		var proxyUrl *url.URL
+
+		if base.Proxy != nil {
+			var err error
+
+			proxyUrl, err = base.Proxy(&http.Request{URL: &url.URL{Scheme: scheme, Host: address}})
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to get proxy")
+			}
+		}
+
+		if proxyUrl == nil {
+			return dial(ctx, "tcp", address)
+		}
+
+		if proxyUrl.Scheme != "http" {
+			return nil, errors.Wrapf(ErrUnsupportedTransport, "grpc supports only http proxies, got %s", proxyUrl.Scheme)
+		}
+
+		conn, err := dial(ctx, "tcp", proxyUrl.Host)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to dial proxy")
+		}
+
+		tunnel, err := connectTunnel(ctx, conn, proxyUrl, address, userAgent)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+
+		return tunnel, nil
+	}
+}
+
+func connectTunnel(ctx context.Context, conn net.Conn, proxyUrl *url.URL, address string, userAgent string) (net.Conn, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	request := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Host: address},
+		Host:   address,
+		Header: http.Header{},
+	}
+
+	if userAgent != "" {
+		request.Header.Set("User-Agent", userAgent)
+	}
+
+	if proxyUrl.User != nil {
+		password, _ := proxyUrl.User.Password()
+		auth := base64.StdEncoding.EncodeToString([]byte(proxyUrl.User.Username() + ":" + password))
+		request.Header.Set("Proxy-Authorization", "Basic "+auth)
+	}
+
+	if err := request.Write(conn); err != nil {
+		return nil, errors.Wrap(err, "failed to write proxy CONNECT request")
+	}
+
+	reader := bufio.NewReader(conn)
+
+	response, err := http.ReadResponse(reader, request)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read proxy CONNECT response")
+	}
+	response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("proxy refused CONNECT to %s: %s", address, response.Status)
+	}
+
+	if reader.Buffered() != 0 {
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+
+	return conn, nil
+}
+
+// bufferedConn keeps the bytes the proxy sent right after its CONNECT response
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
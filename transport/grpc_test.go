@@ -0,0 +1,193 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// grpcServer serves the health service over TLS with the httptest certificate and reports the user agents
+func grpcServer(t *testing.T, certificates []tls.Certificate) (string, chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	userAgents := make(chan string, 8)
+
+	server := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(&tls.Config{Certificates: certificates})),
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			userAgents <- strings.Join(md.Get("user-agent"), ",")
+
+			return handler(ctx, req)
+		}),
+	)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().String(), userAgents
+}
+
+// connectProxy tunnels the CONNECT requests and reports the requested targets with the user agents
+func connectProxy(t *testing.T) (string, chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	connects := make(chan string, 8)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				request, err := http.ReadRequest(bufio.NewReader(conn))
+				if err != nil || request.Method != http.MethodConnect {
+					return
+				}
+
+				connects <- request.Host + " " + request.UserAgent()
+
+				target, err := net.Dial("tcp", request.Host)
+				if err != nil {
+					return
+				}
+				defer target.Close()
+
+				_, _ = conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+
+				go func() { _, _ = io.Copy(target, conn) }()
+				_, _ = io.Copy(conn, target)
+			}()
+		}
+	}()
+
+	return "http://" + listener.Addr().String(), connects
+}
+
+func checkHealth(t *testing.T, address string, client *http.Client) error {
+	options, err := GrpcDialOptions(client, true)
+	if err != nil {
+		t.Fatalf("failed to get dial options: %v", err)
+	}
+
+	// the test certificate is issued for example.com, the pins are looked up by the server name
+	conn, err := grpc.Dial(address, append(options, grpc.WithAuthority("example.com"))...)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+
+	return err
+}
+
+func TestGrpcDialOptions(t *testing.T) {
+	httpServer := httptest.NewTLSServer(http.NotFoundHandler())
+	defer httpServer.Close()
+
+	address, userAgents := grpcServer(t, httpServer.TLS.Certificates)
+
+	t.Run("Should reject unknown CA", func(t *testing.T) {
+		client, err := NewClient(Options{})
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+
+		if err := checkHealth(t, address, client); err == nil {
+			t.Errorf("expected certificate error")
+		}
+	})
+	t.Run("Should trust custom CA and pinned key", func(t *testing.T) {
+		client, err := NewClient(Options{
+			RootCAs: serverRootCAs(httpServer),
+			Pins:    map[string][]string{"example.com": {Pin(httpServer.Certificate())}},
+		})
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+
+		if err := checkHealth(t, address, client); err != nil {
+			t.Errorf("failed to check health: %v", err)
+		}
+		<-userAgents
+	})
+	t.Run("Should reject key not matching the pins", func(t *testing.T) {
+		client, err := NewClient(Options{
+			RootCAs: serverRootCAs(httpServer),
+			Pins:    map[string][]string{"example.com": {"sha256/" + strings.Repeat("A", 43) + "="}},
+		})
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+
+		if err := checkHealth(t, address, client); err == nil || !strings.Contains(err.Error(), ErrPinMismatch.Error()) {
+			t.Errorf("expected pin mismatch, got %v", err)
+		}
+	})
+	t.Run("Should tunnel through proxy with user agent", func(t *testing.T) {
+		proxyUrl, connects := connectProxy(t)
+
+		client, err := NewClient(Options{
+			RootCAs:   serverRootCAs(httpServer),
+			ProxyUrl:  proxyUrl,
+			UserAgent: "exposer-test/1.0",
+		})
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+
+		if err := checkHealth(t, address, client); err != nil {
+			t.Fatalf("failed to check health: %v", err)
+		}
+
+		if connect := <-connects; connect != address+" exposer-test/1.0" {
+			t.Errorf("unexpected CONNECT %q", connect)
+		}
+
+		if userAgent := <-userAgents; !strings.HasPrefix(userAgent, "exposer-test/1.0") {
+			t.Errorf("unexpected user agent %q", userAgent)
+		}
+	})
+	t.Run("Should reject custom round trippers", func(t *testing.T) {
+		client := &http.Client{Transport: &hooksTransport{base: roundTripperFunc(nil)}}
+
+		if _, err := GrpcDialOptions(client, true); !errors.Is(err, ErrUnsupportedTransport) {
+			t.Errorf("expected ErrUnsupportedTransport, got %v", err)
+		}
+	})
+}
+
+type roundTripperFunc func(request *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
@@ -0,0 +1,195 @@
+package transport
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultTimeout             = 60 * time.Second
+	DefaultDialTimeout         = 15 * time.Second
+	DefaultTLSHandshakeTimeout = 10 * time.Second
+
+	pinPrefix = "sha256/"
+)
+
+var ErrPinMismatch = errcode.New(errcode.CertificateRejected, "no certificate of the chain matches the pinned keys")
+
+// Options Outbound HTTP policy, zero values fall back to the defaults
+type Options struct {
+	// RootCAs are the PEM encoded certificates trusted in addition to the system ones
+	RootCAs []byte
+	// Pins maps the host to the base64 SHA-256 digests of the accepted SubjectPublicKeyInfo,
+	// optionally prefixed with "sha256/". Hosts without pins are not pinned
+	Pins map[string][]string
+	// ProxyUrl is used for every request, the empty one falls back to the environment
+	ProxyUrl  string
+	UserAgent string
+
+	// Timeout bounds the whole request including reading the body
+	Timeout             time.Duration
+	DialTimeout         time.Duration
+	TLSHandshakeTimeout time.Duration
+
+	OnRequest  func(request *http.Request)
+	OnResponse func(request *http.Request, response *http.Response, err error, duration time.Duration)
+}
+
+func (o Options) withDefaults() Options {
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultTimeout
+	}
+
+	if o.DialTimeout <= 0 {
+		o.DialTimeout = DefaultDialTimeout
+	}
+
+	if o.TLSHandshakeTimeout <= 0 {
+		o.TLSHandshakeTimeout = DefaultTLSHandshakeTimeout
+	}
+
+	return o
+}
+
+// NewClient builds the HTTP client every outbound request of the connector goes through
+func NewClient(options Options) (*http.Client, error) {
+	options = options.withDefaults()
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if len(options.RootCAs) != 0 {
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil || rootCAs == nil {
+			rootCAs = x509.NewCertPool()
+		}
+
+		if !rootCAs.AppendCertsFromPEM(options.RootCAs) {
+			return nil, errors.New("failed to parse root CAs")
+		}
+
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	if len(options.Pins) != 0 {
+		pins, err := parsePins(options.Pins)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			return verifyPins(pins, state)
+		}
+	}
+
+	proxy := http.ProxyFromEnvironment
+
+	if options.ProxyUrl != "" {
+		proxyUrl, err := url.Parse(options.ProxyUrl)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse proxy url")
+		}
+
+		proxy = http.ProxyURL(proxyUrl)
+	}
+
+	base := &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   options.DialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:     tlsConfig,
+		TLSHandshakeTimeout: options.TLSHandshakeTimeout,
+		ForceAttemptHTTP2:   true,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+	}
+
+	return &http.Client{
+		Transport: &hooksTransport{
+			base:       base,
+			userAgent:  options.UserAgent,
+			onRequest:  options.OnRequest,
+			onResponse: options.OnResponse,
+		},
+		Timeout: options.Timeout,
+	}, nil
+}
+
+func parsePins(hostPins map[string][]string) (map[string]map[[sha256.Size]byte]bool, error) {
+	parsed := make(map[string]map[[sha256.Size]byte]bool, len(hostPins))
+
+	for host, pins := range hostPins {
+		parsed[strings.ToLower(host)] = make(map[[sha256.Size]byte]bool, len(pins))
+
+		for _, pin := range pins {
+			digest, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(pin, pinPrefix))
+			if err != nil || len(digest) != sha256.Size {
+				return nil, errors.Errorf("invalid pin %q for %s", pin, host)
+			}
+
+			parsed[strings.ToLower(host)][[sha256.Size]byte(digest)] = true
+		}
+	}
+
+	return parsed, nil
+}
+
+func verifyPins(pins map[string]map[[sha256.Size]byte]bool, state tls.ConnectionState) error {
+	hostPins, ok := pins[strings.ToLower(state.ServerName)]
+	if !ok {
+		return nil
+	}
+
+	for _, certificate := range state.PeerCertificates {
+		if hostPins[sha256.Sum256(certificate.RawSubjectPublicKeyInfo)] {
+			return nil
+		}
+	}
+
+	return errors.Wrap(ErrPinMismatch, state.ServerName)
+}
+
+// Pin returns the pin of the certificate public key in the format Options.Pins accepts
+func Pin(certificate *x509.Certificate) string {
+	digest := sha256.Sum256(certificate.RawSubjectPublicKeyInfo)
+
+	return pinPrefix + base64.StdEncoding.EncodeToString(digest[:])
+}
+
+type hooksTransport struct {
+	base       http.RoundTripper
+	userAgent  string
+	onRequest  func(request *http.Request)
+	onResponse func(request *http.Request, response *http.Response, err error, duration time.Duration)
+}
+
+func (t *hooksTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if t.userAgent != "" {
+		request = request.Clone(request.Context())
+		request.Header.Set("User-Agent", t.userAgent)
+	}
+
+	if t.onRequest != nil {
+		t.onRequest(request)
+	}
+
+	started := time.Now()
+
+	response, err := t.base.RoundTrip(request)
+
+	if t.onResponse != nil {
+		t.onResponse(request, response, err, time.Since(started))
+	}
+
+	return response, err
+}
@@ -0,0 +1,134 @@
+package transport
+
+import (
+	"encoding/pem"
+	"github.com/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func serverRootCAs(server *httptest.Server) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+}
+
+func TestClient(t *testing.T) {
+	userAgents := make(chan string, 1)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents <- r.UserAgent()
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+
+	t.Run("Should reject unknown CA", func(t *testing.T) {
+		client, err := NewClient(Options{})
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+
+		if _, err := client.Get(server.URL); err == nil {
+			t.Fatalf("expected certificate error")
+		}
+	})
+	t.Run("Should trust custom CA, set user agent and call hooks", func(t *testing.T) {
+		var (
+			requested bool
+			status    int
+		)
+
+		client, err := NewClient(Options{
+			RootCAs:   serverRootCAs(server),
+			UserAgent: "exposer-test/1.0",
+			OnRequest: func(request *http.Request) {
+				requested = true
+			},
+			OnResponse: func(request *http.Request, response *http.Response, err error, duration time.Duration) {
+				if err == nil {
+					status = response.StatusCode
+				}
+			},
+		})
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+
+		response, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("failed to get: %v", err)
+		}
+		response.Body.Close()
+
+		if userAgent := <-userAgents; userAgent != "exposer-test/1.0" {
+			t.Errorf("unexpected user agent %q", userAgent)
+		}
+
+		if !requested || status != http.StatusTeapot {
+			t.Errorf("hooks were not called, requested %v, status %d", requested, status)
+		}
+	})
+	t.Run("Should accept pinned key", func(t *testing.T) {
+		client, err := NewClient(Options{
+			RootCAs: serverRootCAs(server),
+			Pins:    map[string][]string{"example.com": {Pin(server.Certificate())}},
+		})
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+
+		// the test certificate is issued for example.com, the server name is taken from the request host
+		request, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		client.Transport.(*hooksTransport).base.(*http.Transport).TLSClientConfig.ServerName = "example.com"
+
+		response, err := client.Do(request)
+		if err != nil {
+			t.Fatalf("failed to get: %v", err)
+		}
+		response.Body.Close()
+		<-userAgents
+	})
+	t.Run("Should reject key not matching the pins", func(t *testing.T) {
+		client, err := NewClient(Options{
+			RootCAs: serverRootCAs(server),
+			Pins:    map[string][]string{"example.com": {"sha256/" + strings.Repeat("A", 43) + "="}},
+		})
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+
+		client.Transport.(*hooksTransport).base.(*http.Transport).TLSClientConfig.ServerName = "example.com"
+
+		_, err = client.Get(server.URL)
+		if !errors.Is(err, ErrPinMismatch) {
+			t.Fatalf("expected ErrPinMismatch, got %v", err)
+		}
+	})
+	t.Run("Should reject malformed pins", func(t *testing.T) {
+		if _, err := NewClient(Options{Pins: map[string][]string{"example.com": {"sha256/short"}}}); err == nil {
+			t.Errorf("expected error for malformed pin")
+		}
+	})
+	t.Run("Should route through proxy", func(t *testing.T) {
+		proxied := make(chan string, 1)
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxied <- r.URL.String()
+		}))
+		defer proxy.Close()
+
+		client, err := NewClient(Options{ProxyUrl: proxy.URL})
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+
+		response, err := client.Get("http://issuer.example/v1/credentials")
+		if err != nil {
+			t.Fatalf("failed to get: %v", err)
+		}
+		response.Body.Close()
+
+		if url := <-proxied; url != "http://issuer.example/v1/credentials" {
+			t.Errorf("unexpected proxied url %q", url)
+		}
+	})
+}
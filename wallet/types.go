@@ -4,6 +4,14 @@ import (
 	"github.com/rarimo/zkp-iden3-exposer/wallet/overrides"
 )
 
+// ChainConfig Chain id and fee settings of the chain the wallet sends its transactions to
+type ChainConfig struct {
+	ChainId     string `json:"chainId"`
+	Denom       string `json:"denom"`
+	MinGasPrice uint64 `json:"minGasPrice"`
+	GasLimit    uint64 `json:"gasLimit"`
+}
+
 type Account struct {
 	Algo    string           `json:"algo"`
 	Address string           `json:"address"`
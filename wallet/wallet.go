@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"github.com/decred/dcrd/bech32"
 	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
 	"github.com/rarimo/zkp-iden3-exposer/wallet/overrides"
 	"strings"
 )
@@ -20,7 +21,7 @@ func NewWallet(privateKeyHex string, addressPrefix string) (*Wallet, error) {
 
 	privateKeyBytes, err := hex.DecodeString(sanitizedPrivateKey)
 	if err != nil {
-		return nil, errors.Wrap(err, "Error decoding private key")
+		return nil, errcode.Wrap(err, errcode.KeyError, "Error decoding private key")
 	}
 
 	privateKey := overrides.GenPrivKeyFromSecret(privateKeyBytes)
@@ -0,0 +1,46 @@
+//go:build !js
+
+package zkp_iden3_exposer
+
+import (
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/client"
+	"github.com/rarimo/zkp-iden3-exposer/wallet"
+)
+
+// WalletSend transfers the amount of Denom through the gRPC API of the core at RpcApi
+func (c *Connector) WalletSend(fromAddr, toAddr string, amount int64) (_ []byte, err error) {
+	defer withCode(&err)
+
+	w, err := wallet.NewWallet(c.PkHex, c.AddrPrefix)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating wallet")
+	}
+
+	grpcClient, err := c.getGrpcConn()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting grpc connection")
+	}
+
+	rarimoClient, err := client.NewClient(
+		grpcClient,
+		c.getChainConfig(),
+		*w,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating client")
+	}
+
+	txResp, err := rarimoClient.Send(
+		c.context(),
+		fromAddr,
+		toAddr,
+		amount,
+		c.Denom,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error sending tx")
+	}
+
+	return txResp, nil
+}
@@ -0,0 +1,33 @@
+//go:build js
+
+package zkp_iden3_exposer
+
+import (
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/client/rest"
+	"github.com/rarimo/zkp-iden3-exposer/wallet"
+)
+
+// WalletSend transfers the amount of Denom through the REST API of the core at CoreApiUrl,
+// the browsers can not reach the gRPC one
+func (c *Connector) WalletSend(fromAddr, toAddr string, amount int64) (_ []byte, err error) {
+	defer withCode(&err)
+
+	w, err := wallet.NewWallet(c.PkHex, c.AddrPrefix)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating wallet")
+	}
+
+	txResp, err := rest.NewClient(c.CoreApiUrl, c.getHttpClient(), c.getChainConfig(), *w).Send(
+		c.context(),
+		fromAddr,
+		toAddr,
+		amount,
+		c.Denom,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error sending tx")
+	}
+
+	return txResp, nil
+}
@@ -0,0 +1,65 @@
+package zkp_iden3_exposer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWalletGetBalance(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/cosmos/bank/v1beta1/balances/") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		address := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/cosmos/bank/v1beta1/balances/"), "/by_denom")
+		if address == "rarimo1empty" {
+			_, _ = w.Write([]byte(`{"balance":null}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"balance":{"denom":"` + r.URL.Query().Get("denom") + `","amount":"42"}}`))
+	}))
+	defer server.Close()
+
+	connector, err := NewConnectorFromNetwork(
+		"local",
+		"1cbd5d2d1801e964736881fc0584473f23ba82669599ac65957fb4f2caf43e17",
+		[]byte(localOverrides),
+	)
+	if err != nil {
+		t.Fatalf("Error creating connector: %v", err)
+	}
+
+	connector.CoreApiUrl = server.URL
+
+	getBalance := func(t *testing.T, address string) map[string]string {
+		balanceJson, err := connector.WalletGetBalance(address)
+		if err != nil {
+			t.Fatalf("Error getting balance: %v", err)
+		}
+
+		balance := map[string]string{}
+		if err := json.Unmarshal(balanceJson, &balance); err != nil {
+			t.Fatalf("Error unmarshalling balance: %v", err)
+		}
+
+		return balance
+	}
+
+	t.Run("Should get the connector wallet balance", func(t *testing.T) {
+		balance := getBalance(t, "")
+
+		if balance["denom"] != connector.Denom || balance["amount"] != "42" {
+			t.Errorf("Unexpected balance %v", balance)
+		}
+	})
+	t.Run("Should report zero for the missing balance", func(t *testing.T) {
+		if balance := getBalance(t, "rarimo1empty"); balance["amount"] != "0" {
+			t.Errorf("Unexpected balance %v", balance)
+		}
+	})
+}
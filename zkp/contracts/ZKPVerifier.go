@@ -0,0 +1,233 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contracts
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// ZKPVerifierMetaData contains all meta data concerning the ZKPVerifier contract.
+var ZKPVerifierMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"uint64\",\"name\":\"\",\"type\":\"uint64\"}],\"name\":\"proofs\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint64\",\"name\":\"requestId\",\"type\":\"uint64\"},{\"internalType\":\"uint256[]\",\"name\":\"inputs\",\"type\":\"uint256[]\"},{\"internalType\":\"uint256[2]\",\"name\":\"a\",\"type\":\"uint256[2]\"},{\"internalType\":\"uint256[2][2]\",\"name\":\"b\",\"type\":\"uint256[2][2]\"},{\"internalType\":\"uint256[2]\",\"name\":\"c\",\"type\":\"uint256[2]\"}],\"name\":\"submitZKPResponse\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]",
+}
+
+// ZKPVerifierABI is the input ABI used to generate the binding from.
+// Deprecated: Use ZKPVerifierMetaData.ABI instead.
+var ZKPVerifierABI = ZKPVerifierMetaData.ABI
+
+// ZKPVerifier is an auto generated Go binding around an Ethereum contract.
+type ZKPVerifier struct {
+	ZKPVerifierCaller     // Read-only binding to the contract
+	ZKPVerifierTransactor // Write-only binding to the contract
+	ZKPVerifierFilterer   // Log filterer for contract events
+}
+
+// ZKPVerifierCaller is an auto generated read-only Go binding around an Ethereum contract.
+type ZKPVerifierCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// ZKPVerifierTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type ZKPVerifierTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// ZKPVerifierFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type ZKPVerifierFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// ZKPVerifierSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type ZKPVerifierSession struct {
+	Contract     *ZKPVerifier      // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// ZKPVerifierCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type ZKPVerifierCallerSession struct {
+	Contract *ZKPVerifierCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts      // Call options to use throughout this session
+}
+
+// ZKPVerifierTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type ZKPVerifierTransactorSession struct {
+	Contract     *ZKPVerifierTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts      // Transaction auth options to use throughout this session
+}
+
+// ZKPVerifierRaw is an auto generated low-level Go binding around an Ethereum contract.
+type ZKPVerifierRaw struct {
+	Contract *ZKPVerifier // Generic contract binding to access the raw methods on
+}
+
+// ZKPVerifierCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type ZKPVerifierCallerRaw struct {
+	Contract *ZKPVerifierCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// ZKPVerifierTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type ZKPVerifierTransactorRaw struct {
+	Contract *ZKPVerifierTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewZKPVerifier creates a new instance of ZKPVerifier, bound to a specific deployed contract.
+func NewZKPVerifier(address common.Address, backend bind.ContractBackend) (*ZKPVerifier, error) {
+	contract, err := bindZKPVerifier(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &ZKPVerifier{ZKPVerifierCaller: ZKPVerifierCaller{contract: contract}, ZKPVerifierTransactor: ZKPVerifierTransactor{contract: contract}, ZKPVerifierFilterer: ZKPVerifierFilterer{contract: contract}}, nil
+}
+
+// NewZKPVerifierCaller creates a new read-only instance of ZKPVerifier, bound to a specific deployed contract.
+func NewZKPVerifierCaller(address common.Address, caller bind.ContractCaller) (*ZKPVerifierCaller, error) {
+	contract, err := bindZKPVerifier(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ZKPVerifierCaller{contract: contract}, nil
+}
+
+// NewZKPVerifierTransactor creates a new write-only instance of ZKPVerifier, bound to a specific deployed contract.
+func NewZKPVerifierTransactor(address common.Address, transactor bind.ContractTransactor) (*ZKPVerifierTransactor, error) {
+	contract, err := bindZKPVerifier(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ZKPVerifierTransactor{contract: contract}, nil
+}
+
+// NewZKPVerifierFilterer creates a new log filterer instance of ZKPVerifier, bound to a specific deployed contract.
+func NewZKPVerifierFilterer(address common.Address, filterer bind.ContractFilterer) (*ZKPVerifierFilterer, error) {
+	contract, err := bindZKPVerifier(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &ZKPVerifierFilterer{contract: contract}, nil
+}
+
+// bindZKPVerifier binds a generic wrapper to an already deployed contract.
+func bindZKPVerifier(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := ZKPVerifierMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_ZKPVerifier *ZKPVerifierRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _ZKPVerifier.Contract.ZKPVerifierCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_ZKPVerifier *ZKPVerifierRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _ZKPVerifier.Contract.ZKPVerifierTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_ZKPVerifier *ZKPVerifierRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _ZKPVerifier.Contract.ZKPVerifierTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_ZKPVerifier *ZKPVerifierCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _ZKPVerifier.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_ZKPVerifier *ZKPVerifierTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _ZKPVerifier.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_ZKPVerifier *ZKPVerifierTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _ZKPVerifier.Contract.contract.Transact(opts, method, params...)
+}
+
+// Proofs is a free data retrieval call binding the contract method 0xb45c0fdf.
+//
+// Solidity: function proofs(address , uint64 ) view returns(bool)
+func (_ZKPVerifier *ZKPVerifierCaller) Proofs(opts *bind.CallOpts, arg0 common.Address, arg1 uint64) (bool, error) {
+	var out []interface{}
+	err := _ZKPVerifier.contract.Call(opts, &out, "proofs", arg0, arg1)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// Proofs is a free data retrieval call binding the contract method 0xb45c0fdf.
+//
+// Solidity: function proofs(address , uint64 ) view returns(bool)
+func (_ZKPVerifier *ZKPVerifierSession) Proofs(arg0 common.Address, arg1 uint64) (bool, error) {
+	return _ZKPVerifier.Contract.Proofs(&_ZKPVerifier.CallOpts, arg0, arg1)
+}
+
+// Proofs is a free data retrieval call binding the contract method 0xb45c0fdf.
+//
+// Solidity: function proofs(address , uint64 ) view returns(bool)
+func (_ZKPVerifier *ZKPVerifierCallerSession) Proofs(arg0 common.Address, arg1 uint64) (bool, error) {
+	return _ZKPVerifier.Contract.Proofs(&_ZKPVerifier.CallOpts, arg0, arg1)
+}
+
+// SubmitZKPResponse is a paid mutator transaction binding the contract method 0xb68967e2.
+//
+// Solidity: function submitZKPResponse(uint64 requestId, uint256[] inputs, uint256[2] a, uint256[2][2] b, uint256[2] c) returns(bool)
+func (_ZKPVerifier *ZKPVerifierTransactor) SubmitZKPResponse(opts *bind.TransactOpts, requestId uint64, inputs []*big.Int, a [2]*big.Int, b [2][2]*big.Int, c [2]*big.Int) (*types.Transaction, error) {
+	return _ZKPVerifier.contract.Transact(opts, "submitZKPResponse", requestId, inputs, a, b, c)
+}
+
+// SubmitZKPResponse is a paid mutator transaction binding the contract method 0xb68967e2.
+//
+// Solidity: function submitZKPResponse(uint64 requestId, uint256[] inputs, uint256[2] a, uint256[2][2] b, uint256[2] c) returns(bool)
+func (_ZKPVerifier *ZKPVerifierSession) SubmitZKPResponse(requestId uint64, inputs []*big.Int, a [2]*big.Int, b [2][2]*big.Int, c [2]*big.Int) (*types.Transaction, error) {
+	return _ZKPVerifier.Contract.SubmitZKPResponse(&_ZKPVerifier.TransactOpts, requestId, inputs, a, b, c)
+}
+
+// SubmitZKPResponse is a paid mutator transaction binding the contract method 0xb68967e2.
+//
+// Solidity: function submitZKPResponse(uint64 requestId, uint256[] inputs, uint256[2] a, uint256[2][2] b, uint256[2] c) returns(bool)
+func (_ZKPVerifier *ZKPVerifierTransactorSession) SubmitZKPResponse(requestId uint64, inputs []*big.Int, a [2]*big.Int, b [2][2]*big.Int, c [2]*big.Int) (*types.Transaction, error) {
+	return _ZKPVerifier.Contract.SubmitZKPResponse(&_ZKPVerifier.TransactOpts, requestId, inputs, a, b, c)
+}
@@ -0,0 +1,150 @@
+package didtype
+
+import (
+	core "github.com/iden3/go-iden3-core/v2"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"sync"
+)
+
+const (
+	MethodIden3     = string(core.DIDMethodIden3)
+	MethodPolygonID = string(core.DIDMethodPolygonID)
+
+	BlockchainReadOnly = string(core.ReadOnly)
+	BlockchainEthereum = string(core.Ethereum)
+	BlockchainPolygon  = string(core.Polygon)
+	BlockchainZkEVM    = string(core.ZkEVM)
+	// BlockchainRarimo is not known to go-iden3-core and has no assigned network flags,
+	// it has to be registered with the flags the issuer uses before it can be built
+	BlockchainRarimo = "rarimo"
+
+	NetworkMain    = string(core.Main)
+	NetworkTest    = string(core.Test)
+	NetworkAmoy    = string(core.Amoy)
+	NetworkSepolia = string(core.Sepolia)
+	NetworkNone    = string(core.NoNetwork)
+)
+
+var (
+	ErrInvalidLength      = errcode.New(errcode.InvalidConfig, "id type must be 2 bytes")
+	ErrUnknownMethod      = errcode.New(errcode.InvalidConfig, "unknown DID method")
+	ErrUnsupportedNetwork = errcode.New(errcode.InvalidConfig, "network is not supported by the DID method")
+)
+
+// Type Human readable form of the two bytes identity type
+type Type struct {
+	Method     string `json:"method"`
+	Blockchain string `json:"blockchain"`
+	Network    string `json:"network"`
+}
+
+// Registration Blockchain and network to make known to the DID method, MethodByte is only
+// needed for the methods go-iden3-core does not know, ChainId is optional
+type Registration struct {
+	Method      string
+	Blockchain  string
+	Network     string
+	MethodByte  byte
+	NetworkFlag byte
+	ChainId     int
+}
+
+// go-iden3-core keeps the DID method and network registries in plain maps
+var mutex sync.RWMutex
+
+// Register adds the custom blockchain and network to the DID method, registering the method
+// itself when MethodByte is set
+func Register(registration Registration) error {
+	if registration.Method == "" || registration.Blockchain == "" || registration.Network == "" {
+		return errors.New("method, blockchain and network are required")
+	}
+
+	var options []core.RegistrationOptions
+
+	if registration.MethodByte != 0 {
+		options = append(options, core.WithDIDMethodByte(registration.MethodByte))
+	}
+
+	if registration.ChainId != 0 {
+		options = append(options, core.WithChainID(registration.ChainId))
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if _, ok := core.DIDMethodByte[core.DIDMethod(registration.Method)]; !ok && registration.MethodByte == 0 {
+		return errors.Wrapf(ErrUnknownMethod, "%s, method byte is required", registration.Method)
+	}
+
+	err := core.RegisterDIDMethodNetwork(core.DIDMethodNetworkParams{
+		Method:      core.DIDMethod(registration.Method),
+		Blockchain:  core.Blockchain(registration.Blockchain),
+		Network:     core.NetworkID(registration.Network),
+		NetworkFlag: registration.NetworkFlag,
+	}, options...)
+	if err != nil {
+		return errors.Wrap(err, "failed to register DID method network")
+	}
+
+	return nil
+}
+
+// Build returns the identity type of the DID method on the blockchain network
+func Build(method string, blockchain string, network string) ([2]byte, error) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	if _, ok := core.DIDMethodByte[core.DIDMethod(method)]; !ok {
+		return [2]byte{}, errors.Wrap(ErrUnknownMethod, method)
+	}
+
+	idType, err := core.BuildDIDType(core.DIDMethod(method), core.Blockchain(blockchain), core.NetworkID(network))
+	if err != nil {
+		return [2]byte{}, errors.Wrapf(ErrUnsupportedNetwork, "%s:%s:%s", method, blockchain, network)
+	}
+
+	return idType, nil
+}
+
+// Parse validates the raw identity type, both the method and the network must be registered
+func Parse(idType []byte) ([2]byte, error) {
+	if len(idType) != 2 {
+		return [2]byte{}, errors.Wrapf(ErrInvalidLength, "got %d", len(idType))
+	}
+
+	parsed := [2]byte(idType)
+
+	if _, err := Describe(parsed); err != nil {
+		return [2]byte{}, err
+	}
+
+	return parsed, nil
+}
+
+// Describe returns the DID method, blockchain and network the identity type stands for
+func Describe(idType [2]byte) (*Type, error) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	method, err := core.FindDIDMethodByValue(idType[0])
+	if err != nil {
+		return nil, errors.Wrapf(ErrUnknownMethod, "%#02x", idType[0])
+	}
+
+	blockchain, err := core.FindBlockchainForDIDMethodByValue(method, idType[1])
+	if err != nil {
+		return nil, errors.Wrapf(ErrUnsupportedNetwork, "%s %#02x", method, idType[1])
+	}
+
+	network, err := core.FindNetworkIDForDIDMethodByValue(method, idType[1])
+	if err != nil {
+		return nil, errors.Wrapf(ErrUnsupportedNetwork, "%s %#02x", method, idType[1])
+	}
+
+	return &Type{
+		Method:     string(method),
+		Blockchain: string(blockchain),
+		Network:    string(network),
+	}, nil
+}
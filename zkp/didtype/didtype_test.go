@@ -0,0 +1,96 @@
+package didtype
+
+import (
+	"github.com/pkg/errors"
+	"testing"
+)
+
+func TestDidType(t *testing.T) {
+	t.Run("Should build known id types", func(t *testing.T) {
+		cases := []struct {
+			method, blockchain, network string
+			expected                    [2]byte
+		}{
+			{MethodIden3, BlockchainReadOnly, NetworkNone, [2]byte{0x01, 0x00}},
+			{MethodIden3, BlockchainPolygon, NetworkAmoy, [2]byte{0x01, 0x13}},
+			{MethodPolygonID, BlockchainPolygon, NetworkAmoy, [2]byte{0x02, 0x13}},
+		}
+
+		for _, c := range cases {
+			idType, err := Build(c.method, c.blockchain, c.network)
+			if err != nil {
+				t.Fatalf("failed to build %s:%s:%s: %v", c.method, c.blockchain, c.network, err)
+			}
+
+			if idType != c.expected {
+				t.Errorf("%s:%s:%s: expected %x, got %x", c.method, c.blockchain, c.network, c.expected, idType)
+			}
+
+			description, err := Describe(idType)
+			if err != nil {
+				t.Fatalf("failed to describe %x: %v", idType, err)
+			}
+
+			if description.Method != c.method || description.Blockchain != c.blockchain || description.Network != c.network {
+				t.Errorf("unexpected description of %x: %+v", idType, description)
+			}
+		}
+	})
+	t.Run("Should reject unknown method and network", func(t *testing.T) {
+		if _, err := Build("unknown", BlockchainPolygon, NetworkAmoy); !errors.Is(err, ErrUnknownMethod) {
+			t.Errorf("expected ErrUnknownMethod, got %v", err)
+		}
+
+		if _, err := Build(MethodIden3, BlockchainPolygon, "unknown"); !errors.Is(err, ErrUnsupportedNetwork) {
+			t.Errorf("expected ErrUnsupportedNetwork, got %v", err)
+		}
+
+		if _, err := Build(MethodIden3, BlockchainRarimo, NetworkMain); !errors.Is(err, ErrUnsupportedNetwork) {
+			t.Errorf("expected rarimo to be unregistered, got %v", err)
+		}
+	})
+	t.Run("Should validate raw id type", func(t *testing.T) {
+		if _, err := Parse([]byte{0x01}); !errors.Is(err, ErrInvalidLength) {
+			t.Errorf("expected ErrInvalidLength, got %v", err)
+		}
+
+		if _, err := Parse([]byte{0x01, 0x00, 0x00}); !errors.Is(err, ErrInvalidLength) {
+			t.Errorf("expected ErrInvalidLength, got %v", err)
+		}
+
+		if _, err := Parse([]byte{0x09, 0x00}); !errors.Is(err, ErrUnknownMethod) {
+			t.Errorf("expected ErrUnknownMethod, got %v", err)
+		}
+
+		if _, err := Parse([]byte{0x01, 0x7f}); !errors.Is(err, ErrUnsupportedNetwork) {
+			t.Errorf("expected ErrUnsupportedNetwork, got %v", err)
+		}
+
+		idType, err := Parse([]byte{0x01, 0x00})
+		if err != nil || idType != [2]byte{0x01, 0x00} {
+			t.Errorf("unexpected id type %x: %v", idType, err)
+		}
+	})
+	t.Run("Should register custom networks", func(t *testing.T) {
+		err := Register(Registration{
+			Method:      "exposer",
+			Blockchain:  "exposerchain",
+			Network:     "devnet",
+			MethodByte:  0b0000_0111,
+			NetworkFlag: 0b0000_0001,
+		})
+		if err != nil {
+			t.Fatalf("failed to register network: %v", err)
+		}
+
+		idType, err := Build("exposer", "exposerchain", "devnet")
+		if err != nil || idType != [2]byte{0x07, 0x01} {
+			t.Errorf("unexpected id type %x: %v", idType, err)
+		}
+
+		err = Register(Registration{Method: "unregistered", Blockchain: "chain", Network: "net", NetworkFlag: 1})
+		if !errors.Is(err, ErrUnknownMethod) {
+			t.Errorf("expected ErrUnknownMethod, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package helpers
+
+import (
+	"fmt"
+	"github.com/iden3/go-iden3-crypto/constants"
+	"github.com/iden3/go-rapidsnark/types"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/contracts"
+	"math/big"
+)
+
+// baseFieldModulus Modulus of the BN254 base field the proof point coordinates belong to,
+// the public signals belong to the scalar field constants.Q
+var baseFieldModulus, _ = new(big.Int).SetString("21888242871839275222246405745257275088696311157297823662689037894645226208583", 10)
+
+// ZKPResponse Arguments of ZKPVerifier.submitZKPResponse
+type ZKPResponse struct {
+	RequestId uint64         `json:"requestId"`
+	Inputs    []*big.Int     `json:"inputs"`
+	A         [2]*big.Int    `json:"a"`
+	B         [2][2]*big.Int `json:"b"`
+	C         [2]*big.Int    `json:"c"`
+}
+
+// parseBigInts parses the decimal field elements, the ABI packing of uint256 would wrap the values out of [0, modulus)
+func parseBigInts(values []string, modulus *big.Int) ([]*big.Int, error) {
+	result := make([]*big.Int, len(values))
+
+	for i, value := range values {
+		parsed, ok := new(big.Int).SetString(value, 10)
+		if !ok {
+			return nil, errcode.New(errcode.InvalidInput, fmt.Sprintf("failed to parse %q", value))
+		}
+
+		if parsed.Sign() < 0 || parsed.Cmp(modulus) >= 0 {
+			return nil, errcode.New(errcode.InvalidInput, fmt.Sprintf("%s is not a field element", value))
+		}
+
+		result[i] = parsed
+	}
+
+	return result, nil
+}
+
+// NewZKPResponse converts the snarkjs proof to the verifier arguments,
+// G2 coordinates are swapped since the precompile expects (c1, c0)
+func NewZKPResponse(requestId int64, proof types.ZKProof) (*ZKPResponse, error) {
+	if requestId < 0 {
+		return nil, errcode.New(errcode.InvalidInput, "request ID must not be negative")
+	}
+
+	if proof.Proof == nil {
+		return nil, errors.New("proof is empty")
+	}
+
+	if len(proof.Proof.A) < 2 || len(proof.Proof.B) < 2 || len(proof.Proof.C) < 2 {
+		return nil, errors.New("proof is malformed")
+	}
+
+	inputs, err := parseBigInts(proof.PubSignals, constants.Q)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse public signals")
+	}
+
+	a, err := parseBigInts(proof.Proof.A[:2], baseFieldModulus)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse pi_a")
+	}
+
+	c, err := parseBigInts(proof.Proof.C[:2], baseFieldModulus)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse pi_c")
+	}
+
+	response := ZKPResponse{
+		RequestId: uint64(requestId),
+		Inputs:    inputs,
+		A:         [2]*big.Int{a[0], a[1]},
+		C:         [2]*big.Int{c[0], c[1]},
+	}
+
+	for i := 0; i < 2; i++ {
+		if len(proof.Proof.B[i]) < 2 {
+			return nil, errors.New("proof is malformed")
+		}
+
+		b, err := parseBigInts(proof.Proof.B[i][:2], baseFieldModulus)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse pi_b")
+		}
+
+		response.B[i] = [2]*big.Int{b[1], b[0]}
+	}
+
+	return &response, nil
+}
+
+// Pack ABI-encodes the submitZKPResponse call including the method selector
+func (r *ZKPResponse) Pack() ([]byte, error) {
+	verifierAbi, err := contracts.ZKPVerifierMetaData.GetAbi()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get verifier abi")
+	}
+
+	calldata, err := verifierAbi.Pack("submitZKPResponse", r.RequestId, r.Inputs, r.A, r.B, r.C)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to pack submitZKPResponse")
+	}
+
+	return calldata, nil
+}
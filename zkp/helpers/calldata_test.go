@@ -0,0 +1,109 @@
+package helpers
+
+import (
+	"bytes"
+	"github.com/iden3/go-iden3-crypto/constants"
+	"github.com/iden3/go-rapidsnark/types"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/contracts"
+	"math/big"
+	"testing"
+)
+
+func TestZKPResponse(t *testing.T) {
+	proof := types.ZKProof{
+		Proof: &types.ProofData{
+			A:        []string{"1", "2", "1"},
+			B:        [][]string{{"3", "4"}, {"5", "6"}, {"1", "0"}},
+			C:        []string{"7", "8", "1"},
+			Protocol: "groth16",
+		},
+		PubSignals: []string{"9", "10", "11"},
+	}
+
+	response, err := NewZKPResponse(42, proof)
+	if err != nil {
+		t.Fatalf("Error creating response: %v", err)
+	}
+
+	t.Run("Should swap G2 coordinates", func(t *testing.T) {
+		if response.B[0][0].Int64() != 4 || response.B[0][1].Int64() != 3 ||
+			response.B[1][0].Int64() != 6 || response.B[1][1].Int64() != 5 {
+			t.Errorf("Unexpected pi_b: %v", response.B)
+		}
+	})
+	t.Run("Should pack submitZKPResponse calldata", func(t *testing.T) {
+		calldata, err := response.Pack()
+		if err != nil {
+			t.Fatalf("Error packing calldata: %v", err)
+		}
+
+		verifierAbi, err := contracts.ZKPVerifierMetaData.GetAbi()
+		if err != nil {
+			t.Fatalf("Error getting abi: %v", err)
+		}
+
+		method := verifierAbi.Methods["submitZKPResponse"]
+
+		if !bytes.Equal(calldata[:4], method.ID) {
+			t.Fatalf("Unexpected selector %x", calldata[:4])
+		}
+
+		args, err := method.Inputs.Unpack(calldata[4:])
+		if err != nil {
+			t.Fatalf("Error unpacking calldata: %v", err)
+		}
+
+		if args[0].(uint64) != 42 {
+			t.Errorf("Unexpected request id %v", args[0])
+		}
+
+		inputs := args[1].([]*big.Int)
+		if len(inputs) != 3 || inputs[2].Int64() != 11 {
+			t.Errorf("Unexpected inputs %v", inputs)
+		}
+	})
+	t.Run("Should fail on malformed proof", func(t *testing.T) {
+		if _, err := NewZKPResponse(1, types.ZKProof{}); err == nil {
+			t.Errorf("Expected error for empty proof")
+		}
+	})
+	t.Run("Should reject negative request ID", func(t *testing.T) {
+		if _, err := NewZKPResponse(-1, proof); errcode.Of(err) != errcode.InvalidInput {
+			t.Errorf("Expected %s, got %v", errcode.InvalidInput, err)
+		}
+	})
+	t.Run("Should reject values out of the field", func(t *testing.T) {
+		lastElement := new(big.Int).Sub(constants.Q, big.NewInt(1)).String()
+
+		if _, err := NewZKPResponse(1, withSignals(proof, lastElement)); err != nil {
+			t.Errorf("Expected the last field element to be accepted, got %v", err)
+		}
+
+		for _, signal := range []string{"-1", constants.Q.String()} {
+			if _, err := NewZKPResponse(1, withSignals(proof, signal)); errcode.Of(err) != errcode.InvalidInput {
+				t.Errorf("Expected %s for signal %s, got %v", errcode.InvalidInput, signal, err)
+			}
+		}
+
+		negativePoint := *proof.Proof
+		negativePoint.A = []string{"-1", "2", "1"}
+
+		if _, err := NewZKPResponse(1, types.ZKProof{Proof: &negativePoint, PubSignals: proof.PubSignals}); errcode.Of(err) != errcode.InvalidInput {
+			t.Errorf("Expected %s for negative pi_a, got %v", errcode.InvalidInput, err)
+		}
+
+		largePoint := *proof.Proof
+		largePoint.C = []string{"7", baseFieldModulus.String(), "1"}
+
+		if _, err := NewZKPResponse(1, types.ZKProof{Proof: &largePoint, PubSignals: proof.PubSignals}); errcode.Of(err) != errcode.InvalidInput {
+			t.Errorf("Expected %s for pi_c out of the base field, got %v", errcode.InvalidInput, err)
+		}
+	})
+}
+
+func withSignals(proof types.ZKProof, signals ...string) types.ZKProof {
+	proof.PubSignals = signals
+
+	return proof
+}
@@ -0,0 +1,137 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"github.com/rarimo/zkp-iden3-exposer/evm/backend"
+	"github.com/rarimo/zkp-iden3-exposer/progress"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/contracts"
+	"math/big"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultGISTProofLatestTTL = 30 * time.Second
+	DefaultGISTProofCapacity  = 32
+)
+
+// GISTProofCache Keeps the recently fetched GIST proofs. Proofs for a fixed root, time or block never change
+// and live until evicted, the latest proof is only reused for latestTTL as new roots keep coming
+type GISTProofCache struct {
+	mutex     sync.Mutex
+	latestTTL time.Duration
+	capacity  int
+	entries   map[string]gistProofEntry
+}
+
+type gistProofEntry struct {
+	proof     *contracts.IStateGistProof
+	fetchedAt time.Time
+	latest    bool
+}
+
+func NewGISTProofCache(latestTTL time.Duration, capacity int) *GISTProofCache {
+	if latestTTL <= 0 {
+		latestTTL = DefaultGISTProofLatestTTL
+	}
+
+	if capacity <= 0 {
+		capacity = DefaultGISTProofCapacity
+	}
+
+	return &GISTProofCache{
+		latestTTL: latestTTL,
+		capacity:  capacity,
+		entries:   map[string]gistProofEntry{},
+	}
+}
+
+// GetGISTProof returns the cached proof or fetches it with GetGISTProofByReference, the nil cache always fetches
+func (c *GISTProofCache) GetGISTProof(
+	ctx context.Context,
+	backends *backend.Pool,
+	coreEvmRpcUrl string,
+	coreStateContractAddress string,
+	userId *big.Int,
+	reference GISTReference,
+) (_ *contracts.IStateGistProof, err error) {
+	done := progress.Track(ctx, progress.StepGISTProof)
+	defer func() { done(err) }()
+
+	fetch := func() (*contracts.IStateGistProof, error) {
+		return GetGISTProofByReference(ctx, backends, coreEvmRpcUrl, coreStateContractAddress, userId, reference)
+	}
+
+	if c == nil {
+		return fetch()
+	}
+
+	return c.get(gistProofKey(coreStateContractAddress, userId, reference), reference.Kind == GISTLatest, fetch)
+}
+
+func (c *GISTProofCache) get(
+	key string,
+	latest bool,
+	fetch func() (*contracts.IStateGistProof, error),
+) (*contracts.IStateGistProof, error) {
+	c.mutex.Lock()
+	entry, ok := c.entries[key]
+	c.mutex.Unlock()
+
+	if ok && (!entry.latest || time.Since(entry.fetchedAt) < c.latestTTL) {
+		return entry.proof, nil
+	}
+
+	proof, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries[key] = gistProofEntry{
+		proof:     proof,
+		fetchedAt: time.Now(),
+		latest:    latest,
+	}
+
+	c.evict()
+
+	return proof, nil
+}
+
+// evict drops the oldest proofs over the capacity
+func (c *GISTProofCache) evict() {
+	for len(c.entries) > c.capacity {
+		var (
+			oldestKey string
+			oldest    time.Time
+		)
+
+		for key, entry := range c.entries {
+			if oldestKey == "" || entry.fetchedAt.Before(oldest) {
+				oldestKey, oldest = key, entry.fetchedAt
+			}
+		}
+
+		delete(c.entries, oldestKey)
+	}
+}
+
+// Clear drops every cached proof
+func (c *GISTProofCache) Clear() {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries = map[string]gistProofEntry{}
+}
+
+func gistProofKey(coreStateContractAddress string, userId *big.Int, reference GISTReference) string {
+	return fmt.Sprintf("%s:%s:%d:%s", coreStateContractAddress, userId, reference.Kind, reference.Value)
+}
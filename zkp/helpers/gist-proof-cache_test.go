@@ -0,0 +1,95 @@
+package helpers
+
+import (
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/contracts"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestGISTProofCache(t *testing.T) {
+	fetches := 0
+	fetch := func() (*contracts.IStateGistProof, error) {
+		fetches++
+		return &contracts.IStateGistProof{Root: big.NewInt(int64(fetches))}, nil
+	}
+
+	t.Run("Should reuse proofs for fixed roots", func(t *testing.T) {
+		fetches = 0
+		cache := NewGISTProofCache(time.Hour, 0)
+		key := gistProofKey("0x01", big.NewInt(1), GISTRoot(big.NewInt(5)))
+
+		for i := 0; i < 3; i++ {
+			if _, err := cache.get(key, false, fetch); err != nil {
+				t.Fatalf("failed to get proof: %v", err)
+			}
+		}
+
+		if fetches != 1 {
+			t.Errorf("expected 1 fetch, got %d", fetches)
+		}
+
+		cache.Clear()
+
+		if _, err := cache.get(key, false, fetch); err != nil {
+			t.Fatalf("failed to get proof: %v", err)
+		}
+
+		if fetches != 2 {
+			t.Errorf("expected refetch after clear, got %d fetches", fetches)
+		}
+	})
+	t.Run("Should refetch expired latest proof", func(t *testing.T) {
+		fetches = 0
+		cache := NewGISTProofCache(time.Millisecond, 0)
+		key := gistProofKey("0x01", big.NewInt(1), LatestGIST())
+
+		if _, err := cache.get(key, true, fetch); err != nil {
+			t.Fatalf("failed to get proof: %v", err)
+		}
+
+		time.Sleep(5 * time.Millisecond)
+
+		proof, err := cache.get(key, true, fetch)
+		if err != nil {
+			t.Fatalf("failed to get proof: %v", err)
+		}
+
+		if fetches != 2 || proof.Root.Int64() != 2 {
+			t.Errorf("expected fresh proof, got root %s after %d fetches", proof.Root, fetches)
+		}
+	})
+	t.Run("Should evict oldest proofs", func(t *testing.T) {
+		fetches = 0
+		cache := NewGISTProofCache(time.Hour, 2)
+
+		for i := int64(0); i < 3; i++ {
+			if _, err := cache.get(gistProofKey("0x01", big.NewInt(i), LatestGIST()), true, fetch); err != nil {
+				t.Fatalf("failed to get proof: %v", err)
+			}
+		}
+
+		if len(cache.entries) != 2 {
+			t.Errorf("expected 2 entries, got %d", len(cache.entries))
+		}
+
+		if _, ok := cache.entries[gistProofKey("0x01", big.NewInt(0), LatestGIST())]; ok {
+			t.Errorf("expected the oldest proof to be evicted")
+		}
+	})
+	t.Run("Should not cache errors", func(t *testing.T) {
+		cache := NewGISTProofCache(time.Hour, 0)
+		failing := func() (*contracts.IStateGistProof, error) {
+			return nil, errors.New("rpc is down")
+		}
+
+		if _, err := cache.get("key", false, failing); err == nil {
+			t.Fatalf("expected error")
+		}
+
+		if len(cache.entries) != 0 {
+			t.Errorf("expected no entries, got %d", len(cache.entries))
+		}
+	})
+}
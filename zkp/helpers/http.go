@@ -0,0 +1,33 @@
+package helpers
+
+import (
+	"context"
+	"net/http"
+)
+
+// NewContextHttpClient returns the copy of the client binding every request to ctx, for the libraries
+// like the JSON-LD document loader that take a client but no context
+func NewContextHttpClient(ctx context.Context, client *http.Client) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	bound := *client
+	bound.Transport = &contextTransport{ctx: ctx, base: transport}
+
+	return &bound
+}
+
+type contextTransport struct {
+	ctx  context.Context
+	base http.RoundTripper
+}
+
+func (t *contextTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	return t.base.RoundTrip(request.WithContext(t.ctx))
+}
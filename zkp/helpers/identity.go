@@ -3,6 +3,7 @@ package helpers
 import (
 	"encoding/hex"
 	"github.com/iden3/go-iden3-crypto/babyjub"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
 )
 
 func InitSK(skHex *string) (*babyjub.PrivateKey, error) {
@@ -15,10 +16,14 @@ func InitSK(skHex *string) (*babyjub.PrivateKey, error) {
 
 	var sk babyjub.PrivateKey
 
+	if hex.DecodedLen(len(*skHex)) != len(sk) {
+		return nil, errcode.New(errcode.KeyError, "private key must be 32 bytes hex encoded")
+	}
+
 	_, err := hex.Decode(sk[:], []byte(*skHex))
 
 	if err != nil {
-		return nil, err
+		return nil, errcode.Wrap(err, errcode.KeyError, "failed to decode private key")
 	}
 
 	return &sk, nil
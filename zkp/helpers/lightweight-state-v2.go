@@ -0,0 +1,54 @@
+package helpers
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/coreapi"
+	"github.com/rarimo/zkp-iden3-exposer/evm/backend"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/contracts"
+)
+
+// GetTrustAnchor reads the core signer, the source state contract and the chain name the LightweightStateV2
+// on the target chain accepts
+func GetTrustAnchor(ctx context.Context, backends *backend.Pool, targetRpcUrl string, targetStateContractAddress string) (*coreapi.TrustAnchor, error) {
+	targetBackend, err := backends.Get(targetRpcUrl)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get target chain backend")
+	}
+
+	return readTrustAnchor(ctx, targetBackend, common.HexToAddress(targetStateContractAddress))
+}
+
+// readTrustAnchor the core binds the operations to the source StateV2 it reads the states from,
+// which is the contract the LightweightStateV2 knows as sourceStateContract
+func readTrustAnchor(ctx context.Context, caller bind.ContractCaller, targetStateContract common.Address) (*coreapi.TrustAnchor, error) {
+	lightweightStateCaller, err := contracts.NewLightweightStateV2Caller(targetStateContract, caller)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create lightweight state caller")
+	}
+
+	opts := &bind.CallOpts{Context: ctx}
+
+	signer, err := lightweightStateCaller.Signer(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get signer")
+	}
+
+	sourceStateContract, err := lightweightStateCaller.SourceStateContract(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get source state contract")
+	}
+
+	chainName, err := lightweightStateCaller.ChainName(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get chain name")
+	}
+
+	return &coreapi.TrustAnchor{
+		Signer:   signer,
+		Contract: sourceStateContract,
+		Chain:    chainName,
+	}, nil
+}
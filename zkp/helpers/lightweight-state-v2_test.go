@@ -0,0 +1,26 @@
+package helpers
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	"testing"
+)
+
+func TestReadTrustAnchor(t *testing.T) {
+	t.Run("Should bind the operations to the source state contract", func(t *testing.T) {
+		backend, _, _ := newMockStates(t)
+
+		anchor, err := readTrustAnchor(context.Background(), backend, targetAddress)
+		if err != nil {
+			t.Fatalf("Error reading trust anchor: %v", err)
+		}
+
+		if anchor.Contract != sourceAddress {
+			t.Errorf("Expected source contract %s, got %s", sourceAddress.Hex(), anchor.Contract.Hex())
+		}
+
+		if anchor.Signer != common.HexToAddress("0x03") || anchor.Chain != "Sepolia" {
+			t.Errorf("Unexpected trust anchor %+v", anchor)
+		}
+	})
+}
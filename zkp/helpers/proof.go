@@ -12,6 +12,7 @@ import (
 	"github.com/iden3/go-schema-processor/v2/merklize"
 	"github.com/iden3/go-schema-processor/v2/verifiable"
 	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
 	"github.com/rarimo/zkp-iden3-exposer/zkp/contracts"
 	"github.com/rarimo/zkp-iden3-exposer/zkp/overrides"
 	"github.com/rarimo/zkp-iden3-exposer/zkp/types"
@@ -19,19 +20,38 @@ import (
 	"net/http"
 )
 
-func GetRevocationStatus(url string, EndianSwappedCoreStateHash *string) (verifiable.RevocationStatus, error) {
+func GetRevocationStatus(ctx context.Context, httpClient *http.Client, url string, EndianSwappedCoreStateHash *string) (verifiable.RevocationStatus, error) {
 	revStatusUrl := url
 
 	if EndianSwappedCoreStateHash != nil {
 		revStatusUrl += "?state=" + *EndianSwappedCoreStateHash
 	}
 
-	response, err := http.Get(revStatusUrl)
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, revStatusUrl, nil)
+
+	if err != nil {
+		return verifiable.RevocationStatus{}, errors.Wrap(err, "failed to create revocation status request")
+	}
+
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	response, err := httpClient.Do(request)
 
 	if err != nil {
 		return verifiable.RevocationStatus{}, errors.Wrap(err, "failed to get revocation status")
 	}
 
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return verifiable.RevocationStatus{}, errcode.New(
+			errcode.FromHttpStatus(response.StatusCode, errcode.IssuerRejected),
+			fmt.Sprintf("revocation status responded with status %d", response.StatusCode),
+		)
+	}
+
 	var revStatus verifiable.RevocationStatus
 
 	if err := json.NewDecoder(response.Body).Decode(&revStatus); err != nil {
@@ -44,10 +64,11 @@ func GetRevocationStatus(url string, EndianSwappedCoreStateHash *string) (verifi
 type CredentialStatusResolver struct {
 	Url                        string
 	EndianSwappedCoreStateHash *string
+	HttpClient                 *http.Client
 }
 
 func (c *CredentialStatusResolver) Resolve(ctx context.Context, credentialStatus verifiable.CredentialStatus) (verifiable.RevocationStatus, error) {
-	return GetRevocationStatus(c.Url, c.EndianSwappedCoreStateHash)
+	return GetRevocationStatus(ctx, c.HttpClient, c.Url, c.EndianSwappedCoreStateHash)
 }
 
 func reverseBytes(data []byte) {
@@ -209,7 +230,7 @@ func ToGISTProof(gistProofRaw contracts.IStateGistProof) (*circuits.GISTProof, e
 	return gistProof, nil
 }
 
-func ConvertProofRequestToCircuitQuery(vc *overrides.W3CCredential, request *types.CreateProofRequest) (*circuits.Query, error) {
+func ConvertProofRequestToCircuitQuery(ctx context.Context, httpClient *http.Client, vc *overrides.W3CCredential, request *types.CreateProofRequest) (*circuits.Query, error) {
 	value, ok := new(big.Int).SetString(request.Query.SubjectFieldValue, 10)
 
 	if !ok {
@@ -232,13 +253,13 @@ func ConvertProofRequestToCircuitQuery(vc *overrides.W3CCredential, request *typ
 		return nil, errors.Wrap(err, "failed to marshal vcCopy")
 	}
 
-	merklizer, err := merklize.MerklizeJSONLD(nil, bytes.NewReader(credentialJson))
+	merklizer, err := merklize.MerklizeJSONLD(ctx, bytes.NewReader(credentialJson))
 
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to merklize")
 	}
 
-	docLoader := loaders.NewDocumentLoader(nil, "", loaders.WithHTTPClient(&http.Client{}))
+	docLoader := loaders.NewDocumentLoader(nil, "", loaders.WithHTTPClient(NewContextHttpClient(ctx, httpClient)))
 
 	remoteDocument, err := docLoader.LoadDocument(vc.Context[2])
 
@@ -268,7 +289,7 @@ func ConvertProofRequestToCircuitQuery(vc *overrides.W3CCredential, request *typ
 		return nil, errors.Wrap(err, "failed to prepend path")
 	}
 
-	proof, proofValue, err := merklizer.Proof(nil, path)
+	proof, proofValue, err := merklizer.Proof(ctx, path)
 
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create proof")
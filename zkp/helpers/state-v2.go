@@ -1,37 +1,177 @@
 package helpers
 
 import (
+	"context"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"github.com/rarimo/zkp-iden3-exposer/evm/backend"
 	"github.com/rarimo/zkp-iden3-exposer/zkp/contracts"
 	"math/big"
 )
 
-func GetGISTProof(coreEvmRpcUrl string, coreStateContractAddress string, userId *big.Int, rootHash *big.Int) (*contracts.IStateGistProof, error) {
-	ethClient, err := ethclient.Dial(coreEvmRpcUrl)
+type GISTReferenceKind int
 
-	stateV2Caller, err := contracts.NewStateV2Caller(common.HexToAddress(coreStateContractAddress), ethClient)
+const (
+	GISTLatest GISTReferenceKind = iota + 1
+	GISTByRoot
+	GISTByTime
+	GISTByBlock
+)
 
-	if err != nil {
-		return nil, err
-	}
+var ErrNoCommonGISTRoot = errcode.New(errcode.StateNotSynced, "no GIST root is present on both source and target state")
+
+// GISTReference GIST root the proof is built against, Value is the root, the timestamp or the block number
+// depending on Kind. The zero reference is left for the input builders to pick their default
+type GISTReference struct {
+	Kind  GISTReferenceKind
+	Value *big.Int
+}
+
+func LatestGIST() GISTReference {
+	return GISTReference{Kind: GISTLatest}
+}
 
+func GISTRoot(root *big.Int) GISTReference {
+	return GISTReference{Kind: GISTByRoot, Value: root}
+}
+
+func GISTAtTime(timestamp *big.Int) GISTReference {
+	return GISTReference{Kind: GISTByTime, Value: timestamp}
+}
+
+func GISTAtBlock(blockNumber *big.Int) GISTReference {
+	return GISTReference{Kind: GISTByBlock, Value: blockNumber}
+}
+
+func (r GISTReference) IsZero() bool {
+	return r.Kind == 0
+}
+
+func GetGISTProof(ctx context.Context, backends *backend.Pool, coreEvmRpcUrl string, coreStateContractAddress string, userId *big.Int, rootHash *big.Int) (*contracts.IStateGistProof, error) {
 	if rootHash != nil {
-		gistProof, err := stateV2Caller.GetGISTProofByRoot(nil, userId, rootHash)
+		return GetGISTProofByReference(ctx, backends, coreEvmRpcUrl, coreStateContractAddress, userId, GISTRoot(rootHash))
+	}
 
-		if err != nil {
-			return nil, err
-		}
+	return GetGISTProofByReference(ctx, backends, coreEvmRpcUrl, coreStateContractAddress, userId, LatestGIST())
+}
 
-		return &gistProof, nil
+func GetGISTProofByReference(
+	ctx context.Context,
+	backends *backend.Pool,
+	coreEvmRpcUrl string,
+	coreStateContractAddress string,
+	userId *big.Int,
+	reference GISTReference,
+) (*contracts.IStateGistProof, error) {
+	stateBackend, err := backends.Get(coreEvmRpcUrl)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get state chain backend")
 	}
 
-	gistProof, err := stateV2Caller.GetGISTProof(&bind.CallOpts{}, userId)
+	stateV2Caller, err := contracts.NewStateV2Caller(common.HexToAddress(coreStateContractAddress), stateBackend)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create state caller")
+	}
+
+	return GetGISTProofFromState(ctx, stateV2Caller, userId, reference)
+}
+
+// GetGISTProofFromState GIST proof of the user for the referenced root
+func GetGISTProofFromState(
+	ctx context.Context,
+	stateV2Caller *contracts.StateV2Caller,
+	userId *big.Int,
+	reference GISTReference,
+) (*contracts.IStateGistProof, error) {
+	opts := &bind.CallOpts{Context: ctx}
+
+	var (
+		gistProof contracts.IStateGistProof
+		err       error
+	)
+
+	switch reference.Kind {
+	case GISTLatest:
+		gistProof, err = stateV2Caller.GetGISTProof(opts, userId)
+	case GISTByRoot:
+		gistProof, err = stateV2Caller.GetGISTProofByRoot(opts, userId, reference.Value)
+	case GISTByTime:
+		gistProof, err = stateV2Caller.GetGISTProofByTime(opts, userId, reference.Value)
+	case GISTByBlock:
+		gistProof, err = stateV2Caller.GetGISTProofByBlock(opts, userId, reference.Value)
+	default:
+		return nil, errors.Errorf("unknown GIST reference kind %d", reference.Kind)
+	}
 
 	if err != nil {
-		return nil, err
+		return nil, errors.Wrap(err, "failed to get GIST proof")
 	}
 
 	return &gistProof, nil
 }
+
+// FindNewestCommonGISTRoot walks the source GIST root history from the newest one and returns the first root
+// the target LightweightStateV2 knows, at most maxDepth roots are checked
+func FindNewestCommonGISTRoot(
+	ctx context.Context,
+	stateV2Caller *contracts.StateV2Caller,
+	lightweightStateCaller *contracts.LightweightStateV2Caller,
+	maxDepth int64,
+) (*big.Int, error) {
+	opts := &bind.CallOpts{Context: ctx}
+
+	targetRoot, err := lightweightStateCaller.GetGISTRoot(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get target GIST root")
+	}
+
+	// the current target root is the newest one the verifier accepts, it only has to be provable on the source
+	if targetRoot.Sign() != 0 {
+		rootInfo, err := stateV2Caller.GetGISTRootInfo(opts, targetRoot)
+		if err == nil && rootInfo.CreatedAtTimestamp.Sign() != 0 {
+			return targetRoot, nil
+		}
+	}
+
+	historyLength, err := stateV2Caller.GetGISTRootHistoryLength(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get GIST root history length")
+	}
+
+	const pageSize = 16
+
+	end := historyLength.Int64()
+	stop := end - maxDepth
+	if stop < 0 {
+		stop = 0
+	}
+
+	for end > stop {
+		start := end - pageSize
+		if start < stop {
+			start = stop
+		}
+
+		history, err := stateV2Caller.GetGISTRootHistory(opts, big.NewInt(start), big.NewInt(end-start))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get GIST root history")
+		}
+
+		for i := len(history) - 1; i >= 0; i-- {
+			rootData, err := lightweightStateCaller.GetGISTRootInfo(opts, history[i].Root)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to get target GIST root info")
+			}
+
+			if rootData.CreatedAtTimestamp.Sign() != 0 {
+				return history[i].Root, nil
+			}
+		}
+
+		end = start
+	}
+
+	return nil, ErrNoCommonGISTRoot
+}
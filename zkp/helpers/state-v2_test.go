@@ -0,0 +1,190 @@
+package helpers
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/contracts"
+	"math/big"
+	"testing"
+)
+
+var (
+	sourceAddress = common.HexToAddress("0x01")
+	targetAddress = common.HexToAddress("0x02")
+)
+
+// mockStates serves the source StateV2 GIST history and the roots transited to the target LightweightStateV2
+type mockStates struct {
+	sourceAbi   *abi.ABI
+	targetAbi   *abi.ABI
+	history     []*big.Int
+	targetRoots map[string]bool
+	targetRoot  *big.Int
+	proofCalls  []string
+}
+
+func (m *mockStates) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{0x00}, nil
+}
+
+func (m *mockStates) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	contractAbi := m.sourceAbi
+	if *call.To == targetAddress {
+		contractAbi = m.targetAbi
+	}
+
+	method, err := contractAbi.MethodById(call.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+
+	args, err := method.Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+
+	if *call.To == targetAddress {
+		switch method.Name {
+		case "getGISTRoot":
+			return method.Outputs.Pack(m.targetRoot)
+		case "getGISTRootInfo":
+			root := args[0].(*big.Int)
+			createdAt := big.NewInt(0)
+			if m.targetRoots[root.String()] {
+				createdAt = big.NewInt(1)
+			}
+
+			return method.Outputs.Pack(contracts.ILightweightStateV2GistRootData{Root: root, CreatedAtTimestamp: createdAt})
+		case "signer":
+			return method.Outputs.Pack(common.HexToAddress("0x03"))
+		case "sourceStateContract":
+			return method.Outputs.Pack(sourceAddress)
+		case "chainName":
+			return method.Outputs.Pack("Sepolia")
+		}
+	}
+
+	switch method.Name {
+	case "getGISTRootInfo":
+		for _, root := range m.history {
+			if root.Cmp(args[0].(*big.Int)) == 0 {
+				return method.Outputs.Pack(newRootInfo(root, 1))
+			}
+		}
+
+		return method.Outputs.Pack(newRootInfo(big.NewInt(0), 0))
+	case "getGISTRootHistoryLength":
+		return method.Outputs.Pack(big.NewInt(int64(len(m.history))))
+	case "getGISTRootHistory":
+		start, length := args[0].(*big.Int).Int64(), args[1].(*big.Int).Int64()
+
+		history := make([]contracts.IStateGistRootInfo, 0, length)
+		for _, root := range m.history[start : start+length] {
+			history = append(history, newRootInfo(root, 1))
+		}
+
+		return method.Outputs.Pack(history)
+	case "getGISTProof", "getGISTProofByRoot", "getGISTProofByTime", "getGISTProofByBlock":
+		m.proofCalls = append(m.proofCalls, method.Name)
+
+		proof := contracts.IStateGistProof{Root: big.NewInt(1), Index: big.NewInt(0), Value: big.NewInt(0), AuxIndex: big.NewInt(0), AuxValue: big.NewInt(0)}
+		for i := range proof.Siblings {
+			proof.Siblings[i] = big.NewInt(0)
+		}
+
+		return method.Outputs.Pack(proof)
+	}
+
+	return nil, errors.Errorf("unexpected call %s", method.Name)
+}
+
+func newRootInfo(root *big.Int, createdAt int64) contracts.IStateGistRootInfo {
+	return contracts.IStateGistRootInfo{
+		Root:                root,
+		ReplacedByRoot:      big.NewInt(0),
+		CreatedAtTimestamp:  big.NewInt(createdAt),
+		ReplacedAtTimestamp: big.NewInt(0),
+		CreatedAtBlock:      big.NewInt(createdAt),
+		ReplacedAtBlock:     big.NewInt(0),
+	}
+}
+
+func newMockStates(t *testing.T) (*mockStates, *contracts.StateV2Caller, *contracts.LightweightStateV2Caller) {
+	sourceAbi, err := contracts.StateV2MetaData.GetAbi()
+	if err != nil {
+		t.Fatalf("Error parsing state abi: %v", err)
+	}
+
+	targetAbi, err := contracts.LightweightStateV2MetaData.GetAbi()
+	if err != nil {
+		t.Fatalf("Error parsing lightweight state abi: %v", err)
+	}
+
+	backend := &mockStates{sourceAbi: sourceAbi, targetAbi: targetAbi, targetRoots: map[string]bool{}, targetRoot: big.NewInt(0)}
+	for i := int64(1); i <= 40; i++ {
+		backend.history = append(backend.history, big.NewInt(i*100))
+	}
+
+	stateV2Caller, _ := contracts.NewStateV2Caller(sourceAddress, backend)
+	lightweightStateCaller, _ := contracts.NewLightweightStateV2Caller(targetAddress, backend)
+
+	return backend, stateV2Caller, lightweightStateCaller
+}
+
+func TestFindNewestCommonGISTRoot(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Should use current target root known to source", func(t *testing.T) {
+		backend, stateV2Caller, lightweightStateCaller := newMockStates(t)
+		backend.targetRoot = big.NewInt(3900)
+
+		root, err := FindNewestCommonGISTRoot(ctx, stateV2Caller, lightweightStateCaller, 64)
+		if err != nil || root.Int64() != 3900 {
+			t.Errorf("Unexpected root %v: %v", root, err)
+		}
+	})
+	t.Run("Should walk source history back", func(t *testing.T) {
+		backend, stateV2Caller, lightweightStateCaller := newMockStates(t)
+		backend.targetRoot = big.NewInt(7)
+		backend.targetRoots["500"] = true
+		backend.targetRoots["2000"] = true
+
+		root, err := FindNewestCommonGISTRoot(ctx, stateV2Caller, lightweightStateCaller, 64)
+		if err != nil || root.Int64() != 2000 {
+			t.Errorf("Unexpected root %v: %v", root, err)
+		}
+	})
+	t.Run("Should respect search depth", func(t *testing.T) {
+		backend, stateV2Caller, lightweightStateCaller := newMockStates(t)
+		backend.targetRoots["500"] = true
+
+		if _, err := FindNewestCommonGISTRoot(ctx, stateV2Caller, lightweightStateCaller, 20); !errors.Is(err, ErrNoCommonGISTRoot) {
+			t.Errorf("Expected no common root, got %v", err)
+		}
+	})
+}
+
+func TestGetGISTProofFromState(t *testing.T) {
+	backend, stateV2Caller, _ := newMockStates(t)
+
+	references := []GISTReference{LatestGIST(), GISTRoot(big.NewInt(100)), GISTAtTime(big.NewInt(1)), GISTAtBlock(big.NewInt(1))}
+	for _, reference := range references {
+		if _, err := GetGISTProofFromState(context.Background(), stateV2Caller, big.NewInt(1), reference); err != nil {
+			t.Fatalf("Error getting proof for %+v: %v", reference, err)
+		}
+	}
+
+	expected := []string{"getGISTProof", "getGISTProofByRoot", "getGISTProofByTime", "getGISTProofByBlock"}
+	for i, call := range expected {
+		if backend.proofCalls[i] != call {
+			t.Errorf("Expected %s, got %s", call, backend.proofCalls[i])
+		}
+	}
+
+	if _, err := GetGISTProofFromState(context.Background(), stateV2Caller, big.NewInt(1), GISTReference{}); err == nil {
+		t.Errorf("Expected error for zero reference")
+	}
+}
@@ -1,6 +1,7 @@
 package instances
 
 import (
+	"context"
 	"github.com/iden3/go-circuits/v2"
 	core "github.com/iden3/go-iden3-core/v2"
 	"github.com/iden3/go-iden3-core/v2/w3c"
@@ -8,10 +9,13 @@ import (
 	"github.com/iden3/go-merkletree-sql/v2"
 	merkletree_db_memory "github.com/iden3/go-merkletree-sql/v2/db/memory"
 	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"github.com/rarimo/zkp-iden3-exposer/evm/backend"
 	"github.com/rarimo/zkp-iden3-exposer/zkp/constants"
 	"github.com/rarimo/zkp-iden3-exposer/zkp/helpers"
 	"github.com/rarimo/zkp-iden3-exposer/zkp/types"
 	"math/big"
+	"net/http"
 )
 
 type IdentityConfig struct {
@@ -29,6 +33,12 @@ type Identity struct {
 	AuthClaimNonRevProof      *merkletree.Proof
 	TreeState                 *circuits.TreeState
 	CoreAuthClaim             *core.Claim
+
+	// GISTProofs caches the GIST proofs between the input builds, nil fetches every time
+	GISTProofs *helpers.GISTProofCache
+	// HttpClient and Backends reach the issuer and the chains, nil stands for the defaults
+	HttpClient *http.Client
+	Backends   *backend.Pool
 }
 
 func NewIdentity(config IdentityConfig, privateKeyHex *string) (*Identity, error) {
@@ -189,7 +199,7 @@ func (i *Identity) createCoreAuthClaim() (*core.Claim, error) {
 	return claim, nil
 }
 
-func (i *Identity) PrepareAuthV2Inputs(hash []byte, circuitID circuits.CircuitID) ([]byte, error) {
+func (i *Identity) PrepareAuthV2Inputs(ctx context.Context, hash []byte, circuitID circuits.CircuitID) ([]byte, error) {
 	hashBigInt := helpers.FromBigEndian(hash)
 
 	signature := i.PrivateKey.SignPoseidon(hashBigInt)
@@ -200,13 +210,19 @@ func (i *Identity) PrepareAuthV2Inputs(hash []byte, circuitID circuits.CircuitID
 		return nil, errors.Wrap(err, "failed to get ID")
 	}
 
-	gistProofRaw, err := helpers.GetGISTProof(
+	gistProofRaw, err := i.GISTProofs.GetGISTProof(
+		ctx,
+		i.Backends,
 		i.Config.ChainInfo.CoreEvmRpcApiUrl,
 		i.Config.ChainInfo.CoreStateContractAddress,
 		userId.BigInt(),
-		nil,
+		helpers.LatestGIST(),
 	)
 
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get GIST proof raw")
+	}
+
 	gistProof, err := helpers.ToGISTProof(*gistProofRaw)
 
 	if err != nil {
@@ -237,3 +253,116 @@ func (i *Identity) PrepareAuthV2Inputs(hash []byte, circuitID circuits.CircuitID
 
 	return encodedInputs, nil
 }
+
+// IdentityState Serialisable part of the identity, the private key and the config are kept by the caller
+type IdentityState struct {
+	DID                  string             `json:"did"`
+	AuthClaim            *core.Claim        `json:"authClaim"`
+	AuthClaimIncProof    *merkletree.Proof  `json:"authClaimIncProof"`
+	AuthClaimNonRevProof *merkletree.Proof  `json:"authClaimNonRevProof"`
+	TreeState            circuits.TreeState `json:"treeState"`
+}
+
+func (i *Identity) State() IdentityState {
+	return IdentityState{
+		DID:                  i.DID.String(),
+		AuthClaim:            i.CoreAuthClaim,
+		AuthClaimIncProof:    i.AuthClaimIncProof,
+		AuthClaimNonRevProof: i.AuthClaimNonRevProof,
+		TreeState:            *i.TreeState,
+	}
+}
+
+// ErrIdentityStateMismatch The restored state is not consistent or belongs to another identity
+var ErrIdentityStateMismatch = errcode.New(errcode.InvalidConfig, "identity state mismatch")
+
+// RestoreIdentity recreates the identity from the state without rebuilding its trees, the state
+// is checked to be consistent and to belong to the private key and the id type of the config
+func RestoreIdentity(config IdentityConfig, privateKeyHex string, state IdentityState) (*Identity, error) {
+	if state.AuthClaim == nil || state.AuthClaimIncProof == nil || state.AuthClaimNonRevProof == nil ||
+		state.TreeState.State == nil || state.TreeState.ClaimsRoot == nil ||
+		state.TreeState.RevocationRoot == nil || state.TreeState.RootOfRoots == nil {
+		return nil, errcode.New(errcode.InvalidConfig, "identity state is incomplete")
+	}
+
+	privateKey, err := helpers.InitSK(&privateKeyHex)
+
+	if err != nil {
+		return nil, err
+	}
+
+	identity := Identity{
+		Config:               config,
+		PrivateKey:           *privateKey,
+		AuthClaimIncProof:    state.AuthClaimIncProof,
+		AuthClaimNonRevProof: state.AuthClaimNonRevProof,
+		TreeState:            &state.TreeState,
+		CoreAuthClaim:        state.AuthClaim,
+	}
+
+	expectedAuthClaim, err := identity.createCoreAuthClaim()
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create auth claim")
+	}
+
+	if *expectedAuthClaim != *state.AuthClaim {
+		return nil, errors.Wrap(ErrIdentityStateMismatch, "state belongs to another private key")
+	}
+
+	if err := verifyTreeState(state); err != nil {
+		return nil, err
+	}
+
+	// the genesis DID is derived from the state, so this covers the id type as well
+	did, err := core.NewDIDFromIdenState(config.IdType, state.TreeState.State.BigInt())
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get DID from state")
+	}
+
+	if did.String() != state.DID {
+		return nil, errors.Wrap(ErrIdentityStateMismatch, "state does not match the DID or the id type")
+	}
+
+	identity.DID = *did
+	identity.AuthClaimIncProofSiblings = helpers.PrepareSiblingsStr(*state.AuthClaimIncProof, constants.DefaultMTLevels)
+
+	return &identity, nil
+}
+
+// verifyTreeState checks that the state hash is built from the roots, the auth claim is in the claims tree
+// and its revocation nonce is not in the revocation tree
+func verifyTreeState(state IdentityState) error {
+	treeState := state.TreeState
+
+	idenState, err := core.IdenState(treeState.ClaimsRoot.BigInt(), treeState.RevocationRoot.BigInt(), treeState.RootOfRoots.BigInt())
+
+	if err != nil {
+		return errors.Wrap(err, "failed to compute identity state")
+	}
+
+	if idenState.Cmp(treeState.State.BigInt()) != 0 {
+		return errors.Wrap(ErrIdentityStateMismatch, "state is not built from the tree roots")
+	}
+
+	hi, hv, err := state.AuthClaim.HiHv()
+
+	if err != nil {
+		return errors.Wrap(err, "failed to get auth claim hashes")
+	}
+
+	if !state.AuthClaimIncProof.Existence ||
+		!merkletree.VerifyProof(treeState.ClaimsRoot, state.AuthClaimIncProof, hi, hv) {
+		return errors.Wrap(ErrIdentityStateMismatch, "auth claim inclusion proof does not match the claims root")
+	}
+
+	revocationNonce := new(big.Int).SetUint64(state.AuthClaim.GetRevocationNonce())
+
+	if state.AuthClaimNonRevProof.Existence ||
+		!merkletree.VerifyProof(treeState.RevocationRoot, state.AuthClaimNonRevProof, revocationNonce, big.NewInt(0)) {
+		return errors.Wrap(ErrIdentityStateMismatch, "auth claim non-revocation proof does not match the revocation root")
+	}
+
+	return nil
+}
@@ -1,7 +1,13 @@
 package instances
 
 import (
+	"encoding/json"
+	core "github.com/iden3/go-iden3-core/v2"
+	"github.com/iden3/go-merkletree-sql/v2"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
 	"github.com/rarimo/zkp-iden3-exposer/zkp/types"
+	"math/big"
 	"testing"
 )
 
@@ -53,3 +59,85 @@ func TestNewIdentity(t *testing.T) {
 		}
 	})
 }
+
+func TestRestoreIdentity(t *testing.T) {
+	pkHex := "9a5305fa4c55cbf517c99693a7ec6766203c88feab50c944c00feec051d5dab7"
+	identity := getIdentity(&pkHex)
+
+	stateJson, err := json.Marshal(identity.State())
+	if err != nil {
+		t.Fatalf("Error marshalling state: %v", err)
+	}
+
+	state := IdentityState{}
+	if err := json.Unmarshal(stateJson, &state); err != nil {
+		t.Fatalf("Error unmarshalling state: %v", err)
+	}
+
+	t.Run("Should restore identity from state", func(t *testing.T) {
+		restored, err := RestoreIdentity(identity.Config, pkHex, state)
+		if err != nil {
+			t.Fatalf("Error restoring identity: %v", err)
+		}
+
+		if restored.DID.String() != identity.DID.String() {
+			t.Errorf("Expected: %v, got: %v", identity.DID.String(), restored.DID.String())
+		}
+
+		if restored.TreeState.State.String() != identity.TreeState.State.String() {
+			t.Errorf("Expected state %v, got %v", identity.TreeState.State, restored.TreeState.State)
+		}
+
+		if len(restored.AuthClaimIncProofSiblings) != len(identity.AuthClaimIncProofSiblings) {
+			t.Errorf("Expected %d siblings, got %d", len(identity.AuthClaimIncProofSiblings), len(restored.AuthClaimIncProofSiblings))
+		}
+	})
+
+	t.Run("Should reject state of another key", func(t *testing.T) {
+		otherPkHex := "1cbd5d2d1801e964736881fc0584473f23ba82669599ac65957fb4f2caf43e17"
+
+		if _, err := RestoreIdentity(identity.Config, otherPkHex, state); err == nil {
+			t.Errorf("Expected error")
+		}
+	})
+
+	t.Run("Should reject state of another id type", func(t *testing.T) {
+		config := identity.Config
+		config.IdType = [2]byte{0x01, 0x01}
+
+		if _, err := RestoreIdentity(config, pkHex, state); err == nil {
+			t.Errorf("Expected error")
+		}
+	})
+
+	t.Run("Should reject state not built from the roots", func(t *testing.T) {
+		tampered := state
+		tampered.TreeState.State, _ = merkletree.NewHashFromBigInt(big.NewInt(1))
+
+		_, err := RestoreIdentity(identity.Config, pkHex, tampered)
+		if !errors.Is(err, ErrIdentityStateMismatch) || errcode.Of(err) != errcode.InvalidConfig {
+			t.Errorf("Expected state mismatch, got %v", err)
+		}
+	})
+
+	t.Run("Should reject inclusion proof not matching the claims root", func(t *testing.T) {
+		tampered := state
+		tampered.TreeState.ClaimsRoot, _ = merkletree.NewHashFromBigInt(big.NewInt(1))
+
+		idenState, err := core.IdenState(
+			tampered.TreeState.ClaimsRoot.BigInt(),
+			tampered.TreeState.RevocationRoot.BigInt(),
+			tampered.TreeState.RootOfRoots.BigInt(),
+		)
+		if err != nil {
+			t.Fatalf("Error computing state: %v", err)
+		}
+
+		tampered.TreeState.State, _ = merkletree.NewHashFromBigInt(idenState)
+
+		_, err = RestoreIdentity(identity.Config, pkHex, tampered)
+		if !errors.Is(err, ErrIdentityStateMismatch) || errcode.Of(err) != errcode.InvalidConfig {
+			t.Errorf("Expected state mismatch, got %v", err)
+		}
+	})
+}
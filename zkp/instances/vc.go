@@ -1,13 +1,17 @@
 package instances
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/iden3/go-circuits/v2"
 	types2 "github.com/iden3/go-rapidsnark/types"
 	"github.com/iden3/go-schema-processor/v2/verifiable"
 	"github.com/pkg/errors"
 	"github.com/rarimo/go-jwz"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"github.com/rarimo/zkp-iden3-exposer/progress"
 	"github.com/rarimo/zkp-iden3-exposer/zkp/overrides"
 	"github.com/rarimo/zkp-iden3-exposer/zkp/types"
 	"net/http"
@@ -83,6 +87,7 @@ func GetClaimDetailsJson(claimOffer types.ClaimOffer) ([]byte, error) {
 }
 
 func GetAuthV2Inputs(
+	ctx context.Context,
 	identity Identity,
 	claimOffer types.ClaimOffer,
 ) ([]byte, error) {
@@ -93,7 +98,7 @@ func GetAuthV2Inputs(
 	}
 
 	preparer := jwz.ProofInputsPreparerHandlerFunc(func(hash []byte, circuitID circuits.CircuitID) ([]byte, error) {
-		return identity.PrepareAuthV2Inputs(hash, circuitID)
+		return identity.PrepareAuthV2Inputs(ctx, hash, circuitID)
 	})
 
 	token, err := jwz.NewWithPayload(
@@ -108,7 +113,7 @@ func GetAuthV2Inputs(
 		return nil, errors.Wrap(err, "Error getting message hash")
 	}
 
-	authV2Inputs, err := identity.PrepareAuthV2Inputs(messageHash, circuits.AuthV2CircuitID)
+	authV2Inputs, err := identity.PrepareAuthV2Inputs(ctx, messageHash, circuits.AuthV2CircuitID)
 
 	if err != nil {
 		return nil, errors.Wrap(err, "Error getting AuthV2Inputs")
@@ -118,12 +123,13 @@ func GetAuthV2Inputs(
 }
 
 func GetJWZToken(
+	ctx context.Context,
 	identity Identity,
 	claimDetailsJson []byte,
 	proofRaw []byte,
 ) (*string, error) {
 	preparer := jwz.ProofInputsPreparerHandlerFunc(func(hash []byte, circuitID circuits.CircuitID) ([]byte, error) {
-		return identity.PrepareAuthV2Inputs(hash, circuitID)
+		return identity.PrepareAuthV2Inputs(ctx, hash, circuitID)
 	})
 
 	token, err := jwz.NewWithPayload(
@@ -157,19 +163,37 @@ func GetJWZToken(
 	return &jwzToken, nil
 }
 
-func LoadVC(url string, jwzToken string) (*overrides.W3CCredential, error) {
-	response, err := http.Post(url, "application/json", strings.NewReader(jwzToken))
+func LoadVC(ctx context.Context, httpClient *http.Client, url string, jwzToken string) (_ *overrides.W3CCredential, err error) {
+	done := progress.Track(ctx, progress.StepIssuerAgent)
+	defer func() { done(err) }()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(jwzToken))
 
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to post")
+		return nil, errors.Wrap(err, "failed to create request")
 	}
 
-	if response.StatusCode != http.StatusOK {
-		return nil, errors.New("response status is not OK")
+	request.Header.Set("Content-Type", "application/json")
+
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	response, err := httpClient.Do(request)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to post")
 	}
 
 	defer response.Body.Close()
 
+	if response.StatusCode != http.StatusOK {
+		return nil, errcode.New(
+			errcode.FromHttpStatus(response.StatusCode, errcode.IssuerRejected),
+			fmt.Sprintf("issuer responded with status %d", response.StatusCode),
+		)
+	}
+
 	type AgentResponse struct {
 		Body struct {
 			Credential overrides.W3CCredential `json:"credential"`
@@ -1,6 +1,7 @@
 package instances
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/iden3/go-circuits/v2"
 	"github.com/iden3/go-jwz/v2"
@@ -79,7 +80,7 @@ func GetVC(identity Identity, offer types.ClaimOffer) (*overrides.W3CCredential,
 	}
 
 	preparer := jwz.ProofInputsPreparerHandlerFunc(func(hash []byte, circuitID circuits.CircuitID) ([]byte, error) {
-		return identity.PrepareAuthV2Inputs(hash, circuitID)
+		return identity.PrepareAuthV2Inputs(context.Background(), hash, circuitID)
 	})
 
 	token, err := jwz.NewWithPayload(
@@ -98,7 +99,7 @@ func GetVC(identity Identity, offer types.ClaimOffer) (*overrides.W3CCredential,
 		return nil, errors.Wrap(err, "Error getting JWZ token")
 	}
 
-	vc, err := LoadVC(offer.Body.Url, jwzTokenRaw)
+	vc, err := LoadVC(context.Background(), nil, offer.Body.Url, jwzTokenRaw)
 
 	if err != nil {
 		return nil, errors.Wrap(err, "Error getting vc")
@@ -124,7 +125,7 @@ func TestGetVerifiableCredentials(t *testing.T) {
 	})
 
 	t.Run("should get AuthV2 inputs", func(t *testing.T) {
-		_, err := GetAuthV2Inputs(identity, offer)
+		_, err := GetAuthV2Inputs(context.Background(), identity, offer)
 
 		if err != nil {
 			t.Errorf("Error: %v", err)
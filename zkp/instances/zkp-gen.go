@@ -1,6 +1,7 @@
 package instances
 
 import (
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"github.com/iden3/go-circuits/v2"
@@ -8,14 +9,20 @@ import (
 	"github.com/iden3/go-iden3-core/v2/w3c"
 	"github.com/iden3/go-schema-processor/v2/verifiable"
 	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"github.com/rarimo/zkp-iden3-exposer/progress"
 	"github.com/rarimo/zkp-iden3-exposer/zkp/helpers"
 	"github.com/rarimo/zkp-iden3-exposer/zkp/overrides"
 	"github.com/rarimo/zkp-iden3-exposer/zkp/types"
 	"math/big"
+	"net/http"
+	"strings"
 	"time"
 )
 
 func prepareCommonInputs(
+	ctx context.Context,
+	httpClient *http.Client,
 	coreStateHash string,
 	vc overrides.W3CCredential,
 	proofRequest types.CreateProofRequest,
@@ -35,12 +42,19 @@ func prepareCommonInputs(
 	resolver := helpers.CredentialStatusResolver{
 		Url:                        credStatus.ID,
 		EndianSwappedCoreStateHash: nil,
+		HttpClient:                 httpClient,
 	}
 
 	verifiable.DefaultCredentialStatusResolverRegistry.Register(verifiable.SparseMerkleTreeProof, &resolver)
 	//verifiable.DefaultCredentialStatusResolverRegistry.Register(verifiable.BJJSignatureProofType, &resolver) // FIXME
 
-	revStatus, err := verifiable.ValidateCredentialStatus(nil, credStatus)
+	done := progress.Track(ctx, progress.StepCredentialStatus)
+	revStatus, err := verifiable.ValidateCredentialStatus(ctx, credStatus)
+	done(err)
+
+	if errors.Is(err, verifiable.ErrCredentialIsRevoked) {
+		return nil, nil, errcode.Wrap(err, errcode.CredentialRevoked, "failed to validate credential status")
+	}
 
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to validate credential status")
@@ -59,10 +73,12 @@ func prepareCommonInputs(
 		return nil, nil, errors.Wrap(err, "failed to get core claim from vc")
 	}
 
-	query, err := helpers.ConvertProofRequestToCircuitQuery(&vc, &proofRequest)
+	done = progress.Track(ctx, progress.StepJsonLdContexts)
+	query, err := helpers.ConvertProofRequestToCircuitQuery(ctx, httpClient, &vc, &proofRequest)
+	done(err)
 
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "failed to convert proof request to circuit query")
+		return nil, nil, errcode.Classify(err, errcode.InvalidQuery, "failed to convert proof request to circuit query")
 	}
 
 	claimWithMTPProof := circuits.ClaimWithMTPProof{}
@@ -105,7 +121,9 @@ func prepareCommonInputs(
 		return nil, nil, errors.Wrap(err, "failed to convert endian swapped core state hash")
 	}
 
-	smtRevStatus, err := helpers.GetRevocationStatus(smtProof.ID, stateHashEndian)
+	done = progress.Track(ctx, progress.StepRevocationStatus)
+	smtRevStatus, err := helpers.GetRevocationStatus(ctx, httpClient, smtProof.ID, stateHashEndian)
+	done(err)
 
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to get revocation status")
@@ -118,6 +136,10 @@ func prepareCommonInputs(
 		*smtRevStatus.Issuer.RootOfRoots,
 	)
 
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to build revocation status issuer tree state")
+	}
+
 	incProof := circuits.MTProof{
 		Proof:     &smtRevStatus.MTP,
 		TreeState: *smtRevStatusTreeState,
@@ -149,9 +171,11 @@ type AtomicQueryMTPV2OnChainProof struct {
 
 	CoreStateHash     string
 	OperationGistHash string
-	VC                overrides.W3CCredential
-	ProofRequest      types.CreateProofRequest
-	Circuits          types.CircuitPair
+	// GISTReference overrides the operation GIST root when set
+	GISTReference helpers.GISTReference
+	VC            overrides.W3CCredential
+	ProofRequest  types.CreateProofRequest
+	Circuits      types.CircuitPair
 }
 
 func NewAtomicQueryMTPV2OnChainProof(
@@ -170,8 +194,12 @@ func NewAtomicQueryMTPV2OnChainProof(
 	}
 }
 
-func (a *AtomicQueryMTPV2OnChainProof) GetInputs() ([]byte, error) {
-	claimWithMTPProof, query, err := prepareCommonInputs(a.CoreStateHash, a.VC, a.ProofRequest)
+func (a *AtomicQueryMTPV2OnChainProof) GetInputs(ctx context.Context) ([]byte, error) {
+	claimWithMTPProof, query, err := prepareCommonInputs(ctx, a.Identity.HttpClient, a.CoreStateHash, a.VC, a.ProofRequest)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare common inputs")
+	}
 
 	userId, err := a.Identity.ID()
 
@@ -179,19 +207,25 @@ func (a *AtomicQueryMTPV2OnChainProof) GetInputs() ([]byte, error) {
 		return nil, errors.Wrap(err, "failed to get ID")
 	}
 
-	operationGistHashBigInt := new(big.Int)
+	gistReference := a.GISTReference
 
-	operationGistHashBigInt.SetString(a.OperationGistHash, 16)
+	if gistReference.IsZero() {
+		operationGistHashBigInt, ok := new(big.Int).SetString(strings.TrimPrefix(a.OperationGistHash, "0x"), 16)
 
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to get hash from operationGistHash hex")
+		if !ok {
+			return nil, errors.New("failed to get hash from operationGistHash hex")
+		}
+
+		gistReference = helpers.GISTRoot(operationGistHashBigInt)
 	}
 
-	gistProofRaw, err := helpers.GetGISTProof(
+	gistProofRaw, err := a.Identity.GISTProofs.GetGISTProof(
+		ctx,
+		a.Identity.Backends,
 		a.Identity.Config.ChainInfo.CoreEvmRpcApiUrl,
 		a.Identity.Config.ChainInfo.CoreStateContractAddress,
 		userId.BigInt(),
-		operationGistHashBigInt,
+		gistReference,
 	)
 
 	if err != nil {
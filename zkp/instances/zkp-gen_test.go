@@ -1,14 +1,22 @@
 package instances
 
 import (
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"github.com/iden3/go-circuits/v2"
 	core "github.com/iden3/go-iden3-core/v2"
 	"github.com/iden3/go-iden3-core/v2/w3c"
 	"github.com/iden3/go-jwz/v2"
+	"github.com/iden3/go-merkletree-sql/v2"
+	merkletree_db_memory "github.com/iden3/go-merkletree-sql/v2/db/memory"
+	"github.com/iden3/go-schema-processor/v2/verifiable"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/overrides"
 	"github.com/rarimo/zkp-iden3-exposer/zkp/types"
+	"math/big"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -123,7 +131,7 @@ func TestGenerateProof(t *testing.T) {
 			proofRequest,
 		)
 
-		inputs, err := atomicQueryMTPV2OnChainProof.GetInputs()
+		inputs, err := atomicQueryMTPV2OnChainProof.GetInputs(context.Background())
 
 		if err != nil {
 			t.Errorf("Error getting inputs: %v", err)
@@ -136,3 +144,76 @@ func TestGenerateProof(t *testing.T) {
 		}
 	})
 }
+
+// newRevokedStatusServer serves the revocation status proving the nonce is in the issuer revocation tree
+func newRevokedStatusServer(t *testing.T, revocationNonce uint64) *httptest.Server {
+	ctx := context.Background()
+
+	revocationsTree, err := merkletree.NewMerkleTree(ctx, merkletree_db_memory.NewMemoryStorage(), 40)
+	if err != nil {
+		t.Fatalf("Error creating revocations tree: %v", err)
+	}
+
+	nonce := new(big.Int).SetUint64(revocationNonce)
+	if err := revocationsTree.Add(ctx, nonce, big.NewInt(0)); err != nil {
+		t.Fatalf("Error revoking nonce: %v", err)
+	}
+
+	proof, _, err := revocationsTree.GenerateProof(ctx, nonce, nil)
+	if err != nil {
+		t.Fatalf("Error generating proof: %v", err)
+	}
+
+	revocationRoot := revocationsTree.Root()
+
+	state, err := core.IdenState(big.NewInt(0), revocationRoot.BigInt(), big.NewInt(0))
+	if err != nil {
+		t.Fatalf("Error computing state: %v", err)
+	}
+
+	stateHash, _ := merkletree.NewHashFromBigInt(state)
+	stateHex, revocationRootHex, zeroHex := stateHash.Hex(), revocationRoot.Hex(), merkletree.HashZero.Hex()
+
+	statusJson, err := json.Marshal(verifiable.RevocationStatus{
+		Issuer: verifiable.TreeState{
+			State:              &stateHex,
+			ClaimsTreeRoot:     &zeroHex,
+			RevocationTreeRoot: &revocationRootHex,
+			RootOfRoots:        &zeroHex,
+		},
+		MTP: *proof,
+	})
+	if err != nil {
+		t.Fatalf("Error marshalling status: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(statusJson)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestAtomicQueryMTPV2OnChainInputs(t *testing.T) {
+	identity := getIdentity(nil)
+
+	t.Run("Should report revoked credential", func(t *testing.T) {
+		server := newRevokedStatusServer(t, 42)
+
+		vc := overrides.W3CCredential{}
+		vc.CredentialStatus = verifiable.CredentialStatus{
+			ID:              server.URL,
+			Type:            verifiable.SparseMerkleTreeProof,
+			RevocationNonce: 42,
+		}
+
+		proof := NewAtomicQueryMTPV2OnChainProof(identity, "0x00", "0x00", vc, types.CreateProofRequest{
+			CircuitId: circuits.AtomicQueryMTPV2OnChainCircuitID,
+		})
+
+		if _, err := proof.GetInputs(context.Background()); errcode.Of(err) != errcode.CredentialRevoked {
+			t.Errorf("Expected %s, got %v", errcode.CredentialRevoked, err)
+		}
+	})
+}
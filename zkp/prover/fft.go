@@ -0,0 +1,132 @@
+package prover
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"math/big"
+	"math/bits"
+)
+
+// twoAdicity is the largest s such that 2^s divides r - 1 for the bn254 scalar field
+const twoAdicity = 28
+
+// nonResidue is the smallest quadratic non-residue of the scalar field,
+// snarkjs derives its roots of unity from it, so the zkey H points are bound to the same choice
+var nonResidue = func() fr.Element {
+	modulus := fr.Modulus()
+
+	halfOrder := new(big.Int).Sub(modulus, big.NewInt(1))
+	halfOrder.Rsh(halfOrder, 1)
+
+	one := fr.One()
+
+	for candidate := uint64(2); ; candidate++ {
+		element := fr.Element{}
+		element.SetUint64(candidate)
+
+		legendre := fr.Element{}
+		legendre.Exp(element, halfOrder)
+
+		if !legendre.Equal(&one) {
+			return element
+		}
+	}
+}()
+
+// rootOfUnity returns the primitive 2^power-th root of unity as snarkjs computes it
+func rootOfUnity(power int) fr.Element {
+	oddFactor := new(big.Int).Sub(fr.Modulus(), big.NewInt(1))
+	oddFactor.Rsh(oddFactor, twoAdicity)
+
+	root := fr.Element{}
+	root.Exp(nonResidue, oddFactor)
+
+	for i := twoAdicity; i > power; i-- {
+		root.Square(&root)
+	}
+
+	return root
+}
+
+// cosetShift returns the shift of the odd coset snarkjs evaluates the quotient polynomial on
+func cosetShift(power int) fr.Element {
+	if power == twoAdicity {
+		shift := fr.Element{}
+		return *shift.Square(&nonResidue)
+	}
+
+	return rootOfUnity(power + 1)
+}
+
+func bitReverse(values []fr.Element) {
+	n := uint64(len(values))
+	shift := 64 - uint64(bits.TrailingZeros64(n))
+
+	for i := uint64(0); i < n; i++ {
+		j := bits.Reverse64(i) >> shift
+
+		if i < j {
+			values[i], values[j] = values[j], values[i]
+		}
+	}
+}
+
+// fft evaluates the polynomial given by its coefficients on the powers of root in place
+func fft(values []fr.Element, root fr.Element) {
+	n := len(values)
+
+	bitReverse(values)
+
+	for size := 2; size <= n; size <<= 1 {
+		step := fr.Element{}
+		step.Exp(root, big.NewInt(int64(n/size)))
+
+		half := size / 2
+
+		twiddles := make([]fr.Element, half)
+		twiddles[0].SetOne()
+
+		for i := 1; i < half; i++ {
+			twiddles[i].Mul(&twiddles[i-1], &step)
+		}
+
+		for start := 0; start < n; start += size {
+			for i := 0; i < half; i++ {
+				t := fr.Element{}
+				t.Mul(&values[start+i+half], &twiddles[i])
+
+				values[start+i+half].Sub(&values[start+i], &t)
+				values[start+i].Add(&values[start+i], &t)
+			}
+		}
+	}
+}
+
+// ifft interpolates the coefficients of the polynomial from its evaluations on the powers of root in place
+func ifft(values []fr.Element, root fr.Element) {
+	inverseRoot := fr.Element{}
+	inverseRoot.Inverse(&root)
+
+	fft(values, inverseRoot)
+
+	inverseSize := fr.Element{}
+	inverseSize.SetUint64(uint64(len(values)))
+	inverseSize.Inverse(&inverseSize)
+
+	for i := range values {
+		values[i].Mul(&values[i], &inverseSize)
+	}
+}
+
+// evaluateOnCoset maps the evaluations on the domain to the evaluations on the domain shifted by shift in place
+func evaluateOnCoset(values []fr.Element, root fr.Element, shift fr.Element) {
+	ifft(values, root)
+
+	power := fr.One()
+
+	for i := range values {
+		values[i].Mul(&values[i], &power)
+		power.Mul(&power, &shift)
+	}
+
+	fft(values, root)
+}
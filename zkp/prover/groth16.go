@@ -0,0 +1,169 @@
+package prover
+
+import (
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/iden3/go-rapidsnark/types"
+	"github.com/pkg/errors"
+	"math/big"
+	"math/bits"
+)
+
+func frToString(element *fr.Element) string {
+	return element.BigInt(new(big.Int)).String()
+}
+
+func g1ToStrings(point *bn254.G1Affine) []string {
+	return []string{
+		point.X.BigInt(new(big.Int)).String(),
+		point.Y.BigInt(new(big.Int)).String(),
+		"1",
+	}
+}
+
+func g2ToStrings(point *bn254.G2Affine) [][]string {
+	return [][]string{
+		{point.X.A0.BigInt(new(big.Int)).String(), point.X.A1.BigInt(new(big.Int)).String()},
+		{point.Y.A0.BigInt(new(big.Int)).String(), point.Y.A1.BigInt(new(big.Int)).String()},
+		{"1", "0"},
+	}
+}
+
+// quotientEvaluations computes the evaluations of (A*B - C)/Z on the odd coset of the domain,
+// the division by Z is already folded into the zkey H points
+func quotientEvaluations(pk *ProvingKey, witness []fr.Element) []fr.Element {
+	a := make([]fr.Element, pk.DomainSize)
+	b := make([]fr.Element, pk.DomainSize)
+	c := make([]fr.Element, pk.DomainSize)
+
+	for _, coef := range pk.Coefficients {
+		term := fr.Element{}
+		term.Mul(&coef.Value, &witness[coef.Signal])
+
+		if coef.Matrix == 0 {
+			a[coef.Constraint].Add(&a[coef.Constraint], &term)
+		} else {
+			b[coef.Constraint].Add(&b[coef.Constraint], &term)
+		}
+	}
+
+	for i := range c {
+		c[i].Mul(&a[i], &b[i])
+	}
+
+	power := bits.TrailingZeros32(pk.DomainSize)
+	root := rootOfUnity(power)
+	shift := cosetShift(power)
+
+	evaluateOnCoset(a, root, shift)
+	evaluateOnCoset(b, root, shift)
+	evaluateOnCoset(c, root, shift)
+
+	for i := range a {
+		a[i].Mul(&a[i], &b[i])
+		a[i].Sub(&a[i], &c[i])
+	}
+
+	return a
+}
+
+// ProveGroth16 generates the groth16 proof for the full circuit witness
+func ProveGroth16(pk *ProvingKey, witness []*big.Int) (*types.ZKProof, error) {
+	if len(witness) != int(pk.NVars) {
+		return nil, errors.Errorf("witness length %d does not match variables count %d", len(witness), pk.NVars)
+	}
+
+	wit := make([]fr.Element, len(witness))
+	for i, value := range witness {
+		wit[i].SetBigInt(value)
+	}
+
+	h := quotientEvaluations(pk, wit)
+
+	var r, s fr.Element
+
+	if _, err := r.SetRandom(); err != nil {
+		return nil, errors.Wrap(err, "failed to generate r")
+	}
+
+	if _, err := s.SetRandom(); err != nil {
+		return nil, errors.Wrap(err, "failed to generate s")
+	}
+
+	rBigInt := r.BigInt(new(big.Int))
+	sBigInt := s.BigInt(new(big.Int))
+
+	rs := fr.Element{}
+	rs.Mul(&r, &s)
+
+	config := ecc.MultiExpConfig{}
+
+	// pi_a = alpha1 + sum(w_i * A_i) + r * delta1
+	piA := bn254.G1Jac{}
+	if _, err := piA.MultiExp(pk.A, wit, config); err != nil {
+		return nil, errors.Wrap(err, "failed to compute pi_a")
+	}
+
+	piA.AddMixed(&pk.Alpha1)
+	piA.AddAssign(new(bn254.G1Jac).ScalarMultiplicationAffine(&pk.Delta1, rBigInt))
+
+	// pi_b = beta2 + sum(w_i * B2_i) + s * delta2
+	piB := bn254.G2Jac{}
+	if _, err := piB.MultiExp(pk.B2, wit, config); err != nil {
+		return nil, errors.Wrap(err, "failed to compute pi_b")
+	}
+
+	delta2 := bn254.G2Jac{}
+	delta2.FromAffine(&pk.Delta2)
+
+	piB.AddMixed(&pk.Beta2)
+	piB.AddAssign(delta2.ScalarMultiplication(&delta2, sBigInt))
+
+	// pi_b in G1 is only needed to blind pi_c
+	piB1 := bn254.G1Jac{}
+	if _, err := piB1.MultiExp(pk.B1, wit, config); err != nil {
+		return nil, errors.Wrap(err, "failed to compute pi_b in G1")
+	}
+
+	piB1.AddMixed(&pk.Beta1)
+	piB1.AddAssign(new(bn254.G1Jac).ScalarMultiplicationAffine(&pk.Delta1, sBigInt))
+
+	// pi_c = sum(w_i * C_i) + sum(h_i * H_i) + s * pi_a + r * pi_b1 - r * s * delta1
+	piC := bn254.G1Jac{}
+	if _, err := piC.MultiExp(pk.C, wit[pk.NPublic+1:], config); err != nil {
+		return nil, errors.Wrap(err, "failed to compute pi_c")
+	}
+
+	piH := bn254.G1Jac{}
+	if _, err := piH.MultiExp(pk.H, h, config); err != nil {
+		return nil, errors.Wrap(err, "failed to compute pi_h")
+	}
+
+	piC.AddAssign(&piH)
+	piC.AddAssign(new(bn254.G1Jac).ScalarMultiplication(&piA, sBigInt))
+	piC.AddAssign(new(bn254.G1Jac).ScalarMultiplication(&piB1, rBigInt))
+	piC.SubAssign(new(bn254.G1Jac).ScalarMultiplicationAffine(&pk.Delta1, rs.BigInt(new(big.Int))))
+
+	var a, c bn254.G1Affine
+	var b bn254.G2Affine
+
+	a.FromJacobian(&piA)
+	b.FromJacobian(&piB)
+	c.FromJacobian(&piC)
+
+	pubSignals := make([]string, pk.NPublic)
+	for i := range pubSignals {
+		pubSignals[i] = frToString(&wit[i+1])
+	}
+
+	return &types.ZKProof{
+		Proof: &types.ProofData{
+			A:        g1ToStrings(&a),
+			B:        g2ToStrings(&b),
+			C:        g1ToStrings(&c),
+			Protocol: "groth16",
+		},
+		PubSignals: pubSignals,
+	}, nil
+}
@@ -0,0 +1,107 @@
+package prover
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/iden3/go-rapidsnark/verifier"
+	"math/big"
+	"testing"
+)
+
+func TestProveGroth16(t *testing.T) {
+	setup := setupGroth16(t)
+
+	pk, err := ParseProvingKey(setup.Zkey)
+	if err != nil {
+		t.Fatalf("Error parsing proving key: %v", err)
+	}
+
+	t.Run("Should generate valid proof", func(t *testing.T) {
+		proof, err := ProveGroth16(pk, testWitness(2, 5))
+		if err != nil {
+			t.Fatalf("Error generating proof: %v", err)
+		}
+
+		if len(proof.PubSignals) != 2 || proof.PubSignals[0] != "5412" || proof.PubSignals[1] != "2" {
+			t.Errorf("Unexpected public signals %v", proof.PubSignals)
+		}
+
+		if err := verifier.VerifyGroth16(*proof, setup.VerificationKey); err != nil {
+			t.Errorf("Error verifying proof: %v", err)
+		}
+	})
+	t.Run("Should generate valid proof for large values", func(t *testing.T) {
+		// the field wraps the intermediate signals, so the quotient polynomial is far from trivial
+		a, b := new(big.Int).Sub(fr.Modulus(), big.NewInt(7)), big.NewInt(1<<40)
+
+		t1 := new(big.Int).Mul(big.NewInt(3), a)
+		t1.Mul(t1, b).Mod(t1, fr.Modulus())
+		t2 := new(big.Int).Mul(t1, t1)
+		t2.Mod(t2, fr.Modulus())
+		out := new(big.Int).Add(t2, a)
+		out.Mul(out, new(big.Int).Add(b, big.NewInt(1))).Mod(out, fr.Modulus())
+
+		proof, err := ProveGroth16(pk, []*big.Int{big.NewInt(1), out, a, b, t1, t2})
+		if err != nil {
+			t.Fatalf("Error generating proof: %v", err)
+		}
+
+		if err := verifier.VerifyGroth16(*proof, setup.VerificationKey); err != nil {
+			t.Errorf("Error verifying proof: %v", err)
+		}
+	})
+	t.Run("Should not prove unsatisfied witness", func(t *testing.T) {
+		witness := testWitness(2, 5)
+		witness[1] = big.NewInt(5413)
+
+		proof, err := ProveGroth16(pk, witness)
+		if err != nil {
+			t.Fatalf("Error generating proof: %v", err)
+		}
+
+		if err := verifier.VerifyGroth16(*proof, setup.VerificationKey); err == nil {
+			t.Errorf("Expected proof of unsatisfied witness to be rejected")
+		}
+	})
+	t.Run("Should reject tampered public signals", func(t *testing.T) {
+		proof, err := ProveGroth16(pk, testWitness(3, 4))
+		if err != nil {
+			t.Fatalf("Error generating proof: %v", err)
+		}
+
+		proof.PubSignals[1] = "4"
+
+		if err := verifier.VerifyGroth16(*proof, setup.VerificationKey); err == nil {
+			t.Errorf("Expected proof with tampered public signals to be rejected")
+		}
+	})
+	t.Run("Should reject witness of wrong length", func(t *testing.T) {
+		if _, err := ProveGroth16(pk, testWitness(2, 5)[:5]); err == nil {
+			t.Errorf("Expected error for short witness")
+		}
+	})
+}
+
+func TestRootOfUnity(t *testing.T) {
+	t.Run("Should match snarkjs root of unity", func(t *testing.T) {
+		root := rootOfUnity(twoAdicity)
+
+		if frToString(&root) != "19103219067921713944291392827692070036145651957329286315305642004821462161904" {
+			t.Errorf("Unexpected root of unity %s", frToString(&root))
+		}
+	})
+	t.Run("Should derive smaller roots by squaring", func(t *testing.T) {
+		root, half := rootOfUnity(3), rootOfUnity(4)
+		half.Square(&half)
+
+		if !root.Equal(&half) {
+			t.Errorf("Root of 2^3 is not the square of the root of 2^4")
+		}
+
+		eighth := fr.Element{}
+		eighth.Exp(root, big.NewInt(8))
+
+		if !eighth.IsOne() {
+			t.Errorf("Root of 2^3 is not of order 8")
+		}
+	})
+}
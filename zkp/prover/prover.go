@@ -0,0 +1,152 @@
+package prover
+
+import (
+	"crypto/sha256"
+	"github.com/iden3/go-circuits/v2"
+	"github.com/iden3/go-rapidsnark/types"
+	"github.com/iden3/go-rapidsnark/witness/v2"
+	"github.com/iden3/go-rapidsnark/witness/wazero"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	zkpTypes "github.com/rarimo/zkp-iden3-exposer/zkp/types"
+	"io"
+	"math/big"
+	"sync"
+)
+
+// Prover calculates the witness for the circuit inputs and generates the groth16 proof
+type Prover interface {
+	Prove(circuitId circuits.CircuitID, inputs []byte) (*types.ZKProof, error)
+}
+
+// CircuitLoader resolves the wasm and proving key of the circuit
+type CircuitLoader interface {
+	LoadCircuit(circuitId circuits.CircuitID) (*zkpTypes.CircuitPair, error)
+}
+
+// ProvingKeyLoader is implemented by the CircuitLoader that keeps the parsed proving keys
+type ProvingKeyLoader interface {
+	LoadProvingKey(circuitId circuits.CircuitID) (*ProvingKey, error)
+}
+
+// CircuitPairs is the in-memory CircuitLoader
+type CircuitPairs map[circuits.CircuitID]zkpTypes.CircuitPair
+
+func (c CircuitPairs) LoadCircuit(circuitId circuits.CircuitID) (*zkpTypes.CircuitPair, error) {
+	circuitPair, ok := c[circuitId]
+	if !ok {
+		return nil, errcode.New(errcode.CircuitArtifact, "circuit "+string(circuitId)+" is not loaded")
+	}
+
+	return &circuitPair, nil
+}
+
+// Groth16Prover is the pure Go Prover, the witness is calculated by the wazero runtime
+type Groth16Prover struct {
+	Circuits CircuitLoader
+
+	cacheMutex sync.Mutex
+	cache      map[[sha256.Size]byte]witness.CalculatorImpl
+}
+
+func NewGroth16Prover(circuits CircuitLoader) *Groth16Prover {
+	return &Groth16Prover{
+		Circuits: circuits,
+		cache:    make(map[[sha256.Size]byte]witness.CalculatorImpl),
+	}
+}
+
+func (p *Groth16Prover) Prove(circuitId circuits.CircuitID, inputs []byte) (*types.ZKProof, error) {
+	circuitPair, err := p.Circuits.LoadCircuit(circuitId)
+	if err != nil {
+		return nil, errcode.Classify(err, errcode.CircuitArtifact, "failed to load circuit")
+	}
+
+	wit, err := p.CalculateWitness(circuitPair.Wasm, inputs)
+	if err != nil {
+		return nil, errcode.Classify(err, errcode.ProverFailed, "failed to calculate witness")
+	}
+
+	provingKey, err := p.provingKey(circuitId, circuitPair)
+	if err != nil {
+		return nil, errcode.Classify(err, errcode.CircuitArtifact, "failed to parse proving key")
+	}
+
+	proof, err := ProveGroth16(provingKey, wit)
+	if err != nil {
+		return nil, errcode.Classify(err, errcode.ProverFailed, "failed to generate proof")
+	}
+
+	return proof, nil
+}
+
+func (p *Groth16Prover) provingKey(circuitId circuits.CircuitID, circuitPair *zkpTypes.CircuitPair) (*ProvingKey, error) {
+	if loader, ok := p.Circuits.(ProvingKeyLoader); ok {
+		return loader.LoadProvingKey(circuitId)
+	}
+
+	return ParseProvingKey(circuitPair.ProvingKey)
+}
+
+// CalculateWitness calculates the full witness of the circuit for the json encoded inputs
+func (p *Groth16Prover) CalculateWitness(wasm []byte, inputs []byte) ([]*big.Int, error) {
+	calculator, err := p.witnessCalculator(wasm)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create witness calculator")
+	}
+
+	parsedInputs, err := witness.ParseInputs(inputs)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse inputs")
+	}
+
+	wit, err := calculator.Calculate(parsedInputs, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to calculate witness")
+	}
+
+	return wit.Witness, nil
+}
+
+// witnessCalculator instantiates the wasm module once per circuit, compilation takes longer than the calculation itself
+func (p *Groth16Prover) witnessCalculator(wasm []byte) (witness.CalculatorImpl, error) {
+	moduleId := sha256.Sum256(wasm)
+
+	p.cacheMutex.Lock()
+	defer p.cacheMutex.Unlock()
+
+	if p.cache == nil {
+		p.cache = make(map[[sha256.Size]byte]witness.CalculatorImpl)
+	}
+
+	if calculator, ok := p.cache[moduleId]; ok {
+		return calculator, nil
+	}
+
+	calculator, err := wazero.NewCircom2WZWitnessCalculator(wasm)
+	if err != nil {
+		return nil, err
+	}
+
+	p.cache[moduleId] = calculator
+
+	return calculator, nil
+}
+
+// Close releases the wasm runtimes of the cached witness calculators
+func (p *Groth16Prover) Close() error {
+	p.cacheMutex.Lock()
+	defer p.cacheMutex.Unlock()
+
+	for moduleId, calculator := range p.cache {
+		if closer, ok := calculator.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				return errors.Wrap(err, "failed to close witness calculator")
+			}
+		}
+
+		delete(p.cache, moduleId)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package prover
+
+import (
+	"encoding/json"
+	"github.com/iden3/go-circuits/v2"
+	"github.com/iden3/go-rapidsnark/verifier"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/types"
+	"os"
+	"testing"
+)
+
+const authV2Inputs = `{"genesisID":"19229084873704550357232887142774605442297337229176579229011342091594174977","profileNonce":"0","authClaim":["301485908906857522017021291028488077057","0","4720763745722683616702324599137259461509439547324750011830105416383780791263","4844030361230692908091131578688419341633213823133966379083981236400104720538","16547485850637761685","0","0","0"],"authClaimIncMtp":["0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0"],"authClaimNonRevMtp":["0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0"],"authClaimNonRevMtpAuxHi":"0","authClaimNonRevMtpAuxHv":"0","authClaimNonRevMtpNoAux":"1","challenge":"6110517768249559238193477435454792024732173865488900270849624328650765691494","challengeSignatureR8x":"10923900855019966925146890192107445603460581432515833977084358496785417078889","challengeSignatureR8y":"16158862443157007045624936621448425746188316255879806600364391221203989186031","challengeSignatureS":"51416591880507739389339515804072924841765472826035808894700970942045022090","claimsTreeRoot":"5156125448952672817978035354327403409438120028299513459509442000229340486813","revTreeRoot":"0","rootsTreeRoot":"0","state":"13749793311041076104545663747883540987785640262360452307923674522221753800226","gistRoot":"1243904711429961858774220647610724273798918457991486031567244100767259239747","gistMtp":["0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0","0"],"gistMtpAuxHi":"1","gistMtpAuxHv":"1","gistMtpNoAux":"0"}`
+
+func TestGroth16Prover(t *testing.T) {
+	wasm, err := os.ReadFile("../assets/circuits/auth/circuit.wasm")
+	if err != nil {
+		t.Fatalf("Error getting wasm file: %v", err)
+	}
+
+	// the AuthV2 proving key is too large to be bundled, the proving itself is covered by TestProveGroth16
+	provingKey, provingKeyErr := os.ReadFile("../assets/circuits/auth/circuit_final.zkey")
+
+	prover := NewGroth16Prover(CircuitPairs{
+		circuits.AuthV2CircuitID: types.CircuitPair{
+			Wasm:       wasm,
+			ProvingKey: provingKey,
+		},
+	})
+
+	defer func() {
+		if err := prover.Close(); err != nil {
+			t.Errorf("Error closing prover: %v", err)
+		}
+	}()
+
+	t.Run("Should generate valid AuthV2 proof", func(t *testing.T) {
+		if provingKeyErr != nil {
+			t.Skipf("Proving key is not available: %v", provingKeyErr)
+		}
+
+		proof, err := prover.Prove(circuits.AuthV2CircuitID, []byte(authV2Inputs))
+		if err != nil {
+			t.Fatalf("Error generating proof: %v", err)
+		}
+
+		pubSignals := circuits.AuthV2PubSignals{}
+
+		pubSignalsJson, err := json.Marshal(proof.PubSignals)
+		if err != nil {
+			t.Fatalf("Error marshalling public signals: %v", err)
+		}
+
+		if err := pubSignals.PubSignalsUnmarshal(pubSignalsJson); err != nil {
+			t.Fatalf("Error unmarshalling public signals: %v", err)
+		}
+
+		if pubSignals.Challenge.String() != "6110517768249559238193477435454792024732173865488900270849624328650765691494" {
+			t.Errorf("Unexpected challenge: %s", pubSignals.Challenge.String())
+		}
+
+		verificationKey, err := os.ReadFile("../assets/circuits/auth/verification_key.json")
+		if err != nil {
+			t.Skipf("Verification key is not available: %v", err)
+		}
+
+		if err := verifier.VerifyGroth16(*proof, verificationKey); err != nil {
+			t.Errorf("Error verifying proof: %v", err)
+		}
+	})
+	t.Run("Should fail on unknown circuit", func(t *testing.T) {
+		if _, err := prover.Prove(circuits.AtomicQueryMTPV2CircuitID, []byte(authV2Inputs)); err == nil {
+			t.Errorf("Expected error for unknown circuit")
+		}
+	})
+}
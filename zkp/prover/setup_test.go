@@ -0,0 +1,298 @@
+package prover
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"math/big"
+	"math/bits"
+	"testing"
+)
+
+// testConstraint is the R1CS constraint A * B = C, the maps hold the coefficients by the signal index
+type testConstraint struct {
+	A, B, C map[int]uint64
+}
+
+// testCircuit is the tiny circuit the proving key is set up for in the tests. Signal 0 is one,
+// signals 1 and 2 are public: out = (t2 + a) * (b + 1), t2 = t1 * t1, t1 = 3a * b
+var testCircuit = struct {
+	NVars       int
+	NPublic     int
+	Constraints []testConstraint
+}{
+	NVars:   6,
+	NPublic: 2,
+	Constraints: []testConstraint{
+		{A: map[int]uint64{2: 3}, B: map[int]uint64{3: 1}, C: map[int]uint64{4: 1}},
+		{A: map[int]uint64{4: 1}, B: map[int]uint64{4: 1}, C: map[int]uint64{5: 1}},
+		{A: map[int]uint64{5: 1, 2: 1}, B: map[int]uint64{3: 1, 0: 1}, C: map[int]uint64{1: 1}},
+	},
+}
+
+// testWitness returns the witness of the test circuit: one, out, a, b, t1, t2
+func testWitness(a int64, b int64) []*big.Int {
+	t1 := 3 * a * b
+	t2 := t1 * t1
+
+	return []*big.Int{
+		big.NewInt(1),
+		big.NewInt((t2 + a) * (b + 1)),
+		big.NewInt(a),
+		big.NewInt(b),
+		big.NewInt(t1),
+		big.NewInt(t2),
+	}
+}
+
+// lagrange evaluates the lagrange basis of the domain generated by root at tau
+func lagrange(n int, root fr.Element, tau fr.Element) []fr.Element {
+	one := fr.One()
+
+	// (tau^n - 1) / n
+	numerator := fr.Element{}
+	numerator.Exp(tau, big.NewInt(int64(n)))
+	numerator.Sub(&numerator, &one)
+
+	size := fr.Element{}
+	size.SetUint64(uint64(n))
+	numerator.Div(&numerator, &size)
+
+	basis := make([]fr.Element, n)
+	power := fr.One()
+
+	for i := range basis {
+		denominator := fr.Element{}
+		denominator.Sub(&tau, &power)
+
+		basis[i].Mul(&numerator, &power)
+		basis[i].Div(&basis[i], &denominator)
+
+		power.Mul(&power, &root)
+	}
+
+	return basis
+}
+
+// zkeyWriter writes the sections in the snarkjs zkey layout: the points in the montgomery form,
+// the G2 coordinates as c0 then c1, the coefficients as the plain integer value * R^2
+type zkeyWriter struct {
+	data []byte
+}
+
+func (w *zkeyWriter) uint32(value uint32) {
+	w.data = binary.LittleEndian.AppendUint32(w.data, value)
+}
+
+func (w *zkeyWriter) limbs(limbs [4]uint64) {
+	for _, limb := range limbs {
+		w.data = binary.LittleEndian.AppendUint64(w.data, limb)
+	}
+}
+
+func (w *zkeyWriter) integer(value *big.Int) {
+	bytes := make([]byte, fieldSize)
+	value.FillBytes(bytes)
+
+	for i, j := 0, len(bytes)-1; i < j; i, j = i+1, j-1 {
+		bytes[i], bytes[j] = bytes[j], bytes[i]
+	}
+
+	w.data = append(w.data, bytes...)
+}
+
+func (w *zkeyWriter) g1(point bn254.G1Affine) {
+	w.limbs(point.X)
+	w.limbs(point.Y)
+}
+
+func (w *zkeyWriter) g2(point bn254.G2Affine) {
+	w.limbs(point.X.A0)
+	w.limbs(point.X.A1)
+	w.limbs(point.Y.A0)
+	w.limbs(point.Y.A1)
+}
+
+type testSetup struct {
+	Zkey            []byte
+	VerificationKey []byte
+}
+
+// setupGroth16 runs the groth16 setup of the test circuit the way snarkjs zkey new does it,
+// with the toxic waste known to the test
+func setupGroth16(t *testing.T) testSetup {
+	var tau, alpha, beta, gamma, delta fr.Element
+	for _, element := range []*fr.Element{&tau, &alpha, &beta, &gamma, &delta} {
+		if _, err := element.SetRandom(); err != nil {
+			t.Fatalf("Error generating toxic waste: %v", err)
+		}
+	}
+
+	nVars, nPublic := testCircuit.NVars, testCircuit.NPublic
+
+	// snarkjs binds the public signals with the extra constraints signal * 0 = 0
+	constraints := append([]testConstraint{}, testCircuit.Constraints...)
+	for signal := 0; signal <= nPublic; signal++ {
+		constraints = append(constraints, testConstraint{A: map[int]uint64{signal: 1}})
+	}
+
+	domainSize := 1
+	for domainSize < len(constraints) {
+		domainSize <<= 1
+	}
+
+	power := bits.TrailingZeros(uint(domainSize))
+	basis := lagrange(domainSize, rootOfUnity(power), tau)
+
+	u := make([]fr.Element, nVars)
+	v := make([]fr.Element, nVars)
+	w := make([]fr.Element, nVars)
+
+	for i, constraint := range constraints {
+		for _, matrix := range []struct {
+			values      map[int]uint64
+			polynomials []fr.Element
+		}{{constraint.A, u}, {constraint.B, v}, {constraint.C, w}} {
+			for signal, value := range matrix.values {
+				term := fr.Element{}
+				term.SetUint64(value)
+				term.Mul(&term, &basis[i])
+
+				matrix.polynomials[signal].Add(&matrix.polynomials[signal], &term)
+			}
+		}
+	}
+
+	_, _, g1, g2 := bn254.Generators()
+
+	g1Mul := func(scalar fr.Element) bn254.G1Affine {
+		point := bn254.G1Affine{}
+		point.ScalarMultiplication(&g1, scalar.BigInt(new(big.Int)))
+		return point
+	}
+
+	g2Mul := func(scalar fr.Element) bn254.G2Affine {
+		point := bn254.G2Affine{}
+		point.ScalarMultiplication(&g2, scalar.BigInt(new(big.Int)))
+		return point
+	}
+
+	// (beta * u_i + alpha * v_i + w_i) / divisor
+	combined := func(i int, divisor fr.Element) fr.Element {
+		value, term := fr.Element{}, fr.Element{}
+		value.Mul(&beta, &u[i])
+		term.Mul(&alpha, &v[i])
+		value.Add(&value, &term)
+		value.Add(&value, &w[i])
+
+		return *value.Div(&value, &divisor)
+	}
+
+	header := zkeyWriter{}
+	header.uint32(zkeyProtocolGroth16)
+
+	groth16Header := zkeyWriter{}
+	groth16Header.uint32(fieldSize)
+	groth16Header.integer(fp.Modulus())
+	groth16Header.uint32(fieldSize)
+	groth16Header.integer(fr.Modulus())
+	groth16Header.uint32(uint32(nVars))
+	groth16Header.uint32(uint32(nPublic))
+	groth16Header.uint32(uint32(domainSize))
+	groth16Header.g1(g1Mul(alpha))
+	groth16Header.g1(g1Mul(beta))
+	groth16Header.g2(g2Mul(beta))
+	groth16Header.g2(g2Mul(gamma))
+	groth16Header.g1(g1Mul(delta))
+	groth16Header.g2(g2Mul(delta))
+
+	r2 := new(big.Int).Lsh(big.NewInt(1), 512)
+
+	coefficients := zkeyWriter{}
+	nCoefficients := uint32(0)
+
+	for i, constraint := range constraints {
+		for matrix, values := range []map[int]uint64{constraint.A, constraint.B} {
+			for signal, value := range values {
+				encoded := new(big.Int).Mul(new(big.Int).SetUint64(value), r2)
+
+				coefficients.uint32(uint32(matrix))
+				coefficients.uint32(uint32(i))
+				coefficients.uint32(uint32(signal))
+				coefficients.integer(encoded.Mod(encoded, fr.Modulus()))
+
+				nCoefficients++
+			}
+		}
+	}
+
+	coefficients.data = append(binary.LittleEndian.AppendUint32(nil, nCoefficients), coefficients.data...)
+
+	pointsA, pointsB1, pointsB2, pointsC, pointsH := zkeyWriter{}, zkeyWriter{}, zkeyWriter{}, zkeyWriter{}, zkeyWriter{}
+
+	for i := 0; i < nVars; i++ {
+		pointsA.g1(g1Mul(u[i]))
+		pointsB1.g1(g1Mul(v[i]))
+		pointsB2.g2(g2Mul(v[i]))
+
+		if i > nPublic {
+			pointsC.g1(g1Mul(combined(i, delta)))
+		}
+	}
+
+	// the quotient is evaluated on the odd powers of the root of the double domain,
+	// the even ones are the roots of Z, so the odd lagrange basis alone recovers A * B - C at tau
+	doubleBasis := lagrange(2*domainSize, rootOfUnity(power+1), tau)
+	for i := 0; i < domainSize; i++ {
+		h := fr.Element{}
+		pointsH.g1(g1Mul(*h.Div(&doubleBasis[2*i+1], &delta)))
+	}
+
+	zkey := zkeyWriter{data: []byte("zkey")}
+	zkey.uint32(1)
+	zkey.uint32(8)
+
+	for _, section := range []struct {
+		sectionType uint32
+		data        []byte
+	}{
+		{zkeySectionHeader, header.data},
+		{zkeySectionGroth16Header, groth16Header.data},
+		{zkeySectionCoefficients, coefficients.data},
+		{zkeySectionPointsA, pointsA.data},
+		{zkeySectionPointsB1, pointsB1.data},
+		{zkeySectionPointsB2, pointsB2.data},
+		{zkeySectionPointsC, pointsC.data},
+		{zkeySectionPointsH, pointsH.data},
+	} {
+		zkey.uint32(section.sectionType)
+		zkey.data = binary.LittleEndian.AppendUint64(zkey.data, uint64(len(section.data)))
+		zkey.data = append(zkey.data, section.data...)
+	}
+
+	ic := make([][]string, nPublic+1)
+	for i := range ic {
+		point := g1Mul(combined(i, gamma))
+		ic[i] = g1ToStrings(&point)
+	}
+
+	alpha1, beta2, gamma2, delta2 := g1Mul(alpha), g2Mul(beta), g2Mul(gamma), g2Mul(delta)
+
+	verificationKey, err := json.Marshal(map[string]interface{}{
+		"protocol":   "groth16",
+		"curve":      "bn128",
+		"nPublic":    nPublic,
+		"vk_alpha_1": g1ToStrings(&alpha1),
+		"vk_beta_2":  g2ToStrings(&beta2),
+		"vk_gamma_2": g2ToStrings(&gamma2),
+		"vk_delta_2": g2ToStrings(&delta2),
+		"IC":         ic,
+	})
+	if err != nil {
+		t.Fatalf("Error marshalling verification key: %v", err)
+	}
+
+	return testSetup{Zkey: zkey.data, VerificationKey: verificationKey}
+}
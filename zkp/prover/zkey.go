@@ -0,0 +1,402 @@
+package prover
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/pkg/errors"
+	"math/big"
+)
+
+const (
+	zkeySectionHeader        = 1
+	zkeySectionGroth16Header = 2
+	zkeySectionCoefficients  = 4
+	zkeySectionPointsA       = 5
+	zkeySectionPointsB1      = 6
+	zkeySectionPointsB2      = 7
+	zkeySectionPointsC       = 8
+	zkeySectionPointsH       = 9
+
+	zkeyProtocolGroth16 = 1
+
+	// fieldSize is the byte length of bn254 base and scalar field elements
+	fieldSize = 32
+
+	// coefficientSize is the byte length of the coefficient entry: matrix, constraint, signal and value
+	coefficientSize = 3*4 + fieldSize
+)
+
+// coefficient is the R1CS matrix entry stored in the zkey coefficients section
+type coefficient struct {
+	Matrix     uint32
+	Constraint uint32
+	Signal     uint32
+	Value      fr.Element
+}
+
+// ProvingKey is the parsed groth16 proving key in snarkjs zkey format
+type ProvingKey struct {
+	NVars      uint32
+	NPublic    uint32
+	DomainSize uint32
+
+	Alpha1 bn254.G1Affine
+	Beta1  bn254.G1Affine
+	Beta2  bn254.G2Affine
+	Delta1 bn254.G1Affine
+	Delta2 bn254.G2Affine
+
+	Coefficients []coefficient
+
+	A  []bn254.G1Affine
+	B1 []bn254.G1Affine
+	B2 []bn254.G2Affine
+	C  []bn254.G1Affine
+	H  []bn254.G1Affine
+}
+
+type zkeyReader struct {
+	data   []byte
+	offset int
+}
+
+func (r *zkeyReader) uint32() (uint32, error) {
+	if r.offset+4 > len(r.data) {
+		return 0, errors.New("unexpected end of zkey")
+	}
+
+	value := binary.LittleEndian.Uint32(r.data[r.offset:])
+	r.offset += 4
+
+	return value, nil
+}
+
+func (r *zkeyReader) uint64() (uint64, error) {
+	if r.offset+8 > len(r.data) {
+		return 0, errors.New("unexpected end of zkey")
+	}
+
+	value := binary.LittleEndian.Uint64(r.data[r.offset:])
+	r.offset += 8
+
+	return value, nil
+}
+
+func (r *zkeyReader) bytes(n int) ([]byte, error) {
+	if n < 0 || r.offset+n > len(r.data) {
+		return nil, errors.New("unexpected end of zkey")
+	}
+
+	value := r.data[r.offset : r.offset+n]
+	r.offset += n
+
+	return value, nil
+}
+
+// montgomeryLimbs reads the little-endian montgomery representation of a field element as is,
+// both snarkjs and gnark-crypto use R = 2^256 for bn254
+func montgomeryLimbs(data []byte) [4]uint64 {
+	return [4]uint64{
+		binary.LittleEndian.Uint64(data[0:8]),
+		binary.LittleEndian.Uint64(data[8:16]),
+		binary.LittleEndian.Uint64(data[16:24]),
+		binary.LittleEndian.Uint64(data[24:32]),
+	}
+}
+
+func (r *zkeyReader) g1() (bn254.G1Affine, error) {
+	data, err := r.bytes(2 * fieldSize)
+	if err != nil {
+		return bn254.G1Affine{}, err
+	}
+
+	return bn254.G1Affine{
+		X: fp.Element(montgomeryLimbs(data[:fieldSize])),
+		Y: fp.Element(montgomeryLimbs(data[fieldSize:])),
+	}, nil
+}
+
+func (r *zkeyReader) g2() (bn254.G2Affine, error) {
+	data, err := r.bytes(4 * fieldSize)
+	if err != nil {
+		return bn254.G2Affine{}, err
+	}
+
+	point := bn254.G2Affine{}
+	point.X.A0 = fp.Element(montgomeryLimbs(data[0*fieldSize:]))
+	point.X.A1 = fp.Element(montgomeryLimbs(data[1*fieldSize:]))
+	point.Y.A0 = fp.Element(montgomeryLimbs(data[2*fieldSize:]))
+	point.Y.A1 = fp.Element(montgomeryLimbs(data[3*fieldSize:]))
+
+	return point, nil
+}
+
+// fits reports whether n items of the size are left, so the counts read from the zkey never
+// allocate more than the file holds
+func (r *zkeyReader) fits(n int, size int) bool {
+	return n >= 0 && n <= (len(r.data)-r.offset)/size
+}
+
+func (r *zkeyReader) g1Points(n int) ([]bn254.G1Affine, error) {
+	if !r.fits(n, 2*fieldSize) {
+		return nil, errors.Errorf("zkey section is too short for %d G1 points", n)
+	}
+
+	points := make([]bn254.G1Affine, n)
+
+	for i := range points {
+		point, err := r.g1()
+		if err != nil {
+			return nil, err
+		}
+
+		points[i] = point
+	}
+
+	return points, nil
+}
+
+func (r *zkeyReader) g2Points(n int) ([]bn254.G2Affine, error) {
+	if !r.fits(n, 4*fieldSize) {
+		return nil, errors.Errorf("zkey section is too short for %d G2 points", n)
+	}
+
+	points := make([]bn254.G2Affine, n)
+
+	for i := range points {
+		point, err := r.g2()
+		if err != nil {
+			return nil, err
+		}
+
+		points[i] = point
+	}
+
+	return points, nil
+}
+
+func readZkeySections(zkey []byte) (map[uint32][]byte, error) {
+	reader := zkeyReader{data: zkey}
+
+	magic, err := reader.bytes(4)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(magic, []byte("zkey")) {
+		return nil, errors.New("invalid zkey magic")
+	}
+
+	if _, err := reader.uint32(); err != nil {
+		return nil, errors.Wrap(err, "failed to read zkey version")
+	}
+
+	nSections, err := reader.uint32()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read zkey sections count")
+	}
+
+	sections := make(map[uint32][]byte, nSections)
+
+	for i := uint32(0); i < nSections; i++ {
+		sectionType, err := reader.uint32()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read zkey section type")
+		}
+
+		sectionSize, err := reader.uint64()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read zkey section size")
+		}
+
+		section, err := reader.bytes(int(sectionSize))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read zkey section %d", sectionType)
+		}
+
+		if _, ok := sections[sectionType]; !ok {
+			sections[sectionType] = section
+		}
+	}
+
+	return sections, nil
+}
+
+// rInverse is the inverse of the montgomery constant R = 2^256 in the scalar field,
+// zkey coefficients are stored as value * R^2, so reading them as montgomery limbs leaves one extra R
+var rInverse = func() fr.Element {
+	r := new(big.Int).Lsh(big.NewInt(1), 256)
+
+	inverse := fr.Element{}
+	inverse.SetBigInt(r)
+	inverse.Inverse(&inverse)
+
+	return inverse
+}()
+
+// ParseProvingKey parses the groth16 proving key from the snarkjs zkey file
+func ParseProvingKey(zkey []byte) (*ProvingKey, error) {
+	sections, err := readZkeySections(zkey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read zkey sections")
+	}
+
+	for _, sectionType := range []uint32{
+		zkeySectionHeader,
+		zkeySectionGroth16Header,
+		zkeySectionCoefficients,
+		zkeySectionPointsA,
+		zkeySectionPointsB1,
+		zkeySectionPointsB2,
+		zkeySectionPointsC,
+		zkeySectionPointsH,
+	} {
+		if _, ok := sections[sectionType]; !ok {
+			return nil, errors.Errorf("zkey section %d is missing", sectionType)
+		}
+	}
+
+	header := zkeyReader{data: sections[zkeySectionHeader]}
+
+	protocol, err := header.uint32()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read zkey protocol")
+	}
+
+	if protocol != zkeyProtocolGroth16 {
+		return nil, errors.Errorf("unsupported zkey protocol %d", protocol)
+	}
+
+	pk := ProvingKey{}
+
+	groth16Header := zkeyReader{data: sections[zkeySectionGroth16Header]}
+
+	for _, field := range []string{"base", "scalar"} {
+		n8, err := groth16Header.uint32()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s field size", field)
+		}
+
+		if n8 != fieldSize {
+			return nil, errors.Errorf("unsupported %s field size %d", field, n8)
+		}
+
+		if _, err := groth16Header.bytes(int(n8)); err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s field modulus", field)
+		}
+	}
+
+	if pk.NVars, err = groth16Header.uint32(); err != nil {
+		return nil, errors.Wrap(err, "failed to read variables count")
+	}
+
+	if pk.NPublic, err = groth16Header.uint32(); err != nil {
+		return nil, errors.Wrap(err, "failed to read public signals count")
+	}
+
+	if pk.NPublic >= pk.NVars {
+		return nil, errors.Errorf("public signals count %d exceeds variables count %d", pk.NPublic, pk.NVars)
+	}
+
+	if pk.DomainSize, err = groth16Header.uint32(); err != nil {
+		return nil, errors.Wrap(err, "failed to read domain size")
+	}
+
+	if pk.DomainSize == 0 || pk.DomainSize&(pk.DomainSize-1) != 0 {
+		return nil, errors.Errorf("domain size %d is not a power of two", pk.DomainSize)
+	}
+
+	if pk.Alpha1, err = groth16Header.g1(); err != nil {
+		return nil, errors.Wrap(err, "failed to read alpha1")
+	}
+
+	if pk.Beta1, err = groth16Header.g1(); err != nil {
+		return nil, errors.Wrap(err, "failed to read beta1")
+	}
+
+	if pk.Beta2, err = groth16Header.g2(); err != nil {
+		return nil, errors.Wrap(err, "failed to read beta2")
+	}
+
+	if _, err = groth16Header.g2(); err != nil {
+		return nil, errors.Wrap(err, "failed to read gamma2")
+	}
+
+	if pk.Delta1, err = groth16Header.g1(); err != nil {
+		return nil, errors.Wrap(err, "failed to read delta1")
+	}
+
+	if pk.Delta2, err = groth16Header.g2(); err != nil {
+		return nil, errors.Wrap(err, "failed to read delta2")
+	}
+
+	coefficients := zkeyReader{data: sections[zkeySectionCoefficients]}
+
+	nCoefficients, err := coefficients.uint32()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read coefficients count")
+	}
+
+	if !coefficients.fits(int(nCoefficients), coefficientSize) {
+		return nil, errors.Errorf("coefficients section is too short for %d coefficients", nCoefficients)
+	}
+
+	pk.Coefficients = make([]coefficient, nCoefficients)
+
+	for i := range pk.Coefficients {
+		coef := coefficient{}
+
+		if coef.Matrix, err = coefficients.uint32(); err != nil {
+			return nil, errors.Wrap(err, "failed to read coefficient matrix")
+		}
+
+		if coef.Constraint, err = coefficients.uint32(); err != nil {
+			return nil, errors.Wrap(err, "failed to read coefficient constraint")
+		}
+
+		if coef.Signal, err = coefficients.uint32(); err != nil {
+			return nil, errors.Wrap(err, "failed to read coefficient signal")
+		}
+
+		value, err := coefficients.bytes(fieldSize)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read coefficient value")
+		}
+
+		if coef.Matrix > 1 || coef.Constraint >= pk.DomainSize || coef.Signal >= pk.NVars {
+			return nil, errors.Errorf("coefficient %d is out of range", i)
+		}
+
+		coef.Value = fr.Element(montgomeryLimbs(value))
+		coef.Value.Mul(&coef.Value, &rInverse)
+
+		pk.Coefficients[i] = coef
+	}
+
+	nPrivate := int(pk.NVars - pk.NPublic - 1)
+
+	if pk.A, err = (&zkeyReader{data: sections[zkeySectionPointsA]}).g1Points(int(pk.NVars)); err != nil {
+		return nil, errors.Wrap(err, "failed to read A points")
+	}
+
+	if pk.B1, err = (&zkeyReader{data: sections[zkeySectionPointsB1]}).g1Points(int(pk.NVars)); err != nil {
+		return nil, errors.Wrap(err, "failed to read B1 points")
+	}
+
+	if pk.B2, err = (&zkeyReader{data: sections[zkeySectionPointsB2]}).g2Points(int(pk.NVars)); err != nil {
+		return nil, errors.Wrap(err, "failed to read B2 points")
+	}
+
+	if pk.C, err = (&zkeyReader{data: sections[zkeySectionPointsC]}).g1Points(nPrivate); err != nil {
+		return nil, errors.Wrap(err, "failed to read C points")
+	}
+
+	if pk.H, err = (&zkeyReader{data: sections[zkeySectionPointsH]}).g1Points(int(pk.DomainSize)); err != nil {
+		return nil, errors.Wrap(err, "failed to read H points")
+	}
+
+	return &pk, nil
+}
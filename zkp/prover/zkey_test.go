@@ -0,0 +1,162 @@
+package prover
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+// patchSection rewrites the first section of the type in the zkey, the nil patch drops the section
+func patchSection(t *testing.T, zkey []byte, sectionType uint32, patch func(section []byte) []byte) []byte {
+	patched := append([]byte{}, zkey[:12]...)
+
+	for offset := 12; offset < len(zkey); {
+		currentType := binary.LittleEndian.Uint32(zkey[offset:])
+		size := int(binary.LittleEndian.Uint64(zkey[offset+4:]))
+		section := append([]byte{}, zkey[offset+12:offset+12+size]...)
+
+		offset += 12 + size
+
+		if currentType == sectionType {
+			sectionType = 0
+
+			if patch == nil {
+				binary.LittleEndian.PutUint32(patched[8:], binary.LittleEndian.Uint32(patched[8:])-1)
+				continue
+			}
+
+			section = patch(section)
+		}
+
+		patched = binary.LittleEndian.AppendUint32(patched, currentType)
+		patched = binary.LittleEndian.AppendUint64(patched, uint64(len(section)))
+		patched = append(patched, section...)
+	}
+
+	if sectionType != 0 {
+		t.Fatalf("Section is missing")
+	}
+
+	return patched
+}
+
+func putUint32(offset int, value uint32) func(section []byte) []byte {
+	return func(section []byte) []byte {
+		binary.LittleEndian.PutUint32(section[offset:], value)
+		return section
+	}
+}
+
+func TestParseProvingKey(t *testing.T) {
+	zkey := setupGroth16(t).Zkey
+
+	// the groth16 header holds both field sizes and moduli before the counts
+	countsOffset := 2 * (4 + fieldSize)
+
+	t.Run("Should parse proving key", func(t *testing.T) {
+		pk, err := ParseProvingKey(zkey)
+		if err != nil {
+			t.Fatalf("Error parsing proving key: %v", err)
+		}
+
+		if pk.NVars != 6 || pk.NPublic != 2 || pk.DomainSize != 8 {
+			t.Errorf("Unexpected sizes %d %d %d", pk.NVars, pk.NPublic, pk.DomainSize)
+		}
+
+		if len(pk.A) != 6 || len(pk.B2) != 6 || len(pk.C) != 3 || len(pk.H) != 8 {
+			t.Errorf("Unexpected points count %d %d %d %d", len(pk.A), len(pk.B2), len(pk.C), len(pk.H))
+		}
+
+		for _, coef := range pk.Coefficients {
+			if coef.Matrix == 0 && coef.Constraint == 0 && coef.Signal == 2 && frToString(&coef.Value) != "3" {
+				t.Errorf("Unexpected coefficient value %s", frToString(&coef.Value))
+			}
+		}
+	})
+	t.Run("Should reject truncated zkey", func(t *testing.T) {
+		for length := 0; length < len(zkey); length += 7 {
+			if _, err := ParseProvingKey(zkey[:length]); err == nil {
+				t.Fatalf("Expected error for zkey truncated to %d bytes", length)
+			}
+		}
+	})
+
+	cases := []struct {
+		name     string
+		zkey     []byte
+		expected string
+	}{
+		{
+			name:     "Should reject invalid magic",
+			zkey:     append([]byte("zkez"), zkey[4:]...),
+			expected: "invalid zkey magic",
+		},
+		{
+			name:     "Should reject missing section",
+			zkey:     patchSection(t, zkey, zkeySectionPointsH, nil),
+			expected: "zkey section 9 is missing",
+		},
+		{
+			name:     "Should reject unsupported protocol",
+			zkey:     patchSection(t, zkey, zkeySectionHeader, putUint32(0, 2)),
+			expected: "unsupported zkey protocol 2",
+		},
+		{
+			name:     "Should reject unsupported field size",
+			zkey:     patchSection(t, zkey, zkeySectionGroth16Header, putUint32(0, 48)),
+			expected: "unsupported base field size 48",
+		},
+		{
+			name:     "Should reject public signals exceeding variables",
+			zkey:     patchSection(t, zkey, zkeySectionGroth16Header, putUint32(countsOffset+4, 6)),
+			expected: "public signals count 6 exceeds variables count 6",
+		},
+		{
+			name:     "Should reject domain size not power of two",
+			zkey:     patchSection(t, zkey, zkeySectionGroth16Header, putUint32(countsOffset+8, 6)),
+			expected: "domain size 6 is not a power of two",
+		},
+		{
+			name:     "Should reject huge coefficients count without allocating",
+			zkey:     patchSection(t, zkey, zkeySectionCoefficients, putUint32(0, 0xffffffff)),
+			expected: "coefficients section is too short",
+		},
+		{
+			name:     "Should reject huge variables count without allocating",
+			zkey:     patchSection(t, zkey, zkeySectionGroth16Header, putUint32(countsOffset, 0xffffffff)),
+			expected: "too short for 4294967295 G1 points",
+		},
+		{
+			name:     "Should reject huge domain size without allocating",
+			zkey:     patchSection(t, zkey, zkeySectionGroth16Header, putUint32(countsOffset+8, 1<<31)),
+			expected: "too short for 2147483648 G1 points",
+		},
+		{
+			name:     "Should reject coefficient out of range",
+			zkey:     patchSection(t, zkey, zkeySectionCoefficients, putUint32(4+8, 6)),
+			expected: "coefficient 0 is out of range",
+		},
+		{
+			name: "Should reject section size past the end",
+			zkey: func() []byte {
+				broken := append([]byte{}, zkey...)
+				binary.LittleEndian.PutUint64(broken[16:], 1<<63)
+				return broken
+			}(),
+			expected: "failed to read zkey section 1",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := ParseProvingKey(c.zkey)
+			if err == nil {
+				t.Fatalf("Expected error")
+			}
+
+			if !strings.Contains(err.Error(), c.expected) {
+				t.Errorf("Expected error containing %q, got %v", c.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package registry
+
+import "github.com/iden3/go-circuits/v2"
+
+// BundledDigests returns the pins of the artifacts shipped in zkp/assets/circuits, the proving keys
+// are not bundled so they have to be pinned by the caller
+func BundledDigests() map[circuits.CircuitID]Digests {
+	return map[circuits.CircuitID]Digests{
+		circuits.AuthV2CircuitID: {
+			Wasm: "70affbca1ad1947d76784ca90f6c4a8fd143119685c9917a2a6fefc15b9ed7c1",
+		},
+		circuits.AtomicQueryMTPV2CircuitID: {
+			Wasm:            "419be312398e0bb87373166ea9ab936bde10863ff82a3f6691f0b616a6d3b634",
+			VerificationKey: "035a44c1c9246b5e7ee45ef06cda27572c5374fc0cc125c9bc7f92d9ed2e2b5e",
+		},
+		circuits.AtomicQueryMTPV2OnChainCircuitID: {
+			VerificationKey: "f7bf2dd1c19339752bf9d29f4cb8d841f96d06b3bee0d126a4eac877753e26c6",
+		},
+		circuits.AtomicQuerySigV2CircuitID: {
+			Wasm:            "8d43d3ba84dbe2245302cd2d351f399e03e3c15a0543943b9ba31c3c42e57a73",
+			VerificationKey: "b9cdae0e50b532e6bcc82be5714e2b5ae3b44a0f49897ae51388c2e59be4638c",
+		},
+		circuits.AtomicQuerySigV2OnChainCircuitID: {
+			VerificationKey: "a164bb91909c57eddfaae48d7842ee19d9e2e09de520e20d78a106077cfcf669",
+		},
+	}
+}
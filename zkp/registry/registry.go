@@ -0,0 +1,308 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/iden3/go-circuits/v2"
+	"github.com/pkg/errors"
+	"github.com/rarimo/zkp-iden3-exposer/errcode"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/prover"
+	"github.com/rarimo/zkp-iden3-exposer/zkp/types"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+// Artifact is the file name of the circuit artifact inside the circuit directory
+type Artifact string
+
+const (
+	ArtifactWasm            Artifact = "circuit.wasm"
+	ArtifactProvingKey      Artifact = "circuit_final.zkey"
+	ArtifactVerificationKey Artifact = "verification_key.json"
+)
+
+var (
+	ErrArtifactNotFound = errcode.New(errcode.CircuitArtifact, "circuit artifact not found")
+	ErrDigestMismatch   = errcode.New(errcode.CircuitArtifact, "circuit artifact digest mismatch")
+	ErrUnpinnedArtifact = errcode.New(errcode.CircuitArtifact, "circuit artifact digest is not pinned")
+)
+
+// circuitDirs are the directories of the circuits laid out under another name, the bundled
+// AuthV2 artifacts live in auth/
+var circuitDirs = map[circuits.CircuitID]string{
+	circuits.AuthV2CircuitID: "auth",
+}
+
+// Digests are the pinned hex encoded SHA-256 digests of the circuit artifacts, the artifacts without
+// the digest are rejected unless the registry allows unpinned ones
+type Digests struct {
+	Wasm            string `json:"wasm"`
+	ProvingKey      string `json:"provingKey"`
+	VerificationKey string `json:"verificationKey"`
+}
+
+func (d Digests) get(artifact Artifact) string {
+	switch artifact {
+	case ArtifactWasm:
+		return d.Wasm
+	case ArtifactProvingKey:
+		return d.ProvingKey
+	case ArtifactVerificationKey:
+		return d.VerificationKey
+	}
+
+	return ""
+}
+
+type entry struct {
+	artifacts  map[Artifact][]byte
+	provingKey *prover.ProvingKey
+}
+
+// Registry loads the circuit artifacts laid out as <circuitId>/<artifact> lazily and keeps them in memory
+type Registry struct {
+	fsys          fs.FS
+	digests       map[circuits.CircuitID]Digests
+	allowUnpinned bool
+
+	mutex   sync.Mutex
+	entries map[circuits.CircuitID]*entry
+}
+
+// NewRegistry creates the registry over the file system, embed.FS included
+func NewRegistry(fsys fs.FS, digests map[circuits.CircuitID]Digests) *Registry {
+	if digests == nil {
+		digests = map[circuits.CircuitID]Digests{}
+	}
+
+	return &Registry{
+		fsys:    fsys,
+		digests: digests,
+		entries: map[circuits.CircuitID]*entry{},
+	}
+}
+
+// NewDirRegistry creates the registry over the directory on disk
+func NewDirRegistry(dir string, digests map[circuits.CircuitID]Digests) *Registry {
+	return NewRegistry(os.DirFS(dir), digests)
+}
+
+// WithFS returns the registry over the file system with the same pins and unpinned policy,
+// the loaded artifacts are not carried over
+func (r *Registry) WithFS(fsys fs.FS) *Registry {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	digests := make(map[circuits.CircuitID]Digests, len(r.digests))
+	for circuitId, circuitDigests := range r.digests {
+		digests[circuitId] = circuitDigests
+	}
+
+	registry := NewRegistry(fsys, digests)
+	registry.allowUnpinned = r.allowUnpinned
+
+	return registry
+}
+
+// AllowUnpinned accepts the artifacts without the pinned digest, only meant for development
+func (r *Registry) AllowUnpinned(allow bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.allowUnpinned = allow
+}
+
+// Pin sets the expected digests of the circuit artifacts, already loaded artifacts are dropped
+func (r *Registry) Pin(circuitId circuits.CircuitID, digests Digests) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.digests[circuitId] = digests
+	delete(r.entries, circuitId)
+}
+
+// Set puts the artifact in memory, it takes precedence over the file system
+func (r *Registry) Set(circuitId circuits.CircuitID, artifact Artifact, data []byte) error {
+	if err := r.verify(circuitId, artifact, data); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	e := r.entry(circuitId)
+	e.artifacts[artifact] = data
+
+	if artifact == ArtifactProvingKey {
+		e.provingKey = nil
+	}
+
+	return nil
+}
+
+func (r *Registry) entry(circuitId circuits.CircuitID) *entry {
+	e, ok := r.entries[circuitId]
+	if !ok {
+		e = &entry{artifacts: map[Artifact][]byte{}}
+		r.entries[circuitId] = e
+	}
+
+	return e
+}
+
+func (r *Registry) verify(circuitId circuits.CircuitID, artifact Artifact, data []byte) error {
+	r.mutex.Lock()
+	expected := r.digests[circuitId].get(artifact)
+	allowUnpinned := r.allowUnpinned
+	r.mutex.Unlock()
+
+	if expected == "" {
+		if allowUnpinned {
+			return nil
+		}
+
+		return errors.Wrapf(ErrUnpinnedArtifact, "%s/%s", circuitId, artifact)
+	}
+
+	digest := sha256.Sum256(data)
+
+	if !strings.EqualFold(strings.TrimPrefix(expected, "0x"), hex.EncodeToString(digest[:])) {
+		return errors.Wrapf(ErrDigestMismatch, "%s/%s", circuitId, artifact)
+	}
+
+	return nil
+}
+
+// path returns the artifact path, the circuit directory falls back to its alias when missing
+func (r *Registry) path(circuitId circuits.CircuitID, artifact Artifact) string {
+	dir, ok := circuitDirs[circuitId]
+	if !ok {
+		return path.Join(string(circuitId), string(artifact))
+	}
+
+	if _, err := fs.Stat(r.fsys, string(circuitId)); err == nil {
+		dir = string(circuitId)
+	}
+
+	return path.Join(dir, string(artifact))
+}
+
+func (r *Registry) read(circuitId circuits.CircuitID, artifact Artifact) ([]byte, error) {
+	if r.fsys == nil {
+		return nil, errors.Wrapf(ErrArtifactNotFound, "%s/%s", circuitId, artifact)
+	}
+
+	data, err := fs.ReadFile(r.fsys, r.path(circuitId, artifact))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, errors.Wrapf(ErrArtifactNotFound, "%s/%s", circuitId, artifact)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s/%s", circuitId, artifact)
+	}
+
+	return data, nil
+}
+
+// Artifact returns the verified artifact, reading it on the first access
+func (r *Registry) Artifact(circuitId circuits.CircuitID, artifact Artifact) ([]byte, error) {
+	r.mutex.Lock()
+	data, ok := r.entry(circuitId).artifacts[artifact]
+	r.mutex.Unlock()
+
+	if ok {
+		return data, nil
+	}
+
+	data, err := r.read(circuitId, artifact)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.verify(circuitId, artifact, data); err != nil {
+		return nil, err
+	}
+
+	r.mutex.Lock()
+	r.entry(circuitId).artifacts[artifact] = data
+	r.mutex.Unlock()
+
+	return data, nil
+}
+
+// LoadCircuit returns the wasm and the proving key of the circuit
+func (r *Registry) LoadCircuit(circuitId circuits.CircuitID) (*types.CircuitPair, error) {
+	wasm, err := r.Artifact(circuitId, ArtifactWasm)
+	if err != nil {
+		return nil, err
+	}
+
+	provingKey, err := r.Artifact(circuitId, ArtifactProvingKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.CircuitPair{
+		Wasm:       wasm,
+		ProvingKey: provingKey,
+	}, nil
+}
+
+// LoadProvingKey returns the parsed proving key, parsing happens once per circuit
+func (r *Registry) LoadProvingKey(circuitId circuits.CircuitID) (*prover.ProvingKey, error) {
+	r.mutex.Lock()
+	provingKey := r.entry(circuitId).provingKey
+	r.mutex.Unlock()
+
+	if provingKey != nil {
+		return provingKey, nil
+	}
+
+	data, err := r.Artifact(circuitId, ArtifactProvingKey)
+	if err != nil {
+		return nil, err
+	}
+
+	provingKey, err = prover.ParseProvingKey(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s proving key", circuitId)
+	}
+
+	r.mutex.Lock()
+	r.entry(circuitId).provingKey = provingKey
+	r.mutex.Unlock()
+
+	return provingKey, nil
+}
+
+// VerificationKey returns the json encoded verification key of the circuit
+func (r *Registry) VerificationKey(circuitId circuits.CircuitID) ([]byte, error) {
+	return r.Artifact(circuitId, ArtifactVerificationKey)
+}
+
+// Missing lists the artifacts of the circuit that are neither in memory nor on the file system
+func (r *Registry) Missing(circuitId circuits.CircuitID) []Artifact {
+	missing := make([]Artifact, 0)
+
+	for _, artifact := range []Artifact{ArtifactWasm, ArtifactProvingKey, ArtifactVerificationKey} {
+		r.mutex.Lock()
+		_, ok := r.entry(circuitId).artifacts[artifact]
+		r.mutex.Unlock()
+
+		if ok {
+			continue
+		}
+
+		if r.fsys != nil {
+			if _, err := fs.Stat(r.fsys, r.path(circuitId, artifact)); err == nil {
+				continue
+			}
+		}
+
+		missing = append(missing, artifact)
+	}
+
+	return missing
+}
@@ -0,0 +1,176 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/iden3/go-circuits/v2"
+	"github.com/pkg/errors"
+	"os"
+	"testing"
+	"testing/fstest"
+)
+
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func TestRegistry(t *testing.T) {
+	wasm := []byte("wasm")
+	provingKey := []byte("zkey")
+	verificationKey := []byte("{}")
+
+	fsys := fstest.MapFS{
+		"authV2/circuit.wasm":                            {Data: wasm},
+		"authV2/circuit_final.zkey":                      {Data: provingKey},
+		"authV2/verification_key.json":                   {Data: verificationKey},
+		"credentialAtomicQueryMTPV2OnChain/circuit.wasm": {Data: wasm},
+	}
+
+	t.Run("Should load pinned circuit", func(t *testing.T) {
+		registry := NewRegistry(fsys, map[circuits.CircuitID]Digests{
+			circuits.AuthV2CircuitID: {
+				Wasm:            digest(wasm),
+				ProvingKey:      digest(provingKey),
+				VerificationKey: digest(verificationKey),
+			},
+		})
+
+		circuitPair, err := registry.LoadCircuit(circuits.AuthV2CircuitID)
+		if err != nil {
+			t.Fatalf("Error loading circuit: %v", err)
+		}
+
+		if string(circuitPair.Wasm) != string(wasm) || string(circuitPair.ProvingKey) != string(provingKey) {
+			t.Errorf("Unexpected circuit pair")
+		}
+
+		if _, err := registry.VerificationKey(circuits.AuthV2CircuitID); err != nil {
+			t.Errorf("Error loading verification key: %v", err)
+		}
+	})
+	t.Run("Should report missing artifacts", func(t *testing.T) {
+		registry := NewRegistry(fsys, nil)
+		registry.AllowUnpinned(true)
+
+		_, err := registry.LoadCircuit(circuits.AtomicQueryMTPV2OnChainCircuitID)
+		if !errors.Is(err, ErrArtifactNotFound) {
+			t.Errorf("Expected ErrArtifactNotFound, got %v", err)
+		}
+
+		missing := registry.Missing(circuits.AtomicQueryMTPV2OnChainCircuitID)
+		if len(missing) != 2 || missing[0] != ArtifactProvingKey || missing[1] != ArtifactVerificationKey {
+			t.Errorf("Unexpected missing artifacts: %v", missing)
+		}
+	})
+	t.Run("Should reject tampered artifact", func(t *testing.T) {
+		registry := NewRegistry(fsys, map[circuits.CircuitID]Digests{
+			circuits.AuthV2CircuitID: {
+				Wasm:       digest(wasm),
+				ProvingKey: digest([]byte("another zkey")),
+			},
+		})
+
+		if _, err := registry.LoadCircuit(circuits.AuthV2CircuitID); !errors.Is(err, ErrDigestMismatch) {
+			t.Errorf("Expected ErrDigestMismatch, got %v", err)
+		}
+
+		if err := registry.Set(circuits.AuthV2CircuitID, ArtifactProvingKey, provingKey); !errors.Is(err, ErrDigestMismatch) {
+			t.Errorf("Expected ErrDigestMismatch, got %v", err)
+		}
+	})
+	t.Run("Should reject unpinned artifacts unless allowed", func(t *testing.T) {
+		registry := NewRegistry(fsys, map[circuits.CircuitID]Digests{
+			circuits.AuthV2CircuitID: {Wasm: digest(wasm)},
+		})
+
+		if _, err := registry.LoadCircuit(circuits.AuthV2CircuitID); !errors.Is(err, ErrUnpinnedArtifact) {
+			t.Errorf("Expected ErrUnpinnedArtifact, got %v", err)
+		}
+
+		if err := registry.Set(circuits.AtomicQuerySigV2CircuitID, ArtifactWasm, wasm); !errors.Is(err, ErrUnpinnedArtifact) {
+			t.Errorf("Expected ErrUnpinnedArtifact, got %v", err)
+		}
+
+		registry.AllowUnpinned(true)
+
+		if _, err := registry.LoadCircuit(circuits.AuthV2CircuitID); err != nil {
+			t.Errorf("Error loading unpinned circuit: %v", err)
+		}
+	})
+	t.Run("Should keep pins and policy over another file system", func(t *testing.T) {
+		registry := NewRegistry(nil, map[circuits.CircuitID]Digests{
+			circuits.AuthV2CircuitID: {Wasm: digest(wasm), ProvingKey: digest([]byte("another zkey"))},
+		})
+
+		moved := registry.WithFS(fsys)
+
+		if _, err := moved.LoadCircuit(circuits.AuthV2CircuitID); !errors.Is(err, ErrDigestMismatch) {
+			t.Errorf("Expected ErrDigestMismatch, got %v", err)
+		}
+
+		registry.AllowUnpinned(true)
+
+		if _, err := registry.WithFS(fsys).Artifact(circuits.AuthV2CircuitID, ArtifactVerificationKey); err != nil {
+			t.Errorf("Expected unpinned policy to be kept, got %v", err)
+		}
+	})
+	t.Run("Should read AuthV2 from the auth directory", func(t *testing.T) {
+		registry := NewRegistry(fstest.MapFS{"auth/circuit.wasm": {Data: wasm}}, map[circuits.CircuitID]Digests{
+			circuits.AuthV2CircuitID: {Wasm: digest(wasm)},
+		})
+
+		if data, err := registry.Artifact(circuits.AuthV2CircuitID, ArtifactWasm); err != nil || string(data) != string(wasm) {
+			t.Errorf("Unexpected wasm %q: %v", data, err)
+		}
+
+		missing := registry.Missing(circuits.AuthV2CircuitID)
+		if len(missing) != 2 || missing[0] != ArtifactProvingKey {
+			t.Errorf("Unexpected missing artifacts: %v", missing)
+		}
+	})
+	t.Run("Should match the bundled artifacts", func(t *testing.T) {
+		registry := NewDirRegistry("../assets/circuits", BundledDigests())
+
+		for circuitId, digests := range BundledDigests() {
+			for _, artifact := range []Artifact{ArtifactWasm, ArtifactVerificationKey} {
+				if digests.get(artifact) == "" {
+					continue
+				}
+
+				if _, err := registry.Artifact(circuitId, artifact); err != nil {
+					t.Errorf("Error loading bundled %s/%s: %v", circuitId, artifact, err)
+				}
+			}
+		}
+
+		if _, err := os.Stat("../assets/circuits/authV2"); err == nil {
+			t.Errorf("Expected the bundled AuthV2 artifacts in auth/")
+		}
+	})
+	t.Run("Should prefer in-memory artifacts", func(t *testing.T) {
+		registry := NewRegistry(nil, nil)
+		registry.AllowUnpinned(true)
+
+		if err := registry.Set(circuits.AuthV2CircuitID, ArtifactWasm, wasm); err != nil {
+			t.Fatalf("Error setting wasm: %v", err)
+		}
+
+		if _, err := registry.LoadCircuit(circuits.AuthV2CircuitID); !errors.Is(err, ErrArtifactNotFound) {
+			t.Errorf("Expected ErrArtifactNotFound, got %v", err)
+		}
+
+		if err := registry.Set(circuits.AuthV2CircuitID, ArtifactProvingKey, provingKey); err != nil {
+			t.Fatalf("Error setting proving key: %v", err)
+		}
+
+		if _, err := registry.LoadCircuit(circuits.AuthV2CircuitID); err != nil {
+			t.Errorf("Error loading circuit: %v", err)
+		}
+
+		if _, err := registry.LoadProvingKey(circuits.AuthV2CircuitID); err == nil {
+			t.Errorf("Expected error parsing invalid proving key")
+		}
+	})
+}
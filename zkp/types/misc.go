@@ -1,12 +1,12 @@
 package types
 
 type ChainZkpInfo struct {
-	TargetChainId              int
-	TargetRpcUrl               string
-	TargetStateContractAddress string
-	CoreApiUrl                 string
-	CoreEvmRpcApiUrl           string
-	CoreStateContractAddress   string
+	TargetChainId              int    `json:"targetChainId"`
+	TargetRpcUrl               string `json:"targetRpcUrl"`
+	TargetStateContractAddress string `json:"targetStateContractAddress"`
+	CoreApiUrl                 string `json:"coreApiUrl"`
+	CoreEvmRpcApiUrl           string `json:"coreEvmRpcApiUrl"`
+	CoreStateContractAddress   string `json:"coreStateContractAddress"`
 }
 
 type CircuitPair struct {